@@ -0,0 +1,167 @@
+package messageformat
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgerrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+type adapterTestMoney struct {
+	Amount   float64
+	Currency string
+}
+
+type adapterTestID [4]byte
+
+func (id adapterTestID) MessageValue(ctx functions.MessageFunctionContext) messagevalue.MessageValue {
+	return messagevalue.NewStringValue("id-"+string(id[:]), ctx.Locales()[0], ctx.Source())
+}
+
+type adapterTestRef struct{ name string }
+
+func (r *adapterTestRef) MessageValue(ctx functions.MessageFunctionContext) messagevalue.MessageValue {
+	return messagevalue.NewStringValue(r.name, ctx.Locales()[0], ctx.Source())
+}
+
+func adaptTestMoney(ctx functions.MessageFunctionContext, value any) messagevalue.MessageValue {
+	money := value.(adapterTestMoney)
+	return functions.CurrencyFunction(ctx, functions.Options{"currency": money.Currency}, money.Amount)
+}
+
+// inspectOperand formats the type and currency of its operand.
+func inspectOperand(ctx functions.MessageFunctionContext, options functions.Options, operand any) messagevalue.MessageValue {
+	description := reflect.TypeOf(operand).String()
+	if nv, ok := operand.(*messagevalue.NumberValue); ok {
+		description += " " + nv.Options()["currency"].(string)
+	}
+	return messagevalue.NewStringValue(description, ctx.Locales()[0], ctx.Source())
+}
+
+func TestValueAdapters(t *testing.T) {
+	t.Parallel()
+
+	mf, err := Parse(
+		[]string{"en"},
+		"{$price :inspect} {$id} {$ref} {$order.total :inspect}",
+		WithBidiIsolation(BidiNone),
+		WithValueAdapter(reflect.TypeFor[adapterTestMoney](), adaptTestMoney),
+		WithFunction("inspect", inspectOperand),
+	)
+	require.NoError(t, err)
+
+	formatted, err := mf.Format(map[string]any{
+		"price": adapterTestMoney{Amount: 12.5, Currency: "EUR"},
+		"id":    adapterTestID{'a', 'b', 'c', 'd'},
+		"ref":   &adapterTestRef{name: "ref"},
+		"order": map[string]any{"total": adapterTestMoney{Amount: 3, Currency: "JPY"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "*messagevalue.NumberValue EUR id-abcd ref *messagevalue.NumberValue JPY", formatted)
+}
+
+func TestValueAdapterResultIsThePlaceholderValue(t *testing.T) {
+	t.Parallel()
+
+	mf, err := Parse(
+		[]string{"en"},
+		"{$price} {$price :integer}",
+		WithBidiIsolation(BidiNone),
+		WithValueAdapter(reflect.TypeFor[adapterTestMoney](), adaptTestMoney),
+	)
+	require.NoError(t, err)
+
+	parts, err := mf.FormatToParts(map[string]any{"price": adapterTestMoney{Amount: 12.5, Currency: "EUR"}})
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+	assert.Equal(t, "number", parts[0].Type(), "an adapted value is formatted as is, not as an unknown value")
+	assert.Equal(t, "$price", parts[0].Source())
+	assert.Equal(t, "number", parts[2].Type())
+}
+
+func TestValueAdaptersConvertOncePerFormatCall(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	mf, err := Parse(
+		[]string{"en"},
+		".input {$x :string}\n.local $y = {$x}\n.match $x\nadapted {{{$x} {$y}}}\n* {{other}}",
+		WithBidiIsolation(BidiNone),
+		WithValueAdapter(reflect.TypeFor[adapterTestID](), func(ctx functions.MessageFunctionContext, value any) messagevalue.MessageValue {
+			calls++
+			return messagevalue.NewStringValue("adapted", ctx.Locales()[0], ctx.Source())
+		}),
+	)
+	require.NoError(t, err)
+
+	formatted, err := mf.Format(map[string]any{"x": adapterTestID{'a', 'b', 'c', 'd'}})
+	require.NoError(t, err)
+	assert.Equal(t, "adapted adapted", formatted, "the adapter takes precedence over MessageValuer")
+	assert.Equal(t, 1, calls)
+
+	_, err = mf.Format(map[string]any{"x": adapterTestID{'a', 'b', 'c', 'd'}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestValueAdapterErrors(t *testing.T) {
+	t.Parallel()
+
+	mf, err := Parse(
+		[]string{"en"},
+		"{$price}",
+		WithBidiIsolation(BidiNone),
+		WithValueAdapter(reflect.TypeFor[adapterTestMoney](), func(functions.MessageFunctionContext, any) messagevalue.MessageValue {
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	formatted, err := mf.Format(map[string]any{"price": adapterTestMoney{}})
+	assert.Equal(t, "{$price}", formatted)
+	var resolutionErr *pkgerrors.MessageResolutionError
+	require.ErrorAs(t, err, &resolutionErr)
+	assert.Equal(t, pkgerrors.ErrorTypeBadFunctionResult, resolutionErr.Type)
+
+	// A typed nil pointer is not asked to convert itself
+	mf, err = Parse([]string{"en"}, "{$ref}")
+	require.NoError(t, err)
+	assert.NotPanics(t, func() {
+		_, _ = mf.Format(map[string]any{"ref": (*adapterTestRef)(nil)})
+	})
+
+	for _, option := range []Option{
+		WithValueAdapter(nil, adaptTestMoney),
+		WithValueAdapter(reflect.TypeFor[adapterTestMoney](), nil),
+		WithValueAdapter(reflect.TypeFor[functions.MessageValuer](), adaptTestMoney),
+	} {
+		_, err := Parse([]string{"en"}, "{$price}", option)
+		assert.ErrorIs(t, err, ErrInvalidOption)
+	}
+}
+
+func TestCompileTypedAcceptsAdaptedTypes(t *testing.T) {
+	type params struct {
+		Price adapterTestMoney `mf:"price"`
+		ID    adapterTestID    `mf:"id"`
+	}
+
+	_, err := CompileTyped[params]([]string{"en"}, "{$price :number} {$id :number}")
+	require.ErrorIs(t, err, ErrIncompatibleParam)
+	assert.NotContains(t, err.Error(), "$id", "MessageValuer types are accepted by every function")
+
+	typed, err := CompileTyped[params](
+		[]string{"en"},
+		"{$price :integer}",
+		WithBidiIsolation(BidiNone),
+		WithValueAdapter(reflect.TypeFor[adapterTestMoney](), adaptTestMoney),
+	)
+	require.NoError(t, err)
+	formatted, err := typed.Format(params{Price: adapterTestMoney{Amount: 12.5, Currency: "EUR"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, formatted)
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	mferrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/syntax"
+)
+
+// document is an open text document holding one message, or a JSON catalog
+// whose string values are messages.
+type document struct {
+	uri      string
+	version  int
+	text     string
+	catalog  bool
+	lines    []int // Byte offset of the start of each line
+	messages []*message
+	err      *mferrors.MessageSyntaxError // JSON syntax error of a catalog
+}
+
+// message is one message in a document. Offsets into source are mapped to
+// document offsets through offsets, because JSON escapes change them.
+type message struct {
+	source  string
+	start   int   // Document offset of source
+	end     int   // Document offset of the end of source
+	offsets []int // Document offset of each source byte and of its end; nil maps source directly
+	tree    *syntax.Tree
+	model   datamodel.Message // nil when the message has errors
+	errs    []error
+}
+
+// newDocument parses the messages of text. Documents whose URI or language
+// ID names JSON are catalogs.
+func newDocument(uri, languageID string, version int, text string) *document {
+	d := &document{
+		uri:     uri,
+		version: version,
+		text:    text,
+		catalog: languageID == "json" || strings.EqualFold(path.Ext(uri), ".json"),
+		lines:   []int{0},
+	}
+	for i := range len(text) {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+
+	if !d.catalog {
+		source := trimLineTerminator(text)
+		d.messages = []*message{newMessage(source, 0, nil)}
+		return d
+	}
+	d.messages, d.err = catalogMessages(text)
+	return d
+}
+
+// trimLineTerminator removes the line terminator that ends a file, as the
+// mf2 command does.
+func trimLineTerminator(s string) string {
+	if trimmed, ok := strings.CutSuffix(s, "\r\n"); ok {
+		return trimmed
+	}
+	return strings.TrimSuffix(s, "\n")
+}
+
+// newMessage parses and validates source, which starts at document offset
+// start.
+func newMessage(source string, start int, offsets []int) *message {
+	m := &message{source: source, start: start, end: start + len(source), offsets: offsets}
+	if offsets != nil {
+		m.end = offsets[len(source)]
+	}
+
+	tree, err := syntax.Parse(source)
+	m.tree = tree
+	if err != nil {
+		for _, e := range tree.Errors {
+			m.errs = append(m.errs, e)
+		}
+		return m
+	}
+	msg, err := datamodel.ParseMessage(source)
+	if err == nil {
+		_, err = datamodel.ValidateMessage(msg, nil)
+	}
+	if err != nil {
+		m.errs = splitErrors(err)
+		return m
+	}
+	m.model = msg
+	return m
+}
+
+// splitErrors flattens errors joined with errors.Join.
+func splitErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, splitErrors(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+// offset maps an offset in the message source to a document offset.
+func (m *message) offset(i int) int {
+	i = min(max(i, 0), len(m.source))
+	if m.offsets == nil {
+		return m.start + i
+	}
+	return m.offsets[i]
+}
+
+// sourceOffset maps a document offset inside the message to an offset in
+// its source.
+func (m *message) sourceOffset(offset int) int {
+	if m.offsets == nil {
+		return min(max(offset-m.start, 0), len(m.source))
+	}
+	return sort.Search(len(m.offsets), func(i int) bool { return m.offsets[i] > offset }) - 1
+}
+
+// messageAt returns the message containing the document offset, or nil.
+func (d *document) messageAt(offset int) *message {
+	for _, m := range d.messages {
+		if m.start <= offset && offset <= m.end {
+			return m
+		}
+	}
+	return nil
+}
+
+// catalogMessages returns the string values of a JSON document as messages.
+// Object member names are not messages.
+func catalogMessages(text string) ([]*message, *mferrors.MessageSyntaxError) {
+	type container struct {
+		object bool
+		key    bool // The next string is a member name
+	}
+	var stack []container
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].key = true
+		}
+	}
+
+	// Validate first: the decoder's token errors do not locate the fault.
+	if err := json.Unmarshal([]byte(text), new(json.RawMessage)); err != nil {
+		offset := 0
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = max(int(syntaxErr.Offset)-1, 0)
+		}
+		end := min(offset+1, len(text))
+		e := mferrors.NewMessageSyntaxError(mferrors.ErrorTypeParseError, offset, &end, nil)
+		e.Message = "invalid JSON: " + err.Error()
+		return nil, e
+	}
+
+	var messages []*message
+	dec := json.NewDecoder(strings.NewReader(text))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return messages, nil // io.EOF
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, container{object: true, key: true})
+			case '[':
+				stack = append(stack, container{})
+			default:
+				stack = stack[:len(stack)-1]
+				valueDone()
+			}
+		case string:
+			if n := len(stack); n > 0 && stack[n-1].key {
+				stack[n-1].key = false
+				continue
+			}
+			end := int(dec.InputOffset()) - 1 // The closing quote
+			start := openingQuote(text, end) + 1
+			source, offsets := decodeJSONString(text[start:end], start)
+			messages = append(messages, newMessage(source, start, offsets))
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+}
+
+// openingQuote returns the offset of the quote that opens the JSON string
+// whose closing quote is at end. Quotes inside a JSON string are escaped,
+// so the opening quote is the first quote before end that is not preceded
+// by an odd number of backslashes.
+func openingQuote(text string, end int) int {
+	for i := end - 1; i >= 0; i-- {
+		if text[i] != '"' {
+			continue
+		}
+		backslashes := 0
+		for j := i - 1; j >= 0 && text[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeJSONString decodes the contents of a valid JSON string that starts
+// at document offset start. It also returns the document offset of each
+// decoded byte, followed by the offset of the end of raw.
+func decodeJSONString(raw string, start int) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(raw)+1)
+	for i := 0; i < len(raw); {
+		if raw[i] != '\\' {
+			sb.WriteByte(raw[i])
+			offsets = append(offsets, start+i)
+			i++
+			continue
+		}
+
+		escape := i
+		var r rune
+		switch raw[i+1] {
+		case 'b':
+			r, i = '\b', i+2
+		case 'f':
+			r, i = '\f', i+2
+		case 'n':
+			r, i = '\n', i+2
+		case 'r':
+			r, i = '\r', i+2
+		case 't':
+			r, i = '\t', i+2
+		case 'u':
+			r, i = hexRune(raw[i+2:i+6]), i+6
+			if utf16.IsSurrogate(r) {
+				r = utf8.RuneError
+				if i+6 <= len(raw) && raw[i] == '\\' && raw[i+1] == 'u' {
+					if pair := utf16.DecodeRune(hexRune(raw[escape+2:escape+6]), hexRune(raw[i+2:i+6])); pair != utf8.RuneError {
+						r, i = pair, i+6
+					}
+				}
+			}
+		default: // \" \\ \/
+			r, i = rune(raw[i+1]), i+2
+		}
+		n, _ := sb.WriteRune(r)
+		for range n {
+			offsets = append(offsets, start+escape)
+		}
+	}
+	offsets = append(offsets, start+len(raw))
+	return sb.String(), offsets
+}
+
+// hexRune decodes four hexadecimal digits.
+func hexRune(s string) rune {
+	n, err := strconv.ParseUint(s, 16, 16)
+	if err != nil {
+		return utf8.RuneError
+	}
+	return rune(n)
+}
+
+// encodeJSONString returns s encoded as the contents of a JSON string,
+// without the quotes and without escaping HTML characters.
+func encodeJSONString(s string) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // Strings always encode
+	encoded := strings.TrimSuffix(sb.String(), "\n")
+	return encoded[1 : len(encoded)-1]
+}
+
+// position converts a document offset to an LSP position, whose character
+// counts UTF-16 code units.
+func (d *document) position(offset int) position {
+	offset = min(max(offset, 0), len(d.text))
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+	character := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		character += utf16.RuneLen(r)
+	}
+	return position{Line: line, Character: character}
+}
+
+// offset converts an LSP position to a document offset, clamping positions
+// past the end of a line or of the document.
+func (d *document) offset(pos position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	end := len(d.text)
+	if pos.Line+1 < len(d.lines) {
+		end = d.lines[pos.Line+1] - 1
+	}
+	offset := d.lines[pos.Line]
+	for character := 0; offset < end && character < pos.Character; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		character += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// textRange converts document offsets to an LSP range.
+func (d *document) textRange(start, end int) textRange {
+	return textRange{Start: d.position(start), End: d.position(end)}
+}
+
+// sourceRange converts a span of message source to an LSP range.
+func (d *document) sourceRange(m *message, start, end int) textRange {
+	return d.textRange(m.offset(start), m.offset(end))
+}
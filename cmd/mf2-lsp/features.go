@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	mferrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/syntax"
+)
+
+// diagnostics returns the syntax and data model errors of every message in
+// d, and the JSON syntax error of a catalog.
+func (d *document) diagnostics() []diagnostic {
+	diagnostics := []diagnostic{}
+	if d.err != nil {
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    d.textRange(d.err.Start, d.err.End),
+			Severity: severityError,
+			Code:     d.err.Kind().String(),
+			Source:   "mf2",
+			Message:  d.err.Message,
+		})
+	}
+	for _, m := range d.messages {
+		for _, err := range m.errs {
+			diagnostics = append(diagnostics, d.messageDiagnostic(m, err))
+		}
+	}
+	return diagnostics
+}
+
+// messageDiagnostic converts an error of message m to a diagnostic.
+// Errors without a source position cover the whole message.
+func (d *document) messageDiagnostic(m *message, err error) diagnostic {
+	diag := diagnostic{
+		Range:    d.sourceRange(m, 0, len(m.source)),
+		Severity: severityError,
+		Source:   "mf2",
+		Message:  err.Error(),
+	}
+	var syntaxErr *mferrors.MessageSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return diag
+	}
+	diag.Code = syntaxErr.Kind().String()
+	// Syntax error messages end with the byte offset, which the range
+	// replaces.
+	diag.Message = strings.TrimSuffix(syntaxErr.Message, fmt.Sprintf(" at %d", syntaxErr.Start))
+	if syntaxErr.Start >= 0 {
+		diag.Range = d.sourceRange(m, syntaxErr.Start, max(syntaxErr.End, syntaxErr.Start))
+	}
+	return diag
+}
+
+// at returns the message at an LSP position and the offset of the position
+// in its source.
+func (s *server) at(params json.RawMessage) (*document, *message, int, error) {
+	var p positionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, nil, 0, err
+	}
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	offset := d.offset(p.Position)
+	m := d.messageAt(offset)
+	if m == nil {
+		return d, nil, 0, nil
+	}
+	return d, m, m.sourceOffset(offset), nil
+}
+
+// last returns the innermost node of kind in path, or nil.
+func last(path []*syntax.Node, kind syntax.Kind) *syntax.Node {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Kind == kind {
+			return path[i]
+		}
+	}
+	return nil
+}
+
+// hover documents the function or option at the position.
+func (s *server) hover(params json.RawMessage) (any, error) {
+	d, m, offset, err := s.at(params)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	path := m.tree.Root.Find(offset)
+	var node *syntax.Node
+	var text string
+	if option := last(path, syntax.KindOption); option != nil {
+		if function := last(path, syntax.KindFunction); function != nil {
+			node, text = option, optionMarkdown(function.Name, option.Name)
+		}
+	} else if function := last(path, syntax.KindFunction); function != nil && function.Name != "" {
+		node, text = function, functionMarkdown(function.Name)
+	}
+	if node == nil {
+		return nil, nil
+	}
+	r := d.sourceRange(m, node.Start, node.End)
+	return hover{Contents: markupContent{Kind: "markdown", Value: text}, Range: &r}, nil
+}
+
+// completion completes function names after ":" and option names after a
+// function.
+func (s *server) completion(params json.RawMessage) (any, error) {
+	_, m, offset, err := s.at(params)
+	if err != nil || m == nil {
+		return []completionItem{}, err
+	}
+
+	// The name being typed starts at start.
+	start := offset
+	for start > 0 && isNameByte(m.source[start-1]) {
+		start--
+	}
+	path := m.tree.Root.Find(start)
+	expr := last(path, syntax.KindExpression)
+	if expr == nil || start > expressionClose(expr) {
+		return []completionItem{}, nil
+	}
+	i := slices.IndexFunc(expr.Children, func(n *syntax.Node) bool { return n.Kind == syntax.KindFunction })
+	if i < 0 {
+		return []completionItem{}, nil
+	}
+	function := expr.Children[i]
+
+	if start == function.Children[0].End {
+		return functionCompletions(), nil
+	}
+	if start > nameEnd(function) && start > 0 && isSpace(m.source[start-1]) {
+		return optionCompletions(function), nil
+	}
+	return []completionItem{}, nil
+}
+
+// expressionClose returns the offset of the closing brace of expr, or its end
+// when the brace is missing.
+func expressionClose(expr *syntax.Node) int {
+	if n := len(expr.Children); n > 1 && expr.Children[n-1].Text == "}" {
+		return expr.Children[n-1].Start
+	}
+	return expr.End
+}
+
+// nameEnd returns the end of the identifier of a function node.
+func nameEnd(function *syntax.Node) int {
+	end := function.Start
+	for _, child := range function.Children {
+		if !child.IsToken() || child.Kind == syntax.KindWhitespace {
+			break
+		}
+		end = child.End
+	}
+	return end
+}
+
+// isNameByte reports whether c can be part of an unquoted name. All bytes of
+// non-ASCII characters count, so a name is never split inside a character.
+func isNameByte(c byte) bool {
+	return c >= 0x80 || c == '-' || c == '.' || c == '_' ||
+		'0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func functionCompletions() []completionItem {
+	stable, draft := functionSets()
+	items := make([]completionItem, 0, len(stable)+len(draft))
+	for _, name := range slices.Concat(stable, draft) {
+		items = append(items, completionItem{
+			Label:         name,
+			Kind:          completionFunction,
+			Detail:        functionDetail(name),
+			Documentation: &markupContent{Kind: "markdown", Value: functionMarkdown(name)},
+		})
+	}
+	return items
+}
+
+// optionCompletions returns the options of function that it does not set
+// yet.
+func optionCompletions(function *syntax.Node) []completionItem {
+	var set []string
+	for _, child := range function.Children {
+		if child.Kind == syntax.KindOption {
+			set = append(set, child.Name)
+		}
+	}
+	items := []completionItem{}
+	for _, option := range builtinSchemas[function.Name].OptionNames() {
+		if slices.Contains(set, option) {
+			continue
+		}
+		items = append(items, completionItem{
+			Label:         option,
+			Kind:          completionProperty,
+			Documentation: &markupContent{Kind: "markdown", Value: optionMarkdown(function.Name, option)},
+			InsertText:    option + "=",
+		})
+	}
+	return items
+}
+
+// definition returns the declaration of the variable at the position.
+func (s *server) definition(params json.RawMessage) (any, error) {
+	d, m, offset, err := s.at(params)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	variable := last(m.tree.Root.Find(offset), syntax.KindVariable)
+	if variable == nil {
+		return nil, nil
+	}
+
+	for _, decl := range m.tree.Root.Children {
+		if decl.Kind != syntax.KindLocalDeclaration && decl.Kind != syntax.KindInputDeclaration || decl.Name != variable.Name {
+			continue
+		}
+		var target *syntax.Node
+		decl.Inspect(func(n *syntax.Node) bool {
+			if target == nil && n.Kind == syntax.KindVariable {
+				target = n
+			}
+			return target == nil
+		})
+		if target == nil {
+			return nil, nil
+		}
+		return location{URI: d.uri, Range: d.sourceRange(m, target.Start, target.End)}, nil
+	}
+	return nil, nil
+}
+
+// formatting rewrites each valid message in canonical form. Messages with
+// errors are left unchanged.
+func (s *server) formatting(params json.RawMessage) (any, error) {
+	var p formattingParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := []textEdit{}
+	for _, m := range d.messages {
+		if m.model == nil {
+			continue
+		}
+		canonical := datamodel.StringifyMessage(m.model)
+		if canonical == m.source {
+			continue
+		}
+		if d.catalog {
+			canonical = encodeJSONString(canonical)
+		}
+		edits = append(edits, textEdit{Range: d.textRange(m.start, m.end), NewText: canonical})
+	}
+	return edits, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+)
+
+// builtinSchemas documents the functions of functions.DefaultFunctionMap
+// and functions.DraftFunctionMap, with the options each one reads.
+var builtinSchemas = func() map[string]functions.Schema {
+	schemas := functions.DefaultSchemaMap()
+	maps.Copy(schemas, functions.DraftSchemaMap())
+	return schemas
+}()
+
+// functionSets returns the built-in function names by stability, sorted.
+func functionSets() (stable, draft []string) {
+	return slices.Sorted(maps.Keys(functions.DefaultFunctionMap())),
+		slices.Sorted(maps.Keys(functions.DraftFunctionMap()))
+}
+
+// functionDetail describes where a function is registered.
+func functionDetail(name string) string {
+	stable, draft := functionSets()
+	switch {
+	case slices.Contains(stable, name):
+		return "default function"
+	case slices.Contains(draft, name):
+		return "draft function"
+	}
+	return "custom function"
+}
+
+// functionMarkdown documents a function for hover and completion.
+func functionMarkdown(name string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**:%s** (%s)", name, functionDetail(name))
+	schema, ok := builtinSchemas[name]
+	if !ok {
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "\n\n%s", schema.Summary)
+	if len(schema.Options) > 0 {
+		sb.WriteString("\n\nOptions: ")
+		for i, option := range schema.Options {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "`%s`", option.Name)
+		}
+	}
+	return sb.String()
+}
+
+// optionMarkdown documents an option of a function for hover and
+// completion.
+func optionMarkdown(function, option string) string {
+	header := fmt.Sprintf("**%s** option of :%s", option, function)
+	if strings.HasPrefix(option, "u:") {
+		return header + "\n\nMessageFormat option that applies to every function."
+	}
+	schema, ok := builtinSchemas[function]
+	if !ok {
+		return header
+	}
+	optionSchema, ok := schema.Option(option)
+	if !ok {
+		return header + "\n\nNot supported by :" + function + "."
+	}
+	return header + "\n\n" + optionSchema.Doc
+}
@@ -0,0 +1,39 @@
+// Command mf2-lsp is a Language Server Protocol server for MessageFormat 2.0
+// messages. It speaks JSON-RPC over standard input and output.
+//
+// Usage:
+//
+//	mf2-lsp
+//
+// A document holds one message, or is a JSON catalog when its language ID is
+// "json" or its URI ends in ".json". Every string value of a catalog, at any
+// depth, is a message; object member names are not.
+//
+// The server provides:
+//
+//   - diagnostics for syntax errors, every one of them, and for data model
+//     errors reported by datamodel.ValidateMessage
+//   - hover documentation for built-in functions and their options
+//   - completion of the function names of functions.DefaultFunctionMap and
+//     functions.DraftFunctionMap after ":", and of their option names
+//   - go-to-definition from a variable to its .local or .input declaration
+//   - formatting of valid messages with datamodel.StringifyMessage
+//
+// Documents are synchronized in full on every change.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: mf2-lsp")
+		os.Exit(2)
+	}
+	if err := newServer(os.Stdin, os.Stdout).run(); err != nil {
+		fmt.Fprintf(os.Stderr, "mf2-lsp: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// call is a message sent to the server.
+type call struct {
+	method string
+	params any
+}
+
+// notifications are the methods sent without an ID.
+var notifications = map[string]bool{
+	"initialized": true, "exit": true,
+	"textDocument/didOpen": true, "textDocument/didChange": true, "textDocument/didClose": true,
+}
+
+// received is a message sent by the server.
+type received struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// session runs the server over calls and returns the messages it sent.
+// Requests get their index in calls as ID.
+func session(t *testing.T, calls ...call) []received {
+	t.Helper()
+
+	var in bytes.Buffer
+	for i, c := range calls {
+		msg := map[string]any{"jsonrpc": "2.0", "method": c.method, "params": c.params}
+		if !notifications[c.method] {
+			msg["id"] = i
+		}
+		require.NoError(t, writeMessage(&in, msg))
+	}
+	var out bytes.Buffer
+	require.NoError(t, newServer(&in, &out).run())
+
+	var messages []received
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if errors.Is(err, io.EOF) {
+			return messages
+		}
+		require.NoError(t, err)
+		var msg received
+		require.NoError(t, json.Unmarshal(body, &msg))
+		messages = append(messages, msg)
+	}
+}
+
+// result returns the result of the request sent as calls[id].
+func result(t *testing.T, messages []received, id int, v any) {
+	t.Helper()
+
+	for _, msg := range messages {
+		if msg.ID != nil && *msg.ID == id {
+			require.Nil(t, msg.Error)
+			require.NoError(t, json.Unmarshal(msg.Result, v))
+			return
+		}
+	}
+	t.Fatalf("no response to request %d", id)
+}
+
+// published returns the diagnostics of each publishDiagnostics notification.
+func published(t *testing.T, messages []received) [][]diagnostic {
+	t.Helper()
+
+	var result [][]diagnostic
+	for _, msg := range messages {
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var p publishDiagnosticsParams
+		require.NoError(t, json.Unmarshal(msg.Params, &p))
+		result = append(result, p.Diagnostics)
+	}
+	return result
+}
+
+func open(uri, text string) call {
+	return call{"textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "mf2", "version": 1, "text": text},
+	}}
+}
+
+func at(method, uri string, line, character int) call {
+	return call{method, map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}}
+}
+
+func rng(startLine, startChar, endLine, endChar int) textRange {
+	return textRange{Start: position{startLine, startChar}, End: position{endLine, endChar}}
+}
+
+func TestLifecycle(t *testing.T) {
+	messages := session(t,
+		call{"initialize", map[string]any{}},
+		call{"initialized", map[string]any{}},
+		call{"unknown/method", nil},
+		call{"shutdown", nil},
+		call{"exit", nil},
+	)
+	require.Len(t, messages, 3)
+
+	var init struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	result(t, messages, 0, &init)
+	assert.Equal(t, true, init.Capabilities["hoverProvider"])
+	assert.Equal(t, true, init.Capabilities["definitionProvider"])
+	assert.Equal(t, true, init.Capabilities["documentFormattingProvider"])
+
+	require.NotNil(t, messages[1].Error)
+	assert.Equal(t, codeMethodNotFound, messages[1].Error.Code)
+	assert.Equal(t, "null", string(messages[2].Result))
+
+	var in bytes.Buffer
+	require.NoError(t, writeMessage(&in, map[string]any{"jsonrpc": "2.0", "method": "exit"}))
+	assert.ErrorIs(t, newServer(&in, io.Discard).run(), errExitWithoutShutdown)
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		text string
+		want []diagnostic
+	}{
+		{
+			name: "valid message",
+			uri:  "file:///a.mf2",
+			text: "Hello {$name}\n",
+			want: []diagnostic{},
+		},
+		{
+			name: "every syntax error",
+			uri:  "file:///a.mf2",
+			text: "héllo\n{$x :} {$y",
+			want: []diagnostic{
+				{Range: rng(1, 5, 1, 6), Severity: severityError, Code: "empty-token", Source: "mf2", Message: "empty-token"},
+				{Range: rng(1, 10, 1, 10), Severity: severityError, Code: "missing-syntax", Source: "mf2", Message: "missing }"},
+			},
+		},
+		{
+			name: "data model error",
+			uri:  "file:///a.mf2",
+			text: ".input {$x} .input {$x} {{a}}",
+			want: []diagnostic{
+				{Range: rng(0, 12, 0, 23), Severity: severityError, Code: "duplicate-declaration", Source: "mf2", Message: "duplicate-declaration"},
+			},
+		},
+		{
+			name: "catalog positions account for escapes",
+			uri:  "file:///messages.json",
+			text: "{\n  \"en\": {\n    \"ok\": \"Hi \\\"{$name}\\\"\",\n    \"😀\": \"\\u00e9 {$x :}\"\n  }\n}\n",
+			want: []diagnostic{
+				{Range: rng(3, 23, 3, 24), Severity: severityError, Code: "empty-token", Source: "mf2", Message: "empty-token"},
+			},
+		},
+		{
+			name: "invalid JSON",
+			uri:  "file:///messages.json",
+			text: "{\"a\": \"{$x}\", }",
+			want: []diagnostic{
+				{Range: rng(0, 14, 0, 15), Severity: severityError, Code: "parse-error", Source: "mf2", Message: "invalid JSON: invalid character '}' looking for beginning of object key string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := session(t, open(tt.uri, tt.text))
+			assert.Equal(t, [][]diagnostic{tt.want}, published(t, messages))
+		})
+	}
+}
+
+func TestDidChangeAndClose(t *testing.T) {
+	messages := session(t,
+		open("file:///a.mf2", "{$x"),
+		call{"textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": "file:///a.mf2", "version": 2},
+			"contentChanges": []map[string]any{{"text": "{$x}"}},
+		}},
+		call{"textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": "file:///a.mf2"}}},
+		at("textDocument/hover", "file:///a.mf2", 0, 0),
+	)
+	diagnostics := published(t, messages)
+	require.Len(t, diagnostics, 3)
+	assert.Len(t, diagnostics[0], 1)
+	assert.Empty(t, diagnostics[1])
+	assert.Empty(t, diagnostics[2])
+	require.NotNil(t, messages[3].Error)
+	assert.Equal(t, codeInvalidParams, messages[3].Error.Code)
+}
+
+func TestHover(t *testing.T) {
+	const uri = "file:///a.mf2"
+	messages := session(t,
+		open(uri, "{$n :number minimumFractionDigits=2 u:id=x} {$s :custom}"),
+		at("textDocument/hover", uri, 0, 6),
+		at("textDocument/hover", uri, 0, 15),
+		at("textDocument/hover", uri, 0, 38),
+		at("textDocument/hover", uri, 0, 51),
+		at("textDocument/hover", uri, 0, 2),
+	)
+
+	var h hover
+	result(t, messages, 1, &h)
+	assert.Equal(t, rng(0, 4, 0, 42), *h.Range)
+	assert.Contains(t, h.Contents.Value, "**:number** (default function)")
+	assert.Contains(t, h.Contents.Value, "`minimumFractionDigits`")
+
+	result(t, messages, 2, &h)
+	assert.Equal(t, rng(0, 12, 0, 35), *h.Range)
+	assert.Equal(t, "**minimumFractionDigits** option of :number\n\nMinimum number of fraction digits, from 0 to 100.", h.Contents.Value)
+
+	result(t, messages, 3, &h)
+	assert.Contains(t, h.Contents.Value, "applies to every function")
+
+	result(t, messages, 4, &h)
+	assert.Equal(t, "**:custom** (custom function)", h.Contents.Value)
+
+	var none *hover
+	result(t, messages, 5, &none)
+	assert.Nil(t, none)
+}
+
+func TestCompletion(t *testing.T) {
+	labels := func(items []completionItem) []string {
+		result := make([]string, len(items))
+		for i, item := range items {
+			result[i] = item.Label
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		text      string
+		character int
+		want      []string
+	}{
+		{name: "function names", text: "{$x :}", character: 5, want: []string{"currency", "integer", "number", "offset", "percent", "string", "date", "datetime", "time", "unit"}},
+		{name: "partial function name", text: "{$x :nu}", character: 7, want: []string{"currency", "integer", "number", "offset", "percent", "string", "date", "datetime", "time", "unit"}},
+		{name: "option names", text: "{$x :offset }", character: 12, want: []string{"add", "subtract"}},
+		{name: "options not yet set", text: "{$x :offset add=1 su}", character: 20, want: []string{"subtract"}},
+		{name: "pattern text", text: "hello {$x}", character: 3, want: []string{}},
+		{name: "operand", text: "{$x :number}", character: 3, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := session(t, open("file:///a.mf2", tt.text), at("textDocument/completion", "file:///a.mf2", 0, tt.character))
+			var items []completionItem
+			result(t, messages, 1, &items)
+			assert.Equal(t, tt.want, labels(items))
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	const uri = "file:///messages.json"
+	text := "{\"a\": \".input {$n :number} .local $x = {$n :integer} {{{$x} {$n} {$y}}}\"}"
+	messages := session(t,
+		call{"textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "json", "version": 1, "text": text},
+		}},
+		at("textDocument/definition", uri, 0, 57),
+		at("textDocument/definition", uri, 0, 62),
+		at("textDocument/definition", uri, 0, 67),
+	)
+
+	var loc location
+	result(t, messages, 1, &loc)
+	assert.Equal(t, location{URI: uri, Range: rng(0, 34, 0, 36)}, loc)
+	result(t, messages, 2, &loc)
+	assert.Equal(t, location{URI: uri, Range: rng(0, 15, 0, 17)}, loc)
+
+	var none *location
+	result(t, messages, 3, &none)
+	assert.Nil(t, none)
+}
+
+func TestFormatting(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		text string
+		want []textEdit
+	}{
+		{
+			name: "message file",
+			uri:  "file:///a.mf2",
+			text: ".local   $x =   {1}\n{{{$x}}}\n",
+			want: []textEdit{{Range: rng(0, 0, 1, 8), NewText: ".local $x = {1}\n{{{$x}}}"}},
+		},
+		{
+			name: "canonical message",
+			uri:  "file:///a.mf2",
+			text: "Hello {$name}\n",
+			want: []textEdit{},
+		},
+		{
+			name: "catalog strings are reencoded",
+			uri:  "file:///a.json",
+			text: "{\"a\": \"{ $x }\", \"b\": \"{$x\", \"c\": \"<b>\\u00e9</b>\"}",
+			want: []textEdit{{Range: rng(0, 7, 0, 13), NewText: "{$x}"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := session(t, open(tt.uri, tt.text), call{"textDocument/formatting", map[string]any{
+				"textDocument": map[string]any{"uri": tt.uri},
+			}})
+			var edits []textEdit
+			result(t, messages, 1, &edits)
+			assert.Equal(t, tt.want, edits)
+		})
+	}
+}
+
+func TestDecodeJSONString(t *testing.T) {
+	source, offsets := decodeJSONString(`a\"\u00e9\ud83d\ude00`, 10)
+	assert.Equal(t, "a\"é😀", source)
+	assert.Equal(t, []int{10, 11, 13, 13, 19, 19, 19, 19, 31}, offsets)
+	assert.Equal(t, `a\"é😀<`, encodeJSONString("a\"é😀<"))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// server is a language server connected to one client. It handles one
+// message at a time.
+type server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func newServer(in io.Reader, out io.Writer) *server {
+	return &server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: map[string]*document{},
+	}
+}
+
+// errExitWithoutShutdown reports an exit notification that was not preceded
+// by a shutdown request.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// run serves messages until the client sends exit or closes the input.
+func (s *server) run() error {
+	for {
+		body, err := readMessage(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, rerr := s.handle(req)
+		if req.ID == nil {
+			continue // Notifications have no response
+		}
+		if err := s.reply(req.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+// handler handles the params of one method and returns its result.
+type handler func(s *server, params json.RawMessage) (any, error)
+
+var handlers = map[string]handler{
+	"initialize":              (*server).initialize,
+	"initialized":             ignore,
+	"shutdown":                (*server).shutdownRequest,
+	"textDocument/didOpen":    (*server).didOpen,
+	"textDocument/didChange":  (*server).didChange,
+	"textDocument/didSave":    ignore,
+	"textDocument/didClose":   (*server).didClose,
+	"textDocument/hover":      (*server).hover,
+	"textDocument/completion": (*server).completion,
+	"textDocument/definition": (*server).definition,
+	"textDocument/formatting": (*server).formatting,
+}
+
+func ignore(*server, json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// handle dispatches a request or notification.
+func (s *server) handle(req request) (any, *responseError) {
+	h, ok := handlers[req.Method]
+	if !ok {
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	if s.shutdown && req.ID != nil {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+	result, err := h(s, req.Params)
+	if err != nil {
+		var rerr *responseError
+		if errors.As(err, &rerr) {
+			return nil, rerr
+		}
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// reply sends the response to the request with id.
+func (s *server) reply(id json.RawMessage, result any, rerr *responseError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if id == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	if rerr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = data
+	}
+	return writeMessage(s.out, resp)
+}
+
+// notify sends a notification to the client.
+func (s *server) notify(method string, params any) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *server) initialize(json.RawMessage) (any, error) {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":           1, // Full document text on every change
+			"hoverProvider":              true,
+			"completionProvider":         map[string]any{"triggerCharacters": []string{":", " "}},
+			"definitionProvider":         true,
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]string{"name": "mf2-lsp"},
+	}, nil
+}
+
+func (s *server) shutdownRequest(json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *server) didOpen(params json.RawMessage) (any, error) {
+	var p didOpenParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	item := p.TextDocument
+	return nil, s.open(newDocument(item.URI, item.LanguageID, item.Version, item.Text))
+}
+
+func (s *server) didChange(params json.RawMessage) (any, error) {
+	var p didChangeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok || len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+	text := p.ContentChanges[len(p.ContentChanges)-1].Text
+	languageID := ""
+	if d.catalog {
+		languageID = "json"
+	}
+	return nil, s.open(newDocument(d.uri, languageID, p.TextDocument.Version, text))
+}
+
+func (s *server) didClose(params json.RawMessage) (any, error) {
+	var p didCloseParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	delete(s.documents, p.TextDocument.URI)
+	return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []diagnostic{},
+	})
+}
+
+// open stores d and publishes its diagnostics.
+func (s *server) open(d *document) error {
+	s.documents[d.uri] = d
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         d.uri,
+		Version:     d.version,
+		Diagnostics: d.diagnostics(),
+	})
+}
+
+// document returns the open document with uri.
+func (s *server) document(uri string) (*document, error) {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("document not open: %s", uri)}
+	}
+	return d, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	mferrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+)
+
+// runCheck reports the syntax and data model errors of each input.
+func runCheck(c *cli, args []string) error {
+	fs := c.flagSet("check", "[file ...]")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	inputs, err := c.readInputs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, in := range inputs {
+		if _, err := parseMessage(in.source); err != nil {
+			c.printDiagnostics(in, err)
+			failed = true
+		}
+	}
+	if failed {
+		return errDiagnostics
+	}
+	return nil
+}
+
+// parseMessage parses source and validates the resulting data model.
+func parseMessage(source string) (datamodel.Message, error) {
+	msg, err := datamodel.ParseMessage(source)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := datamodel.ValidateMessage(msg, nil); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// printDiagnostics writes one line to stderr for each error joined in err,
+// as "name:line:col: kind" when the error carries a source position and as
+// "name: kind" otherwise.
+func (c *cli) printDiagnostics(in input, err error) {
+	for _, d := range mferrors.Diagnose(in.source, err) {
+		separator := " "
+		if d.Start >= 0 {
+			separator = ""
+		}
+		fmt.Fprintf(c.stderr, "%s:%s%s\n", in.name, separator, d)
+	}
+}
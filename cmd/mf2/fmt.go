@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+)
+
+// runFmt prints each input in canonical form, or rewrites files with -w.
+func runFmt(c *cli, args []string) error {
+	fs := c.flagSet("fmt", "[-l] [-w] [file ...]")
+	list := fs.Bool("l", false, "list files whose formatting differs from canonical form")
+	write := fs.Bool("w", false, "write the canonical form back to each file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	inputs, err := c.readInputs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, in := range inputs {
+		msg, err := parseMessage(in.source)
+		if err != nil {
+			c.printDiagnostics(in, err)
+			failed = true
+			continue
+		}
+
+		canonical := datamodel.StringifyMessage(msg)
+		changed := canonical != in.source
+		if *list && changed {
+			fmt.Fprintln(c.stdout, in.name)
+		}
+		if *write && in.path != "" {
+			if changed {
+				if err := writeFile(in.path, canonical+"\n"); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !*list {
+			fmt.Fprintln(c.stdout, canonical)
+		}
+	}
+	if failed {
+		return errDiagnostics
+	}
+	return nil
+}
+
+// writeFile replaces the contents of path, keeping its permissions.
+func writeFile(path, content string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), info.Mode().Perm())
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kaptinlin/messageformat-go"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// runFormat renders one message with a locale and JSON params.
+func runFormat(c *cli, args []string) error {
+	fs := c.flagSet("format", "[-locale en] [-params JSON] [-parts] [file]")
+	locales := fs.String("locale", "en", "comma-separated locales, in priority order")
+	params := fs.String("params", "", "message params as a JSON object")
+	paramsFile := fs.String("params-file", "", "read message params from a JSON file")
+	parts := fs.Bool("parts", false, "print formatted parts as JSON instead of text")
+	bidiIsolation := fs.String("bidi", string(messageformat.BidiNone), `bidi isolation strategy: "none" or "default"`)
+	dir := fs.String("dir", "", `message direction: "ltr", "rtl" or "auto"`)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("%w: format takes at most one file", errUsage)
+	}
+	if *params != "" && *paramsFile != "" {
+		return fmt.Errorf("%w: -params and -params-file are mutually exclusive", errUsage)
+	}
+
+	values, err := readParams(*params, *paramsFile)
+	if err != nil {
+		return err
+	}
+	inputs, err := c.readInputs(fs.Args())
+	if err != nil {
+		return err
+	}
+	in := inputs[0]
+
+	options := []messageformat.Option{
+		messageformat.WithBidiIsolation(messageformat.BidiIsolation(*bidiIsolation)),
+	}
+	if *dir != "" {
+		options = append(options, messageformat.WithDir(messageformat.Direction(*dir)))
+	}
+	mf, err := messageformat.Parse(splitLocales(*locales), in.source, options...)
+	if errors.Is(err, messageformat.ErrInvalidOption) {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if err != nil {
+		c.printDiagnostics(in, err)
+		return errDiagnostics
+	}
+
+	var formatErr error
+	if *parts {
+		var result []messagevalue.MessagePart
+		result, formatErr = mf.FormatToParts(values)
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(partsJSON(result)); err != nil {
+			return err
+		}
+	} else {
+		var result string
+		result, formatErr = mf.Format(values)
+		fmt.Fprintln(c.stdout, result)
+	}
+	if formatErr != nil {
+		c.printDiagnostics(in, formatErr)
+		return errDiagnostics
+	}
+	return nil
+}
+
+// readParams decodes message params from the -params value or file. Numbers
+// decode as json.Number, so :number keeps their exact decimal digits.
+func readParams(params, paramsFile string) (map[string]any, error) {
+	data := []byte(params)
+	if paramsFile != "" {
+		var err error
+		if data, err = os.ReadFile(paramsFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var values map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err := dec.Decode(&values)
+	if err == nil && dec.More() {
+		err = errors.New("invalid data after top-level value")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: params must be a JSON object: %w", errUsage, err)
+	}
+	return values, nil
+}
+
+// splitLocales splits a comma-separated locale list.
+func splitLocales(list string) []string {
+	var locales []string
+	for locale := range strings.SplitSeq(list, ",") {
+		if locale = strings.TrimSpace(locale); locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// partsJSON converts formatted parts to JSON objects with the fields used by
+// the MessageFormat 2.0 test suite.
+func partsJSON(parts []messagevalue.MessagePart) []map[string]any {
+	result := make([]map[string]any, 0, len(parts))
+	for _, part := range parts {
+		obj := map[string]any{"type": part.Type()}
+		if value := part.Value(); value != nil && value != "" {
+			obj["value"] = value
+		}
+		if source := part.Source(); source != "" {
+			obj["source"] = source
+		}
+		if locale := part.Locale(); locale != "" {
+			obj["locale"] = locale
+		}
+		if dir := string(part.Dir()); dir != "" && dir != "auto" {
+			obj["dir"] = dir
+		}
+		if markup, ok := part.(*messagevalue.MarkupPart); ok {
+			obj["kind"] = markup.Kind()
+			obj["name"] = markup.Name()
+			delete(obj, "value")
+			if id := markup.ID(); id != "" {
+				obj["id"] = id
+			}
+			if options := markup.Options(); len(options) > 0 {
+				obj["options"] = options
+			}
+		}
+		if compound, ok := part.(interface {
+			Parts() []messagevalue.MessagePart
+		}); ok {
+			if sub := compound.Parts(); len(sub) > 0 {
+				obj["parts"] = partsJSON(sub)
+			}
+		}
+		result = append(result, obj)
+	}
+	return result
+}
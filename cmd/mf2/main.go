@@ -0,0 +1,175 @@
+// Command mf2 checks, formats, and renders MessageFormat 2.0 messages.
+//
+// Usage:
+//
+//	mf2 check [file ...]
+//	mf2 fmt [-l] [-w] [file ...]
+//	mf2 format [-locale en] [-params JSON] [-parts] [file]
+//
+// Each file holds one message in MessageFormat 2.0 syntax. A final line
+// terminator ends the file, not the message. With no file, or with "-", the
+// message is read from standard input.
+//
+// The exit status is 0 on success, 1 when a message has errors, and 2 for
+// usage errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit statuses.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `mf2 checks, formats, and renders MessageFormat 2.0 messages.
+
+Usage:
+
+	mf2 <command> [flags] [file ...]
+
+Commands:
+
+	check   report syntax and data model errors as file:line:col
+	fmt     rewrite messages in canonical form
+	format  render a message with a locale and JSON params
+
+Run "mf2 <command> -h" for command flags.
+`
+
+// errDiagnostics reports that a command printed message diagnostics.
+var errDiagnostics = errors.New("message has errors")
+
+// command is one mf2 subcommand.
+type command struct {
+	name string
+	run  func(cli *cli, args []string) error
+}
+
+var commands = []command{
+	{name: "check", run: runCheck},
+	{name: "fmt", run: runFmt},
+	{name: "format", run: runFormat},
+}
+
+// cli holds the standard streams, so commands can be run in tests.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run executes the command line args and returns the exit status.
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, usage)
+		return exitUsage
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(c.stdout, usage)
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(c, args[1:])
+		switch {
+		case err == nil:
+			return exitOK
+		case errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.Is(err, errDiagnostics):
+			return exitError
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(c.stderr, "mf2 %s: %v\n", cmd.name, err)
+			return exitUsage
+		default:
+			fmt.Fprintf(c.stderr, "mf2 %s: %v\n", cmd.name, err)
+			return exitError
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "mf2: unknown command %q\n\n%s", args[0], usage)
+	return exitUsage
+}
+
+// errUsage reports invalid command line arguments.
+var errUsage = errors.New("usage")
+
+// flagSet returns a flag set for a command that reports parse errors to
+// stderr instead of exiting.
+func (c *cli) flagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet("mf2 "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mf2 %s %s\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs, mapping flag errors to errUsage.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	return nil
+}
+
+// input is one message source read from a file or standard input.
+type input struct {
+	name   string
+	path   string
+	source string
+}
+
+// readInputs reads the named files, or standard input when names is empty.
+func (c *cli) readInputs(names []string) ([]input, error) {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	inputs := make([]input, 0, len(names))
+	for _, name := range names {
+		if name == "-" {
+			data, err := io.ReadAll(c.stdin)
+			if err != nil {
+				return nil, fmt.Errorf("read standard input: %w", err)
+			}
+			inputs = append(inputs, input{name: "<stdin>", source: trimLineTerminator(string(data))})
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input{name: name, path: name, source: trimLineTerminator(string(data))})
+	}
+	return inputs, nil
+}
+
+// trimLineTerminator removes the line terminator that ends a file.
+func trimLineTerminator(s string) string {
+	if trimmed, ok := strings.CutSuffix(s, "\r\n"); ok {
+		return trimmed
+	}
+	return strings.TrimSuffix(s, "\n")
+}
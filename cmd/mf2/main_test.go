@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI runs mf2 with args and stdin, returning the exit status and output.
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	return c.run(args), stdout.String(), stderr.String()
+}
+
+// writeMessage writes source to a file in a temporary directory.
+func writeMessage(t *testing.T, name, source string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(source), 0o600))
+	return path
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		code   int
+		stderr string
+	}{
+		{name: "valid", source: "Hello {$name}!\n", code: exitOK},
+		{name: "syntax error", source: "Hello {$name", code: exitError, stderr: "<stdin>:1:13: missing-syntax: missing }\n"},
+		{name: "column counts characters", source: "héllo\nwörld {$x :}", code: exitError, stderr: "<stdin>:2:12: empty-token\n"},
+		{name: "duplicate declaration", source: ".input {$x} .input {$x} {{a}}", code: exitError, stderr: "<stdin>:1:13: duplicate-declaration\n"},
+		{
+			name:   "all data model errors",
+			source: ".match $n 1 {{a}}",
+			code:   exitError,
+			stderr: "<stdin>:1:8: missing-selector-annotation\n<stdin>:1:11: missing-fallback\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, tt.source, "check")
+			assert.Equal(t, tt.code, code)
+			assert.Empty(t, stdout)
+			assert.Equal(t, tt.stderr, stderr)
+		})
+	}
+}
+
+func TestCheckReportsFileNames(t *testing.T) {
+	good := writeMessage(t, "good.mf2", "ok\n")
+	bad := writeMessage(t, "bad.mf2", "line one\n{$x :}\n")
+
+	code, _, stderr := runCLI(t, "", "check", good, bad)
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, bad+":2:6: empty-token\n", stderr)
+}
+
+func TestFmt(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "{#b   a=1 @x}hi{/b}\n", "fmt")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "{#b a=1 @x}hi{/b}\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, _, stderr = runCLI(t, "{$x", "fmt")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "<stdin>:1:4: missing-syntax")
+}
+
+func TestFmtListAndWrite(t *testing.T) {
+	canonical := writeMessage(t, "canonical.mf2", "{$x :number}\n")
+	messy := writeMessage(t, "messy.mf2", "{ $x   :number }\n")
+
+	code, stdout, _ := runCLI(t, "", "fmt", "-l", canonical, messy)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, messy+"\n", stdout)
+
+	code, stdout, _ = runCLI(t, "", "fmt", "-w", canonical, messy)
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stdout)
+	data, err := os.ReadFile(messy)
+	require.NoError(t, err)
+	assert.Equal(t, "{$x :number}\n", string(data))
+}
+
+func TestFormat(t *testing.T) {
+	path := writeMessage(t, "hello.mf2", "Hello {$name}!\n")
+
+	code, stdout, stderr := runCLI(t, "", "format", "-params", `{"name":"Wörld"}`, path)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Hello Wörld!\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCLI(t, "", "format", "-bidi", "default", "-params", `{"name":"Wörld"}`, path)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Hello ⁨Wörld⁩!\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCLI(t, "", "format", path)
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "Hello {$name}!\n", stdout)
+	assert.Contains(t, stderr, path+":1:7: unresolved-variable")
+}
+
+func TestFormatParamsFile(t *testing.T) {
+	params := writeMessage(t, "params.json", `{"user": "Ana"}`)
+
+	code, stdout, _ := runCLI(t, "Hi {$user}", "format", "-params-file", params)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Hi Ana\n", stdout)
+}
+
+func TestFormatParamsNumbers(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "{$n :number} {$id :number useGrouping=never}", "format", "-params", `{"n":1.50,"id":12345678901234567890}`)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "1.50 12345678901234567890\n", stdout)
+}
+
+func TestFormatParts(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "{#link href=|/x|}Hi {$name}{/link}", "format", "-parts", "-params", `{"name":"Ana"}`)
+	require.Equal(t, exitOK, code, stderr)
+
+	var parts []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &parts))
+	require.Len(t, parts, 4)
+	assert.Equal(t, map[string]any{"type": "markup", "kind": "open", "name": "link", "options": map[string]any{"href": "/x"}}, parts[0])
+	assert.Equal(t, "text", parts[1]["type"])
+	assert.Equal(t, "Hi ", parts[1]["value"])
+	assert.Equal(t, "string", parts[2]["type"])
+	assert.Equal(t, "Ana", parts[2]["value"])
+	assert.Equal(t, "$name", parts[2]["source"])
+	assert.Equal(t, map[string]any{"type": "markup", "kind": "close", "name": "link"}, parts[3])
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{name: "no command", args: nil, stderr: "Usage:"},
+		{name: "unknown command", args: []string{"lint"}, stderr: `unknown command "lint"`},
+		{name: "unknown flag", args: []string{"check", "-x"}, stderr: "flag provided but not defined"},
+		{name: "bad params", args: []string{"format", "-params", "[1]"}, stderr: "params must be a JSON object"},
+		{name: "trailing params", args: []string{"format", "-params", "{} {}"}, stderr: "params must be a JSON object"},
+		{name: "both params", args: []string{"format", "-params", "{}", "-params-file", "p.json"}, stderr: "mutually exclusive"},
+		{name: "bad option", args: []string{"format", "-dir", "up"}, stderr: `invalid MessageFormat option: dir "up"`},
+		{name: "two files", args: []string{"format", "a", "b"}, stderr: "at most one file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, "x", tt.args...)
+			assert.Equal(t, exitUsage, code)
+			assert.Contains(t, stderr, tt.stderr)
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	code, stdout, _ := runCLI(t, "", "help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Commands:")
+
+	code, _, stderr := runCLI(t, "", "fmt", "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "Usage: mf2 fmt")
+}
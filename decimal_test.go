@@ -0,0 +1,73 @@
+package messageformat
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatExactDecimals(t *testing.T) {
+	huge, ok := new(big.Int).SetString("12345678901234567890", 10)
+	require.True(t, ok)
+	price, _, err := apd.NewFromString("19.90")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		source   string
+		values   map[string]any
+		expected string
+	}{
+		{
+			name:     "placeholder keeps every digit",
+			source:   "{$n :number useGrouping=false}",
+			values:   map[string]any{"n": huge},
+			expected: "12345678901234567890",
+		},
+		{
+			name:     "bare placeholder formats as a number",
+			source:   "{$n}",
+			values:   map[string]any{"n": price},
+			expected: "19.90",
+		},
+		{
+			name:     "selection sees the visible fraction digits",
+			source:   ".input {$n :number}\n.match $n\n1 {{exactly one}}\n1.50 {{{$n}}}\n* {{other}}",
+			values:   map[string]any{"n": "1.50"},
+			expected: "1.50",
+		},
+		{
+			name:     "json.Number",
+			source:   ".input {$n :number}\n.match $n\n1 {{exactly one}}\n* {{other}}",
+			values:   map[string]any{"n": json.Number("1")},
+			expected: "exactly one",
+		},
+		{
+			name:     "integer rounds exactly",
+			source:   "{$n :integer useGrouping=false}",
+			values:   map[string]any{"n": "12345678901234567890.5"},
+			expected: "12345678901234567891",
+		},
+		{
+			name:     "offset adds exactly",
+			source:   "{$n :offset add=1 useGrouping=false}",
+			values:   map[string]any{"n": huge},
+			expected: "12345678901234567891",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, err := Parse([]string{"en"}, tt.source, WithBidiIsolation(BidiNone))
+			require.NoError(t, err)
+
+			formatted, err := mf.Format(tt.values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, formatted)
+		})
+	}
+}
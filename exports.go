@@ -33,3 +33,10 @@ type (
 	UnknownPart  = messagevalue.UnknownPart
 	MarkupPart   = messagevalue.MarkupPart
 )
+
+// SetFormatterCacheSize sets how many number and date/time formatters are each
+// kept across Format calls. A size of zero or less disables the cache.
+// See messagevalue.SetFormatterCacheSize.
+func SetFormatterCacheSize(size int) {
+	messagevalue.SetFormatterCacheSize(size)
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	got, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 1, got)
+
+	c.Add("c", 3)
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b was least recently used")
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRUAddReplacesValue(t *testing.T) {
+	c := NewLRU[string, int](2)
+	c.Add("a", 1)
+	c.Add("a", 2)
+
+	got, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, 2, got)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestLRUResize(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		wantLen  int
+	}{
+		{name: "shrink", capacity: 1, wantLen: 1},
+		{name: "grow", capacity: 10, wantLen: 3},
+		{name: "disable", capacity: 0, wantLen: 0},
+		{name: "negative disables", capacity: -1, wantLen: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU[int, int](3)
+			for i := range 3 {
+				c.Add(i, i)
+			}
+			c.Resize(tt.capacity)
+			assert.Equal(t, tt.wantLen, c.Len())
+			assert.Equal(t, max(tt.capacity, 0), c.Capacity())
+
+			c.Add(10, 10)
+			_, ok := c.Get(10)
+			assert.Equal(t, tt.capacity > 0, ok)
+		})
+	}
+}
+
+func TestLRUPurge(t *testing.T) {
+	c := NewLRU[string, int](2)
+	c.Add("a", 1)
+	c.Purge()
+
+	assert.Equal(t, 0, c.Len())
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestLRUConcurrentAccess(t *testing.T) {
+	c := NewLRU[string, int](8)
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Go(func() {
+			for j := range 100 {
+				key := strconv.Itoa((i + j) % 12)
+				c.Add(key, j)
+				c.Get(key)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, c.Len(), 8)
+}
@@ -0,0 +1,86 @@
+package intlbridge
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CacheKey returns a key that identifies a formatter built for locale with
+// the typed go-intl options. Options are keyed by value after translation, so
+// equivalent MF2 option bags such as minimumFractionDigits=2 and
+// minimumFractionDigits="2" share a key. Unset pointer fields are omitted.
+func CacheKey(locale string, options any) string {
+	var b strings.Builder
+	b.Grow(64)
+	b.WriteString(strings.ReplaceAll(locale, "_", "-"))
+	b.WriteByte('|')
+	writeKeyValue(&b, reflect.ValueOf(options))
+	return b.String()
+}
+
+// writeKeyValue appends a canonical rendering of v, following pointers instead
+// of printing their addresses.
+func writeKeyValue(b *strings.Builder, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("nil")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		writeKeyValue(b, v.Elem())
+	case reflect.Struct:
+		b.WriteByte('{')
+		t := v.Type()
+		for i := range v.NumField() {
+			field := v.Field(i)
+			if (field.Kind() == reflect.Pointer || field.Kind() == reflect.Interface) && field.IsNil() {
+				continue
+			}
+			b.WriteString(t.Field(i).Name)
+			b.WriteByte('=')
+			writeKeyValue(b, field)
+			b.WriteByte(';')
+		}
+		b.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeKeyValue(b, v.Index(i))
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, c reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a), fmt.Sprint(c))
+		})
+		b.WriteByte('{')
+		for _, key := range keys {
+			writeKeyValue(b, key)
+			b.WriteByte('=')
+			writeKeyValue(b, v.MapIndex(key))
+			b.WriteByte(';')
+		}
+		b.WriteByte('}')
+	case reflect.String:
+		b.WriteString(v.String())
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	default:
+		fmt.Fprint(b, v)
+	}
+}
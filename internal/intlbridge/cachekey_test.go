@@ -0,0 +1,46 @@
+package intlbridge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  map[string]any
+		la    string
+		lb    string
+		equal bool
+	}{
+		{name: "same options", a: map[string]any{"minimumFractionDigits": 2}, b: map[string]any{"minimumFractionDigits": 2}, la: "en", lb: "en", equal: true},
+		{name: "string digits normalize", a: map[string]any{"minimumFractionDigits": 2}, b: map[string]any{"minimumFractionDigits": "2"}, la: "en", lb: "en", equal: true},
+		{name: "unknown options ignored", a: nil, b: map[string]any{"select": "exact"}, la: "en", lb: "en", equal: true},
+		{name: "underscore locale", a: nil, b: nil, la: "en_US", lb: "en-US", equal: true},
+		{name: "different values", a: map[string]any{"minimumFractionDigits": 1}, b: map[string]any{"minimumFractionDigits": 2}, la: "en", lb: "en", equal: false},
+		{name: "different locales", a: nil, b: nil, la: "en", lb: "de", equal: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := CacheKey(tt.la, NumberOptions(tt.a))
+			b := CacheKey(tt.lb, NumberOptions(tt.b))
+			if tt.equal {
+				assert.Equal(t, a, b)
+			} else {
+				assert.NotEqual(t, a, b)
+			}
+		})
+	}
+}
+
+func TestCacheKeyDoesNotUseAddresses(t *testing.T) {
+	zone := "Europe/Paris"
+	other := "Europe/Paris"
+	a := CacheKey("fr", DateTimeOptions(map[string]any{"timeZone": zone}))
+	b := CacheKey("fr", DateTimeOptions(map[string]any{"timeZone": other}))
+
+	assert.Equal(t, a, b)
+	assert.Contains(t, a, "Europe/Paris")
+}
@@ -0,0 +1,203 @@
+package intlbridge
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/agentable/go-intl/locale"
+	"github.com/agentable/go-intl/pluralrules"
+	"golang.org/x/text/language"
+
+	"github.com/kaptinlin/messageformat-go/internal/cache"
+)
+
+// DefaultLocale is the locale used when no requested locale has locale data,
+// matching the English fallback of ParseLocale.
+const DefaultLocale = "en"
+
+// Locale matcher algorithms accepted by NegotiateLocale.
+const (
+	MatcherLookup  = "lookup"
+	MatcherBestFit = "best fit"
+)
+
+// negotiated caches NegotiateLocale results; the requested lists of a
+// MessageFormat are fixed, so a small cache serves most calls.
+var negotiated = cache.NewLRU[string, string](256)
+
+// NegotiateLocale picks the locale to format with from the requested locales,
+// in priority order, among the CLDR locales that go-intl has locale data for.
+//
+// With MatcherLookup it applies BCP 47 lookup (RFC 4647 section 3.4): each
+// requested tag is truncated subtag by subtag until an available locale is
+// found. Any other matcher, including the default MatcherBestFit, picks the
+// available locale closest to each requested tag by CLDR language distance, as
+// language.Matcher does, so zh-TW matches zh-Hant-TW and fr-XX matches fr. A
+// best-fit match must keep the requested language and be of at least high
+// confidence. Malformed tags and tags with unknown subtags are skipped; a -u-
+// extension of the requested tag is kept on the result. When nothing matches,
+// DefaultLocale is returned.
+func NegotiateLocale(requested []string, matcher string) string {
+	key := matcher + "\x00" + strings.Join(requested, "\x00")
+	if locale, ok := negotiated.Get(key); ok {
+		return locale
+	}
+
+	locale := negotiateLocale(requested, matcher)
+	negotiated.Add(key, locale)
+	return locale
+}
+
+func negotiateLocale(requested []string, matcher string) string {
+	for _, raw := range requested {
+		tag, err := language.Parse(strings.ReplaceAll(strings.TrimSpace(raw), "_", "-"))
+		if err != nil || tag == language.Und {
+			continue
+		}
+		extension := ""
+		if u, ok := tag.Extension('u'); ok {
+			extension = "-" + u.String()
+		}
+		stripped := stripExtensions(tag)
+
+		var found language.Tag
+		var ok bool
+		if matcher == MatcherLookup {
+			found, ok = lookupAvailable(stripped)
+		} else {
+			found, ok = bestFitMatcher().match(stripped)
+		}
+		if ok {
+			return found.String() + extension
+		}
+	}
+	return DefaultLocale
+}
+
+// lookupAvailable truncates tag until a locale with data is found.
+func lookupAvailable(tag language.Tag) (language.Tag, bool) {
+	for candidate := tag.String(); candidate != ""; candidate = truncateTag(candidate) {
+		t, err := language.Parse(candidate)
+		if err == nil && isAvailable(t) {
+			return t, true
+		}
+	}
+	return language.Und, false
+}
+
+// availableMatcher matches tags against the available locales.
+type availableMatcher struct {
+	matcher   language.Matcher
+	available []language.Tag
+}
+
+// bestFitMatcher returns the matcher over the available locales, built on
+// first use. It is a variable so tests can rebuild it with other locale data.
+var bestFitMatcher = sync.OnceValue(newAvailableMatcher)
+
+// newAvailableMatcher collects the CLDR locales that isAvailable accepts: each
+// language with a CLDR locale, combined with every script and region.
+func newAvailableMatcher() *availableMatcher {
+	var available []language.Tag
+	add := func(tag language.Tag, err error) bool {
+		if err != nil {
+			return false
+		}
+		if _, exact := language.CompactIndex(tag); !exact {
+			return false
+		}
+		if isAvailable(tag) && !slices.Contains(available, tag) {
+			available = append(available, tag)
+		}
+		return true
+	}
+
+	scripts := language.Supported.Scripts()
+	regions := language.Supported.Regions()
+	for _, base := range language.Supported.BaseLanguages() {
+		if !add(language.Compose(base)) {
+			continue
+		}
+		for _, region := range regions {
+			add(language.Compose(base, region))
+		}
+		for _, script := range scripts {
+			if !add(language.Compose(base, script)) {
+				continue
+			}
+			for _, region := range regions {
+				add(language.Compose(base, script, region))
+			}
+		}
+	}
+	return &availableMatcher{matcher: language.NewMatcher(available), available: available}
+}
+
+// match returns the available locale closest to tag.
+func (m *availableMatcher) match(tag language.Tag) (language.Tag, bool) {
+	if len(m.available) == 0 {
+		return language.Und, false
+	}
+	_, index, confidence := m.matcher.Match(tag)
+	found := m.available[index]
+	requested, _ := tag.Base()
+	matched, _ := found.Base()
+	if confidence < language.High || requested != matched {
+		return language.Und, false
+	}
+	return found, true
+}
+
+// isAvailable reports whether tag is a CLDR locale that go-intl has locale
+// data for. The CLDR table keeps lookup and best fit on real locales, such as
+// fr rather than fr-XX; go-intl decides which of them can be formatted.
+func isAvailable(tag language.Tag) bool {
+	if tag == language.Und {
+		return false
+	}
+	if _, exact := language.CompactIndex(tag); !exact {
+		return false
+	}
+	return hasLocaleData(tag)
+}
+
+// hasLocaleData reports whether go-intl supports tag, matched with lookup so
+// that best fit does not widen the supported set. It is a variable so tests
+// can stand in for the locales of go-intl.
+var hasLocaleData = func(tag language.Tag) bool {
+	loc, err := locale.Parse(tag.String())
+	if err != nil {
+		return false
+	}
+	lookup := string(pluralrules.LookupLocaleMatcher)
+	supported, err := pluralrules.SupportedLocalesOf(
+		[]locale.Locale{loc},
+		pluralrules.Options{LocaleMatcher: &lookup},
+	)
+	return err == nil && len(supported) > 0
+}
+
+// stripExtensions removes extension and private-use subtags.
+func stripExtensions(tag language.Tag) language.Tag {
+	base, script, region := tag.Raw()
+	stripped, err := language.Compose(base, script, region, tag.Variants())
+	if err != nil {
+		return tag
+	}
+	return stripped
+}
+
+// truncateTag removes the last subtag of tag and any singleton left at the
+// end, as in RFC 4647 lookup.
+func truncateTag(tag string) string {
+	i := strings.LastIndexByte(tag, '-')
+	if i < 0 {
+		return ""
+	}
+	tag = tag[:i]
+	if j := strings.LastIndexByte(tag, '-'); j >= 0 && len(tag)-j == 2 {
+		tag = tag[:j]
+	}
+	return tag
+}
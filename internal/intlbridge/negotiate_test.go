@@ -0,0 +1,87 @@
+package intlbridge
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []string
+		matcher   string
+		want      string
+	}{
+		{name: "unknown locale falls back to next", requested: []string{"xx-YY", "fr"}, matcher: MatcherBestFit, want: "fr"},
+		{name: "unknown locale falls back to next with lookup", requested: []string{"xx-YY", "fr"}, matcher: MatcherLookup, want: "fr"},
+		{name: "exact match", requested: []string{"fr-CA"}, matcher: MatcherLookup, want: "fr-CA"},
+		{name: "lookup truncates unknown region data", requested: []string{"fr-XX"}, matcher: MatcherLookup, want: "fr"},
+		{name: "lookup truncates variant", requested: []string{"de-CH-1996"}, matcher: MatcherLookup, want: "de-CH"},
+		{name: "lookup truncates to language", requested: []string{"es-AR-x-foo"}, matcher: MatcherLookup, want: "es-AR"},
+		{name: "lookup uses language for script-implied region", requested: []string{"zh-TW"}, matcher: MatcherLookup, want: "zh"},
+		{name: "best fit matches script-implied region", requested: []string{"zh-TW"}, matcher: MatcherBestFit, want: "zh-Hant-TW"},
+		{name: "empty matcher is best fit", requested: []string{"zh-TW"}, matcher: "", want: "zh-Hant-TW"},
+		{name: "best fit matches unknown region to language", requested: []string{"fr-XX"}, matcher: MatcherBestFit, want: "fr"},
+		{name: "best fit matches deprecated code", requested: []string{"iw"}, matcher: MatcherBestFit, want: "he"},
+		{name: "underscore separator", requested: []string{"en_GB"}, matcher: MatcherLookup, want: "en-GB"},
+		{name: "unicode extension kept", requested: []string{"ar-u-nu-latn"}, matcher: MatcherLookup, want: "ar-u-nu-latn"},
+		{name: "best fit keeps the language", requested: []string{"tlh", "de"}, matcher: MatcherBestFit, want: "de"},
+		{name: "nothing matches", requested: []string{"xx", "tlh"}, matcher: MatcherLookup, want: DefaultLocale},
+		{name: "empty list", requested: nil, matcher: MatcherLookup, want: DefaultLocale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NegotiateLocale(tt.requested, tt.matcher))
+			// Cached results are the same
+			assert.Equal(t, tt.want, NegotiateLocale(tt.requested, tt.matcher))
+		})
+	}
+}
+
+func TestNegotiateLocaleSkipsLocalesWithoutData(t *testing.T) {
+	withoutData := map[string]bool{"fr-CA": true, "zh-Hant": true, "zh-Hant-TW": true, "zh-Hant-HK": true, "zh-Hant-MO": true}
+	originalData, originalMatcher := hasLocaleData, bestFitMatcher
+	hasLocaleData = func(tag language.Tag) bool { return !withoutData[tag.String()] }
+	bestFitMatcher = sync.OnceValue(newAvailableMatcher)
+	t.Cleanup(func() { hasLocaleData, bestFitMatcher = originalData, originalMatcher })
+
+	tests := []struct {
+		name      string
+		requested []string
+		matcher   string
+		want      string
+	}{
+		{name: "lookup truncates to locale with data", requested: []string{"fr-CA"}, matcher: MatcherLookup, want: "fr"},
+		{name: "best fit matches closest locale with data", requested: []string{"fr-CA"}, matcher: MatcherBestFit, want: "fr"},
+		{name: "best fit skips other scripts", requested: []string{"zh-TW", "de"}, matcher: MatcherBestFit, want: "de"},
+		{name: "lookup truncates script without data", requested: []string{"zh-Hant"}, matcher: MatcherLookup, want: "zh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiateLocale(tt.requested, tt.matcher))
+		})
+	}
+}
+
+func TestTruncateTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "de-CH-1996", want: "de-CH"},
+		{tag: "de-CH", want: "de"},
+		{tag: "de", want: ""},
+		{tag: "zh-Hant-CN-x-private1", want: "zh-Hant-CN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			assert.Equal(t, tt.want, truncateTag(tt.tag))
+		})
+	}
+}
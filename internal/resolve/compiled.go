@@ -0,0 +1,244 @@
+// Package resolve provides compiled expression resolution for MessageFormat 2.0
+package resolve
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// isBuiltinFunction reports whether name is bound to a built-in function in
+// funcs. Built-ins are deterministic, do not retain or mutate their options,
+// and may therefore share precomputed options and be constant folded.
+func isBuiltinFunction(funcs map[string]functions.MessageFunction, builtins map[string]bool, name string) bool {
+	return builtins[name] && funcs[name] != nil
+}
+
+// CompiledExpression is an expression prepared once when a message is
+// compiled. Expressions without variable inputs that resolve through built-in
+// functions without diagnostics are folded to their value; function calls with
+// literal universal options are pre-bound with their literal options resolved.
+// Everything else resolves through ResolveExpression.
+// A CompiledExpression is immutable and safe for concurrent use.
+type CompiledExpression struct {
+	expr     *datamodel.Expression
+	constant messagevalue.MessageValue
+	call     *compiledCall
+}
+
+// compiledCall is a pre-bound function call.
+type compiledCall struct {
+	name      string
+	function  functions.MessageFunction
+	builtin   bool
+	operand   datamodel.Node
+	source    string
+	msgCtx    functions.MessageFunctionContext
+	options   functions.Options
+	variables map[string]*datamodel.VariableRef
+	dir       string
+	id        string
+}
+
+// CompileExpression prepares expr for repeated resolution with the given
+// locales, functions, and locale matcher. builtins holds the names in funcs
+// that are bound to built-in functions; only those are constant folded and
+// pre-bound with shared options.
+func CompileExpression(
+	expr *datamodel.Expression,
+	locales []string,
+	funcs map[string]functions.MessageFunction,
+	builtins map[string]bool,
+	localeMatcher string,
+) *CompiledExpression {
+	compiled := &CompiledExpression{expr: expr}
+	if expr == nil {
+		return compiled
+	}
+
+	diagnostics := 0
+	ctx := NewContext(locales, funcs, nil, func(error) { diagnostics++ }, localeMatcher)
+
+	if isConstantExpression(expr, funcs, builtins) {
+		if mv := ResolveExpression(ctx, expr); diagnostics == 0 && mv != nil && mv.Type() != "fallback" {
+			compiled.constant = mv
+			return compiled
+		}
+		return compiled
+	}
+
+	functionRef := expr.FunctionRef()
+	if functionRef == nil {
+		return compiled
+	}
+	fn, exists := funcs[functionRef.Name()]
+	if !exists {
+		return compiled
+	}
+
+	var operand datamodel.Node
+	if arg := expr.Arg(); arg != nil {
+		node, ok := arg.(datamodel.Node)
+		if !ok {
+			return compiled
+		}
+		operand = node
+	}
+
+	call := &compiledCall{
+		name:     functionRef.Name(),
+		function: fn,
+		builtin:  isBuiltinFunction(funcs, builtins, functionRef.Name()),
+		operand:  operand,
+		source:   cmp.Or(getValueSource(operand), ":"+functionRef.Name()),
+		options:  make(functions.Options),
+	}
+	options := convertOptionsToMap(functionRef.Options())
+	for name, value := range options {
+		switch v := value.(type) {
+		case *datamodel.Literal:
+			if !isUniversalOption(name) {
+				call.options[name] = v.Value()
+			}
+		case *datamodel.VariableRef:
+			if isUniversalOption(name) {
+				// u:dir and u:id must be known when the call is bound
+				return compiled
+			}
+			if call.variables == nil {
+				call.variables = make(map[string]*datamodel.VariableRef)
+			}
+			call.variables[name] = v
+		default:
+			return compiled
+		}
+	}
+
+	call.msgCtx = createMessageFunctionContext(ctx, call.source, options)
+	if diagnostics > 0 {
+		// Invalid literal universal options are reported on every format call
+		return compiled
+	}
+	call.dir = call.msgCtx.Dir()
+	call.id = call.msgCtx.ID()
+
+	compiled.call = call
+	return compiled
+}
+
+// isConstantExpression reports whether expr has no variable inputs and
+// resolves through a built-in function.
+func isConstantExpression(
+	expr *datamodel.Expression,
+	funcs map[string]functions.MessageFunction,
+	builtins map[string]bool,
+) bool {
+	if arg := expr.Arg(); arg != nil {
+		if _, ok := arg.(*datamodel.Literal); !ok {
+			return false
+		}
+	}
+
+	functionRef := expr.FunctionRef()
+	if functionRef == nil {
+		if expr.Arg() == nil {
+			return false
+		}
+		// ResolveLiteral formats through :string, or directly when it is absent
+		_, exists := funcs["string"]
+		return !exists || isBuiltinFunction(funcs, builtins, "string")
+	}
+	for _, value := range functionRef.Options() {
+		if _, ok := value.(*datamodel.Literal); !ok {
+			return false
+		}
+	}
+	return isBuiltinFunction(funcs, builtins, functionRef.Name())
+}
+
+// ResolveCompiled resolves a compiled expression to a MessageValue with the
+// same results and diagnostics as ResolveExpression. While it resolves, the
+// span of ctx is the source span of the expression.
+func ResolveCompiled(ctx *Context, compiled *CompiledExpression) messagevalue.MessageValue {
+	if ctx.Span != nil && compiled.expr != nil {
+		defer ctx.ExitSpan(ctx.EnterSpan(compiled.expr.GetPosition()))
+	}
+	switch {
+	case compiled.constant != nil:
+		return compiled.constant
+	case compiled.call != nil:
+		return compiled.call.resolve(ctx)
+	default:
+		return ResolveExpression(ctx, compiled.expr)
+	}
+}
+
+// Expression returns the data model expression that was compiled.
+func (c *CompiledExpression) Expression() *datamodel.Expression {
+	return c.expr
+}
+
+// resolve calls the pre-bound function, matching ResolveFunctionRef.
+func (c *compiledCall) resolve(ctx *Context) messagevalue.MessageValue {
+	// A done formatting context stops resolution; the caller reports ctx.Err() once
+	if ctx.Err() != nil {
+		return functions.FallbackFunction(c.source, ctx.Locale())
+	}
+
+	result, err := c.call(ctx)
+	if err != nil {
+		if ctx.OnError != nil {
+			ctx.OnError(err)
+		}
+		return functions.FallbackFunction(c.source, ctx.Locale())
+	}
+	return result
+}
+
+// call resolves the operand and variable options and invokes the function,
+// matching resolveFunctionRefInternal.
+func (c *compiledCall) call(ctx *Context) (messagevalue.MessageValue, error) {
+	var input any
+	if c.operand != nil {
+		resolved, err := resolveValue(ctx, c.operand)
+		if err != nil {
+			return nil, errors.NewMessageResolutionError(
+				errors.ErrorTypeBadOperand,
+				err.Error(),
+				c.source,
+				err,
+			)
+		}
+		input = resolved
+	}
+
+	// Built-ins only read their options, so literal options are shared
+	options := c.options
+	if len(c.variables) > 0 || !c.builtin {
+		options = maps.Clone(c.options)
+		for name, ref := range c.variables {
+			resolved, _ := lookupVariableRef(ctx, ref)
+			options[name] = optionValue(resolved)
+		}
+	}
+
+	msgCtx := c.msgCtx.WithOnError(ctx.OnError).WithContext(ctx.Context)
+	res, err := callFunction(c.function, msgCtx, options, input)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.NewMessageResolutionError(
+			errors.ErrorTypeBadFunctionResult,
+			fmt.Sprintf("function :%s did not return a MessageValue", c.name),
+			c.source,
+		)
+	}
+
+	return withUniversalOptions(res, c.dir, c.id), nil
+}
@@ -0,0 +1,187 @@
+package resolve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	pkgErrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+func newCompiledTestFunctions(custom functions.MessageFunction) map[string]functions.MessageFunction {
+	funcs := functions.DefaultFunctionMap()
+	if custom != nil {
+		funcs["custom"] = custom
+	}
+	return funcs
+}
+
+// compiledTestBuiltins returns the names of the default functions.
+func compiledTestBuiltins() map[string]bool {
+	builtins := make(map[string]bool)
+	for name := range functions.DefaultFunctionMap() {
+		builtins[name] = true
+	}
+	return builtins
+}
+
+func TestCompileExpressionFoldsConstants(t *testing.T) {
+	funcs := newCompiledTestFunctions(nil)
+	tests := []struct {
+		name string
+		expr *datamodel.Expression
+	}{
+		{
+			name: "literal",
+			expr: mustExpression(t, datamodel.NewLiteral("hello"), nil, nil),
+		},
+		{
+			name: "literal with builtin function and literal options",
+			expr: mustExpression(t, datamodel.NewLiteral("1.5"), mustFunctionRef(t, "number", datamodel.Options{
+				"minimumFractionDigits": datamodel.NewLiteral("2"),
+			}), nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled := CompileExpression(tt.expr, []string{"en"}, funcs, compiledTestBuiltins(), "best fit")
+			require.NotNil(t, compiled.constant)
+
+			var errs []error
+			ctx := NewContext([]string{"en"}, funcs, nil, func(err error) { errs = append(errs, err) }, "best fit")
+			first := ResolveCompiled(ctx, compiled)
+			second := ResolveCompiled(ctx, compiled)
+			assert.Same(t, first, second)
+			assert.Empty(t, errs)
+
+			want, err := ResolveExpression(ctx, tt.expr).ToString()
+			require.NoError(t, err)
+			got, err := first.ToString()
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCompileExpressionDoesNotFold(t *testing.T) {
+	custom := func(_ functions.MessageFunctionContext, _ functions.Options, input any) messagevalue.MessageValue {
+		return messagevalue.NewStringValue("custom", "en", "|x|")
+	}
+	funcs := newCompiledTestFunctions(custom)
+	funcs["num"] = functions.NumberFunction
+	tests := []struct {
+		name string
+		expr *datamodel.Expression
+	}{
+		{name: "variable operand", expr: mustExpression(t, datamodel.NewVariableRef("x"), nil, nil)},
+		{name: "variable option", expr: mustExpression(t, datamodel.NewLiteral("1"), mustFunctionRef(t, "number", datamodel.Options{
+			"minimumFractionDigits": datamodel.NewVariableRef("digits"),
+		}), nil)},
+		{name: "custom function", expr: mustExpression(t, datamodel.NewLiteral("1"), mustFunctionRef(t, "custom", nil), nil)},
+		{name: "built-in implementation under a custom name", expr: mustExpression(t, datamodel.NewLiteral("1"), mustFunctionRef(t, "num", nil), nil)},
+		{name: "unknown function", expr: mustExpression(t, datamodel.NewLiteral("1"), mustFunctionRef(t, "missing", nil), nil)},
+		{name: "diagnostic", expr: mustExpression(t, datamodel.NewLiteral("x"), mustFunctionRef(t, "number", nil), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled := CompileExpression(tt.expr, []string{"en"}, funcs, compiledTestBuiltins(), "best fit")
+			assert.Nil(t, compiled.constant)
+		})
+	}
+}
+
+func TestResolveCompiledMatchesResolveExpression(t *testing.T) {
+	custom := func(ctx functions.MessageFunctionContext, options functions.Options, input any) messagevalue.MessageValue {
+		label, _ := options.String("label")
+		return messagevalue.NewStringValue(ctx.Source()+":"+label, "en", ctx.Source())
+	}
+	funcs := newCompiledTestFunctions(custom)
+	scope := map[string]any{"n": 42, "digits": 1, "label": "x"}
+	tests := []struct {
+		name string
+		expr *datamodel.Expression
+	}{
+		{name: "variable", expr: mustExpression(t, datamodel.NewVariableRef("n"), nil, nil)},
+		{name: "missing variable", expr: mustExpression(t, datamodel.NewVariableRef("missing"), nil, nil)},
+		{name: "number with variable option", expr: mustExpression(t, datamodel.NewVariableRef("n"), mustFunctionRef(t, "number", datamodel.Options{
+			"minimumFractionDigits": datamodel.NewVariableRef("digits"),
+		}), nil)},
+		{name: "custom with literal u:id", expr: mustExpression(t, datamodel.NewVariableRef("n"), mustFunctionRef(t, "custom", datamodel.Options{
+			"label": datamodel.NewVariableRef("label"),
+			"u:id":  datamodel.NewLiteral("id1"),
+		}), nil)},
+		{name: "invalid u:dir", expr: mustExpression(t, datamodel.NewVariableRef("n"), mustFunctionRef(t, "number", datamodel.Options{
+			"u:dir": datamodel.NewLiteral("up"),
+		}), nil)},
+		{name: "unknown function", expr: mustExpression(t, datamodel.NewVariableRef("n"), mustFunctionRef(t, "missing", nil), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wantErrs, gotErrs []error
+			wantCtx := NewContext([]string{"en"}, funcs, scope, func(err error) { wantErrs = append(wantErrs, err) }, "best fit")
+			gotCtx := NewContext([]string{"en"}, funcs, scope, func(err error) { gotErrs = append(gotErrs, err) }, "best fit")
+
+			want := ResolveExpression(wantCtx, tt.expr)
+			got := ResolveCompiled(gotCtx, CompileExpression(tt.expr, []string{"en"}, funcs, compiledTestBuiltins(), "best fit"))
+
+			assert.Equal(t, want.Type(), got.Type())
+			assert.Equal(t, want.Source(), got.Source())
+			wantString, wantErr := want.ToString()
+			gotString, gotErr := got.ToString()
+			assert.Equal(t, wantString, gotString)
+			assert.Equal(t, wantErr, gotErr)
+			wantParts, _ := want.ToParts()
+			gotParts, _ := got.ToParts()
+			assert.Equal(t, wantParts, gotParts)
+			assert.Len(t, gotErrs, len(wantErrs))
+		})
+	}
+}
+
+func TestResolveCompiledDetachesCustomFunctionOptions(t *testing.T) {
+	var seen []string
+	custom := func(ctx functions.MessageFunctionContext, options functions.Options, input any) messagevalue.MessageValue {
+		style, _ := options.String("style")
+		seen = append(seen, style)
+		options["style"] = "mutated"
+		return messagevalue.NewStringValue("ok", "en", ctx.Source())
+	}
+	funcs := newCompiledTestFunctions(custom)
+	expr := mustExpression(t, datamodel.NewVariableRef("x"), mustFunctionRef(t, "custom", datamodel.Options{
+		"style": datamodel.NewLiteral("short"),
+	}), nil)
+	compiled := CompileExpression(expr, []string{"en"}, funcs, compiledTestBuiltins(), "best fit")
+	require.NotNil(t, compiled.call)
+
+	for range 2 {
+		ctx := NewContext([]string{"en"}, funcs, map[string]any{"x": 1}, nil, "best fit")
+		ResolveCompiled(ctx, compiled)
+	}
+	assert.Equal(t, []string{"short", "short"}, seen)
+}
+
+func TestResolveCompiledReportsBadFunctionResult(t *testing.T) {
+	custom := func(functions.MessageFunctionContext, functions.Options, any) messagevalue.MessageValue {
+		return nil
+	}
+	funcs := newCompiledTestFunctions(custom)
+	expr := mustExpression(t, datamodel.NewVariableRef("x"), mustFunctionRef(t, "custom", nil), nil)
+	compiled := CompileExpression(expr, []string{"en"}, funcs, compiledTestBuiltins(), "best fit")
+
+	var errs []error
+	ctx := NewContext([]string{"en"}, funcs, map[string]any{"x": 1}, func(err error) { errs = append(errs, err) }, "best fit")
+	result := ResolveCompiled(ctx, compiled)
+
+	assert.Equal(t, "fallback", result.Type())
+	require.Len(t, errs, 1)
+	var resolutionErr *pkgErrors.MessageResolutionError
+	require.ErrorAs(t, errs[0], &resolutionErr)
+	assert.Equal(t, pkgErrors.ErrorTypeBadFunctionResult, resolutionErr.Type)
+}
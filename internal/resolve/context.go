@@ -3,9 +3,12 @@
 package resolve
 
 import (
+	"context"
 	"maps"
+	"reflect"
 	"slices"
 
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
 	"github.com/kaptinlin/messageformat-go/pkg/functions"
 	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
 )
@@ -34,6 +37,10 @@ type Context struct {
 	// Available locales
 	Locales []string
 
+	// Locale negotiated from Locales with LocaleMatcher; negotiated on first
+	// use when empty
+	NegotiatedLocale string
+
 	// Set of local variables (for cycle detection)
 	LocalVars map[messagevalue.MessageValue]bool
 
@@ -42,6 +49,27 @@ type Context struct {
 
 	// Track variables currently being resolved (for circular reference detection)
 	ResolvingVars map[string]bool
+
+	// Context of the formatting call, passed on to message functions
+	Context context.Context
+
+	// Source span of the expression being resolved, shared by clones; nil
+	// unless TrackSpans was called
+	Span *Span
+	span Span // storage of Span, so tracking allocates nothing
+
+	// Adapters converting input values of application types, by exact type
+	ValueAdapters map[reflect.Type]functions.ValueAdapter
+
+	// Input values converted by a ValueAdapter or MessageValuer, by variable
+	// name; allocated on first use
+	Adapted map[string]messagevalue.MessageValue
+}
+
+// Span is a range of byte offsets in the message source. Start is -1 when
+// the range is unknown.
+type Span struct {
+	Start, End int
 }
 
 // NewContext creates a new resolution context
@@ -68,6 +96,7 @@ func NewContext(
 		LocalVars:     make(map[messagevalue.MessageValue]bool),
 		Scope:         scope,
 		ResolvingVars: make(map[string]bool),
+		Context:       context.Background(),
 	}
 }
 
@@ -82,7 +111,98 @@ func (ctx *Context) Clone() *Context {
 		LocalVars:     maps.Clone(ctx.LocalVars),
 		Scope:         maps.Clone(ctx.Scope),
 		ResolvingVars: ctx.ResolvingVars, // Share the resolving vars tracking
+		Context:       ctx.Context,
+		Span:          ctx.Span, // Share the span of the expression being resolved
+		ValueAdapters: ctx.ValueAdapters,
+		Adapted:       ctx.Adapted, // Share conversions made so far
+
+		// Share the locale, so it is negotiated at most once
+		NegotiatedLocale: ctx.Locale(),
+	}
+}
+
+// TrackSpans makes ctx and its clones keep the source span of the expression
+// being resolved, for LocateError. Tracking only updates offsets; errors are
+// located when they are reported.
+func (ctx *Context) TrackSpans() {
+	ctx.span = Span{Start: -1, End: -1}
+	ctx.Span = &ctx.span
+}
+
+// LocateError returns a runtime error located at the source span of the
+// expression being resolved, unless it carries a span already or spans are
+// not tracked. Errors are copied before their span is set, so errors shared
+// between calls are never modified.
+func (ctx *Context) LocateError(err error) error {
+	if ctx.Span == nil || ctx.Span.Start < 0 {
+		return err
+	}
+	return withSpan(err, *ctx.Span)
+}
+
+// withSpan returns a copy of a runtime error located at span, or err itself
+// when it is located already or cannot be.
+func withSpan(err error, span Span) error {
+	switch e := err.(type) {
+	case *errors.MessageResolutionError:
+		if e.Start < 0 {
+			located := *e
+			located.SetSpan(span.Start, span.End)
+			return &located
+		}
+	case *errors.MessageSelectionError:
+		if e.Start < 0 {
+			located := *e
+			located.SetSpan(span.Start, span.End)
+			return &located
+		}
+	case *errors.MessageFunctionError:
+		if e.Start < 0 {
+			located := *e
+			located.SetSpan(span.Start, span.End)
+			return &located
+		}
+	}
+	return err
+}
+
+// EnterSpan makes start and end the span of the expression being resolved
+// and returns the previous span, which ExitSpan restores. Unknown positions
+// keep the current span.
+func (ctx *Context) EnterSpan(start, end int) Span {
+	if ctx.Span == nil {
+		return Span{Start: -1, End: -1}
+	}
+	prev := *ctx.Span
+	if start >= 0 {
+		*ctx.Span = Span{Start: start, End: end}
+	}
+	return prev
+}
+
+// ExitSpan restores the span returned by EnterSpan.
+func (ctx *Context) ExitSpan(prev Span) {
+	if ctx.Span != nil {
+		*ctx.Span = prev
+	}
+}
+
+// Err reports why the formatting call context is done, or nil while it is active.
+func (ctx *Context) Err() error {
+	if ctx.Context == nil {
+		return nil
+	}
+	return ctx.Context.Err()
+}
+
+// Locale returns the locale negotiated from Locales with LocaleMatcher, which
+// fallback values and parts carry like formatted values. It is negotiated
+// once and kept in NegotiatedLocale. See functions.NegotiateLocale.
+func (ctx *Context) Locale() string {
+	if ctx.NegotiatedLocale == "" {
+		ctx.NegotiatedLocale = functions.NegotiateLocale(ctx.Locales, ctx.LocaleMatcher)
 	}
+	return ctx.NegotiatedLocale
 }
 
 // CloneWithScope creates a copy of the context with a new scope
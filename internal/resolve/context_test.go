@@ -1,10 +1,14 @@
 package resolve
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	pkgErrors "github.com/kaptinlin/messageformat-go/pkg/errors"
 	"github.com/kaptinlin/messageformat-go/pkg/functions"
 	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
 )
@@ -101,3 +105,77 @@ func TestContextCloneWithScope(t *testing.T) {
 	assert.NotContains(t, original.Scope, "age")
 	assert.NotContains(t, original.Scope, "city")
 }
+
+func TestContextErr(t *testing.T) {
+	ctx := NewContext([]string{"en"}, nil, nil, nil, "best fit")
+	assert.Equal(t, context.Background(), ctx.Context)
+	require.NoError(t, ctx.Err())
+
+	parent, cancel := context.WithCancel(t.Context())
+	ctx.Context = parent
+	cloned := ctx.Clone()
+	cancel()
+
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.ErrorIs(t, cloned.Err(), context.Canceled)
+
+	ctx.Context = nil
+	require.NoError(t, ctx.Err())
+}
+
+func TestResolveFunctionRefStopsWhenContextDone(t *testing.T) {
+	called := false
+	funcs := map[string]functions.MessageFunction{
+		"test": func(ctx functions.MessageFunctionContext, options functions.Options, operand any) messagevalue.MessageValue {
+			called = true
+			return messagevalue.NewStringValue("test", "en", "test")
+		},
+	}
+	var reported []error
+	ctx := NewContext([]string{"en"}, funcs, nil, func(err error) { reported = append(reported, err) }, "best fit")
+	parent, cancel := context.WithCancel(t.Context())
+	cancel()
+	ctx.Context = parent
+
+	functionRef, err := datamodel.NewFunctionRef("test", nil)
+	require.NoError(t, err)
+
+	mv := ResolveFunctionRef(ctx, nil, functionRef)
+	assert.Equal(t, "fallback", mv.Type())
+	assert.False(t, called)
+	assert.Empty(t, reported)
+}
+
+func TestContextLocale(t *testing.T) {
+	ctx := NewContext([]string{"xx-YY", "fr"}, nil, nil, nil, "best fit")
+	assert.Equal(t, "fr", ctx.Locale())
+
+	ctx.Locales = []string{"de"}
+	assert.Equal(t, "fr", ctx.Locale(), "negotiated once")
+	assert.Equal(t, "fr", ctx.Clone().NegotiatedLocale)
+	assert.Zero(t, testing.AllocsPerRun(10, func() { ctx.Locale() }))
+
+	ctx = NewContext([]string{"fr"}, nil, nil, nil, "best fit")
+	ctx.NegotiatedLocale = "de"
+	assert.Equal(t, "de", ctx.Locale(), "negotiated by the caller")
+}
+
+func TestContextLocateError(t *testing.T) {
+	ctx := NewContext([]string{"en"}, nil, nil, nil, "best fit")
+	err := pkgErrors.NewMessageResolutionError(pkgErrors.ErrorTypeBadOperand, "bad operand", "$x")
+
+	assert.Same(t, err, ctx.LocateError(err), "untracked")
+
+	assert.Zero(t, testing.AllocsPerRun(10, ctx.TrackSpans))
+	clone := ctx.Clone()
+	prev := clone.EnterSpan(3, 7)
+	located := ctx.LocateError(err)
+	var resolutionErr *pkgErrors.MessageResolutionError
+	require.ErrorAs(t, located, &resolutionErr)
+	assert.Equal(t, 3, resolutionErr.Start)
+	assert.Equal(t, 7, resolutionErr.End)
+	assert.Less(t, err.Start, 0, "shared error modified")
+
+	clone.ExitSpan(prev)
+	assert.Same(t, err, ctx.LocateError(err), "outside any expression")
+}
@@ -70,6 +70,11 @@ func ResolveFunctionRef(
 	// matches TypeScript: const source = getValueSource(operand) ?? `:${name}`;
 	source := cmp.Or(getValueSource(operand), ":"+functionRef.Name())
 
+	// A done formatting context stops resolution; the caller reports ctx.Err() once
+	if ctx.Err() != nil {
+		return functions.FallbackFunction(source, ctx.Locale())
+	}
+
 	// matches TypeScript: try { ... } catch (error) { ctx.onError(error); return fallback(source); }
 	result, err := resolveFunctionRefInternal(ctx, operand, functionRef, source)
 	if err != nil {
@@ -77,7 +82,7 @@ func ResolveFunctionRef(
 			ctx.OnError(err)
 		}
 		// Return fallback value
-		return functions.FallbackFunction(source, functions.GetFirstLocale(ctx.Locales))
+		return functions.FallbackFunction(source, ctx.Locale())
 	}
 	return result
 }
@@ -123,7 +128,11 @@ func resolveFunctionRefInternal(
 	opt := resolveOptions(ctx, options)
 
 	// matches TypeScript: let res = rf(msgCtx, opt, ...fnInput);
-	res := rf(msgCtx, functions.NewOptions(opt), input)
+	// opt is freshly built for this call, so it is passed without a copy
+	res, err := callFunction(rf, msgCtx, functions.Options(opt), input)
+	if err != nil {
+		return nil, err
+	}
 
 	// matches TypeScript: if (res === null || ...) { throw new MessageError('bad-function-result', ...); }
 	if res == nil {
@@ -136,22 +145,26 @@ func resolveFunctionRefInternal(
 
 	// Handle bidi isolation and ID setting like TypeScript
 	// matches TypeScript: if (msgCtx.dir) res = { ...res, dir: msgCtx.dir, [BIDI_ISOLATE]: true };
-	if msgCtx.Dir() != "" || msgCtx.ID() != "" {
-		wrapped := &messageValueWithOptions{
-			wrapped:     res,
-			dir:         msgCtx.Dir(),
-			id:          msgCtx.ID(),
-			bidiIsolate: msgCtx.Dir() != "",
-		}
-		if _, ok := res.(messagevalue.Selector); ok {
-			res = &selectableMessageValueWithOptions{messageValueWithOptions: wrapped}
-		} else {
-			res = wrapped
-		}
-	}
-
 	// matches TypeScript: return res;
-	return res, nil
+	return withUniversalOptions(res, msgCtx.Dir(), msgCtx.ID()), nil
+}
+
+// withUniversalOptions applies the u:dir and u:id options of a function call
+// to its result.
+func withUniversalOptions(res messagevalue.MessageValue, dir, id string) messagevalue.MessageValue {
+	if dir == "" && id == "" {
+		return res
+	}
+	wrapped := &messageValueWithOptions{
+		wrapped:     res,
+		dir:         dir,
+		id:          id,
+		bidiIsolate: dir != "",
+	}
+	if _, ok := res.(messagevalue.Selector); ok {
+		return &selectableMessageValueWithOptions{messageValueWithOptions: wrapped}
+	}
+	return wrapped
 }
 
 // createMessageFunctionContext creates a MessageFunctionContext with options
@@ -253,7 +266,7 @@ func createMessageFunctionContext(
 		literalKeys,
 		dir,
 		id,
-	)
+	).WithLocale(ctx.Locale()).WithContext(ctx.Context)
 }
 
 // resolveOptions resolves function options
@@ -289,20 +302,22 @@ func resolveOptions(ctx *Context, options map[string]any) map[string]any {
 			resolved = value
 		}
 
-		if mv, ok := resolved.(messagevalue.MessageValue); ok {
-			if valueOf, err := mv.ValueOf(); err == nil && valueOf != nil {
-				opt[name] = valueOf
-			} else {
-				opt[name] = resolved
-			}
-		} else {
-			opt[name] = resolved
-		}
+		opt[name] = optionValue(resolved)
 	}
 
 	return opt
 }
 
+// optionValue unwraps a resolved MessageValue option to its underlying value.
+func optionValue(resolved any) any {
+	if mv, ok := resolved.(messagevalue.MessageValue); ok {
+		if valueOf, err := mv.ValueOf(); err == nil && valueOf != nil {
+			return valueOf
+		}
+	}
+	return resolved
+}
+
 // isUniversalOption checks if an option is a universal option
 func isUniversalOption(name string) bool {
 	return len(name) > 2 && strings.HasPrefix(name, "u:")
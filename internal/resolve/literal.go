@@ -26,7 +26,7 @@ func ResolveLiteral(ctx *Context, literal *datamodel.Literal) messagevalue.Messa
 		nil,
 		"",
 		"",
-	)
+	).WithLocale(ctx.Locale()).WithContext(ctx.Context)
 
 	// Use string function to handle literal values - matches TypeScript: return string(msgCtx, {}, lit.value);
 	stringFunc, exists := ctx.Functions["string"]
@@ -34,11 +34,11 @@ func ResolveLiteral(ctx *Context, literal *datamodel.Literal) messagevalue.Messa
 		// Fallback if string function not available
 		return messagevalue.NewStringValue(
 			literal.Value(),
-			functions.GetFirstLocale(ctx.Locales),
+			ctx.Locale(),
 			source,
 		)
 	}
 
 	// matches TypeScript: return string(msgCtx, {}, lit.value);
-	return stringFunc(msgCtx, make(map[string]any), literal.Value())
+	return callImplicitFunction(ctx, stringFunc, msgCtx, literal.Value())
 }
@@ -39,6 +39,9 @@ import (
 //	  return part;
 //	}
 func FormatMarkup(ctx *Context, markup *datamodel.Markup) messagevalue.MessagePart {
+	if ctx.Span != nil {
+		defer ctx.ExitSpan(ctx.EnterSpan(markup.GetPosition()))
+	}
 	part := messagevalue.NewMarkupPart(
 		string(markup.Kind()),
 		markup.Name(),
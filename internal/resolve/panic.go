@@ -0,0 +1,72 @@
+package resolve
+
+import (
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// Message functions and the values they return are application code, and a
+// translator may give them any input. A panic in one of them is recovered and
+// reported as a bad function result error, so that the expression formats as
+// its fallback instead of taking down the goroutine that formats the message.
+
+// recoverPanic sets err to a bad function result error for a panic. It must be
+// deferred directly.
+func recoverPanic(source string, err *error) {
+	if r := recover(); r != nil {
+		*err = errors.NewPanicError(r, source)
+	}
+}
+
+// callFunction calls the message function fn.
+func callFunction(
+	fn functions.MessageFunction,
+	ctx functions.MessageFunctionContext,
+	options functions.Options,
+	operand any,
+) (res messagevalue.MessageValue, err error) {
+	defer recoverPanic(ctx.Source(), &err)
+	return fn(ctx, options, operand), nil
+}
+
+// callImplicitFunction calls fn, the function that formats a literal or a
+// variable without a function annotation. A panic in fn is reported and
+// gives a fallback value.
+func callImplicitFunction(
+	ctx *Context,
+	fn functions.MessageFunction,
+	msgCtx functions.MessageFunctionContext,
+	operand any,
+) messagevalue.MessageValue {
+	res, err := callFunction(fn, msgCtx, make(map[string]any), operand)
+	if err != nil {
+		if ctx.OnError != nil {
+			ctx.OnError(err)
+		}
+		return functions.FallbackFunction(msgCtx.Source(), ctx.Locale())
+	}
+	return res
+}
+
+// ToString formats mv as a string.
+func ToString(mv messagevalue.MessageValue) (s string, err error) {
+	defer recoverPanic(mv.Source(), &err)
+	return mv.ToString()
+}
+
+// ToParts formats mv as message parts.
+func ToParts(mv messagevalue.MessageValue) (parts []messagevalue.MessagePart, err error) {
+	defer recoverPanic(mv.Source(), &err)
+	return mv.ToParts()
+}
+
+// SelectKeys returns the keys that selector matches.
+func SelectKeys(selector messagevalue.Selector, keys []string) (selected []string, err error) {
+	source := ""
+	if value, ok := selector.(messagevalue.Value); ok {
+		source = value.Source()
+	}
+	defer recoverPanic(source, &err)
+	return selector.SelectKeys(keys)
+}
@@ -82,3 +82,17 @@ func getValueSource(value datamodel.Node) string {
 		return ""
 	}
 }
+
+// ExpressionSource returns the source that errors of a function call report:
+// its operand, or the function name when it has none.
+func ExpressionSource(expr *datamodel.Expression) string {
+	if arg, ok := expr.Arg().(datamodel.Node); ok {
+		if source := getValueSource(arg); source != "" {
+			return source
+		}
+	}
+	if functionRef := expr.FunctionRef(); functionRef != nil {
+		return ":" + functionRef.Name()
+	}
+	return ""
+}
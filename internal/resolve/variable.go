@@ -3,10 +3,17 @@
 package resolve
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/cockroachdb/apd/v3"
 	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
 	"github.com/kaptinlin/messageformat-go/pkg/errors"
 	"github.com/kaptinlin/messageformat-go/pkg/functions"
@@ -28,6 +35,10 @@ import (
 type UnresolvedExpression struct {
 	Expression *datamodel.Expression
 	Scope      map[string]any
+
+	// Compiled is the prepared form of Expression; when set it is resolved
+	// with ResolveCompiled instead of ResolveExpression.
+	Compiled *CompiledExpression
 }
 
 // NewUnresolvedExpression creates a new unresolved expression
@@ -95,15 +106,8 @@ func getValue(scope any, name string) (any, bool) {
 		}
 
 		// Dotted property access - matches TypeScript parts logic
-		if strings.Contains(name, ".") {
-			parts := strings.Split(name, ".")
-			for i := len(parts) - 1; i > 0; i-- {
-				head := strings.Join(parts[:i], ".")
-				if headValue, exists := m[head]; exists {
-					tail := strings.Join(parts[i:], ".")
-					return getValue(headValue, tail)
-				}
-			}
+		if value, found, matched := getDottedValue(scope, name); matched {
+			return value, found
 		}
 
 		for key, value := range m {
@@ -111,17 +115,299 @@ func getValue(scope any, name string) (any, bool) {
 				return value, true
 			}
 		}
+		return nil, false
 	}
-	// Handle map[interface{}]interface{} types
-	if m, ok := scope.(map[any]any); ok {
-		if value, exists := m[name]; exists {
+
+	// Handle map[interface{}]interface{}, typed maps, structs, and pointers
+	if value, exists := getMember(scope, name); exists {
+		return value, true
+	}
+	if value, found, matched := getDottedValue(scope, name); matched {
+		return value, found
+	}
+
+	return nil, false
+}
+
+// getDottedValue resolves the longest matching `.` delimited head of name in
+// scope and continues the lookup for the remaining tail. matched reports
+// whether any head was present in scope.
+func getDottedValue(scope any, name string) (value any, found, matched bool) {
+	if !strings.Contains(name, ".") {
+		return nil, false, false
+	}
+
+	parts := strings.Split(name, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		head := strings.Join(parts[:i], ".")
+		if headValue, exists := getMember(scope, head); exists {
+			if _, ok := headValue.(*getterPanic); ok {
+				return headValue, true, true
+			}
+			tail := strings.Join(parts[i:], ".")
+			value, found = getValue(headValue, tail)
+			return value, found, true
+		}
+	}
+	return nil, false, false
+}
+
+// getMember looks up a single key in a scope-like value.
+// Maps are indexed by key; structs expose exported fields, matched by their
+// `mf:"name"` tag or case-insensitively by field name, followed by exported
+// getter methods such as GetName(). Pointers and interfaces are dereferenced;
+// a nil pointer has no members, but a nil member is a found value.
+func getMember(scope any, key string) (any, bool) {
+	switch m := scope.(type) {
+	case map[string]any:
+		value, exists := m[key]
+		return value, exists
+	case map[any]any:
+		value, exists := m[key]
+		return value, exists
+	}
+
+	rv := reflect.ValueOf(scope)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		return getMapValue(rv, key)
+	case reflect.Struct:
+		if value, exists := getFieldValue(rv, key); exists {
 			return value, true
 		}
+		// Use the pointer method set so pointer receivers are found too
+		if rv.CanAddr() {
+			return getMethodValue(rv.Addr(), key)
+		}
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		return getMethodValue(ptr, key)
+	default:
+		return nil, false
+	}
+}
+
+// getMapValue indexes a map with a string-compatible key type.
+func getMapValue(rv reflect.Value, key string) (any, bool) {
+	keyType := rv.Type().Key()
+	var keyValue reflect.Value
+	switch {
+	case keyType.Kind() == reflect.String:
+		keyValue = reflect.ValueOf(key).Convert(keyType)
+	case keyType.Kind() == reflect.Interface && reflect.TypeFor[string]().Implements(keyType):
+		keyValue = reflect.ValueOf(key)
+	default:
+		return nil, false
+	}
+
+	value := rv.MapIndex(keyValue)
+	if !value.IsValid() {
+		return nil, false
+	}
+	return reflectValueInterface(value)
+}
+
+// structFieldIndexes caches the lookup table of each struct type.
+var structFieldIndexes sync.Map // map[reflect.Type]*structFields
+
+// structFields maps scope keys to field indexes of one struct type.
+type structFields struct {
+	byName map[string][]int
+	byFold map[string][]int
+}
+
+// getStructFields returns the cached field table for a struct type.
+// Tagged names take precedence over field names; `mf:"-"` hides a field.
+func getStructFields(t reflect.Type) *structFields {
+	if cached, ok := structFieldIndexes.Load(t); ok {
+		return cached.(*structFields)
+	}
+
+	var taggedFields, plainFields []reflect.StructField
+	names := make(map[string]string)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(field.Tag.Get("mf"), ",")
+		switch tag {
+		case "-":
+			continue
+		case "":
+			plainFields = append(plainFields, field)
+			names[field.Name] = field.Name
+		default:
+			taggedFields = append(taggedFields, field)
+			names[field.Name] = tag
+		}
+	}
+
+	fields := &structFields{
+		byName: make(map[string][]int),
+		byFold: make(map[string][]int),
+	}
+	for _, field := range slices.Concat(taggedFields, plainFields) {
+		name := names[field.Name]
+		if _, exists := fields.byName[name]; !exists {
+			fields.byName[name] = field.Index
+		}
+		if _, exists := fields.byFold[strings.ToLower(name)]; !exists {
+			fields.byFold[strings.ToLower(name)] = field.Index
+		}
+	}
+
+	actual, _ := structFieldIndexes.LoadOrStore(t, fields)
+	return actual.(*structFields)
+}
+
+// getFieldValue reads an exported struct field by tag or field name.
+func getFieldValue(rv reflect.Value, key string) (any, bool) {
+	fields := getStructFields(rv.Type())
+	index, exists := fields.byName[key]
+	if !exists {
+		index, exists = fields.byFold[strings.ToLower(key)]
+	}
+	if !exists {
+		return nil, false
+	}
+
+	field, err := rv.FieldByIndexErr(index)
+	if err != nil {
+		// Promoted through a nil embedded pointer
+		return nil, false
+	}
+	return reflectValueInterface(field)
+}
+
+// getMethodValue calls the exported getter method for key. See findGetter.
+func getMethodValue(rv reflect.Value, key string) (any, bool) {
+	index, exists := findGetter(rv.Type(), key)
+	if !exists {
+		return nil, false
+	}
+	return callGetter(rv.Method(index))
+}
+
+// getterPanic is the value of a getter that panicked. lookupVariableRef
+// reports it and resolves the variable to a fallback value.
+type getterPanic struct {
+	value any
+}
+
+// callGetter calls the getter method. A panic in it gives a getterPanic.
+func callGetter(method reflect.Value) (value any, found bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, found = &getterPanic{value: r}, true
+		}
+	}()
+	return reflectValueInterface(method.Call(nil)[0])
+}
+
+// findGetter returns the method index of the getter for key in the method set
+// of t: Get followed by key with its first letter upper-cased, such as GetCity
+// for "city". Only methods without arguments and with a single result are
+// getters. Other methods are never called, so a message cannot run methods
+// such as Commit or Close by naming them.
+func findGetter(t reflect.Type, key string) (int, bool) {
+	first, size := utf8.DecodeRuneInString(key)
+	if first == utf8.RuneError {
+		return 0, false
+	}
+	method, exists := t.MethodByName("Get" + string(unicode.ToUpper(first)) + key[size:])
+	if !exists || method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+		return 0, false
+	}
+	return method.Index, true
+}
+
+// reflectValueInterface converts a reflected value back to an interface.
+// Nil pointers and interfaces are found values, like nil map entries; only
+// invalid values and values of unexported fields are missing.
+func reflectValueInterface(value reflect.Value) (any, bool) {
+	if !value.IsValid() || !value.CanInterface() {
+		return nil, false
+	}
+	return value.Interface(), true
+}
+
+// GetValue looks up a `.` delimited variable name in scope with the same
+// rules as variable references: maps by key, struct fields by tag or name,
+// getter methods, and pointer dereference.
+func GetValue(scope any, name string) (any, bool) {
+	return getValue(scope, name)
+}
+
+// LookupType statically resolves name against values of type t with the
+// rules of GetValue and returns the Go type that would be found.
+// Interface types end the static walk: members of an interface-typed value
+// are only known at runtime, so the interface type itself is returned.
+func LookupType(t reflect.Type, name string) (reflect.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if memberType, exists := lookupMemberType(t, name); exists {
+		return memberType, true
+	}
+	if !strings.Contains(name, ".") {
+		return nil, false
 	}
 
+	parts := strings.Split(name, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		head := strings.Join(parts[:i], ".")
+		if headType, exists := lookupMemberType(t, head); exists {
+			if headType.Kind() == reflect.Interface {
+				return headType, true
+			}
+			return LookupType(headType, strings.Join(parts[i:], "."))
+		}
+	}
 	return nil, false
 }
 
+// lookupMemberType is the static counterpart of getMember.
+func lookupMemberType(t reflect.Type, key string) (reflect.Type, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return t, true
+	case reflect.Map:
+		keyType := t.Key()
+		if keyType.Kind() == reflect.String ||
+			(keyType.Kind() == reflect.Interface && reflect.TypeFor[string]().Implements(keyType)) {
+			return t.Elem(), true
+		}
+		return nil, false
+	case reflect.Struct:
+		fields := getStructFields(t)
+		index, exists := fields.byName[key]
+		if !exists {
+			index, exists = fields.byFold[strings.ToLower(key)]
+		}
+		if exists {
+			return t.FieldByIndex(index).Type, true
+		}
+		ptrType := reflect.PointerTo(t)
+		if i, exists := findGetter(ptrType, key); exists {
+			return ptrType.Method(i).Type.Out(0), true
+		}
+		return nil, false
+	default:
+		return nil, false
+	}
+}
+
 // lookupVariableRef looks up a variable reference and resolves it
 // TypeScript original code:
 //
@@ -146,6 +432,14 @@ func lookupVariableRef(ctx *Context, ref *datamodel.VariableRef) (any, bool) {
 	name := ref.Name()
 	value, found := getValue(ctx.Scope, name)
 
+	if p, ok := value.(*getterPanic); ok {
+		source := "$" + name
+		if ctx.OnError != nil {
+			ctx.OnError(errors.NewPanicError(p.value, source))
+		}
+		return messagevalue.NewFallbackValue(source, ctx.Locale()), true
+	}
+
 	if !found {
 		source := "$" + name
 		msg := fmt.Sprintf("variable not available: %s", source)
@@ -162,7 +456,7 @@ func lookupVariableRef(ctx *Context, ref *datamodel.VariableRef) (any, bool) {
 	// Handle unresolved expressions - matches TypeScript: value instanceof UnresolvedExpression
 	if unresolvedExpr, ok := value.(*UnresolvedExpression); ok {
 		if originalValue, ok := unresolvedInputValue(unresolvedExpr); ok {
-			return originalValue, true
+			return adaptValue(ctx, name, originalValue)
 		}
 
 		// Check for circular reference by looking if we're already resolving this variable
@@ -180,7 +474,7 @@ func lookupVariableRef(ctx *Context, ref *datamodel.VariableRef) (any, bool) {
 					source,
 				))
 			}
-			return messagevalue.NewFallbackValue(source, functions.GetFirstLocale(ctx.Locales)), true
+			return messagevalue.NewFallbackValue(source, ctx.Locale()), true
 		}
 
 		// Mark this variable as being resolved
@@ -198,16 +492,98 @@ func lookupVariableRef(ctx *Context, ref *datamodel.VariableRef) (any, bool) {
 		}
 
 		// Resolve the expression
-		local := ResolveExpression(newCtx, unresolvedExpr.Expression)
+		var local messagevalue.MessageValue
+		if unresolvedExpr.Compiled != nil {
+			local = ResolveCompiled(newCtx, unresolvedExpr.Compiled)
+		} else {
+			local = ResolveExpression(newCtx, unresolvedExpr.Expression)
+		}
 
 		// Cache the resolved value - matches TypeScript: ctx.scope[name] = local; ctx.localVars.add(local);
 		ctx.Scope[name] = local
+		if ctx.LocalVars == nil {
+			ctx.LocalVars = make(map[messagevalue.MessageValue]bool)
+		}
 		ctx.LocalVars[local] = true
 
 		return local, true
 	}
 
-	return value, true
+	return adaptValue(ctx, name, value)
+}
+
+// adaptValue converts an input value with the ValueAdapter registered for its
+// type or its MessageValuer method. Conversions are kept for the rest of the
+// formatting call, so each variable is converted once.
+func adaptValue(ctx *Context, name string, value any) (any, bool) {
+	if value == nil {
+		return nil, true
+	}
+	if _, ok := value.(messagevalue.MessageValue); ok {
+		return value, true
+	}
+	adapter := ctx.ValueAdapters[reflect.TypeOf(value)]
+	valuer, isValuer := value.(functions.MessageValuer)
+	if adapter == nil && (!isValuer || isNilPointer(value)) {
+		return value, true
+	}
+	if mv, ok := ctx.Adapted[name]; ok {
+		return mv, true
+	}
+
+	source := "$" + name
+	msgCtx := functions.NewMessageFunctionContext(
+		ctx.Locales,
+		source,
+		ctx.LocaleMatcher,
+		ctx.OnError,
+		nil,
+		"",
+		"",
+	).WithLocale(ctx.Locale()).WithContext(ctx.Context)
+	mv, err := convertValue(msgCtx, adapter, valuer, value)
+	if err != nil {
+		if ctx.OnError != nil {
+			ctx.OnError(err)
+		}
+		return nil, false
+	}
+	if mv == nil {
+		if ctx.OnError != nil {
+			ctx.OnError(errors.NewMessageResolutionError(
+				errors.ErrorTypeBadFunctionResult,
+				fmt.Sprintf("value adapter for %T did not return a MessageValue", value),
+				source,
+			))
+		}
+		return nil, false
+	}
+
+	if ctx.Adapted == nil {
+		ctx.Adapted = make(map[string]messagevalue.MessageValue)
+	}
+	ctx.Adapted[name] = mv
+	return mv, true
+}
+
+// convertValue converts value with adapter, or with valuer when adapter is nil.
+func convertValue(
+	ctx functions.MessageFunctionContext,
+	adapter functions.ValueAdapter,
+	valuer functions.MessageValuer,
+	value any,
+) (mv messagevalue.MessageValue, err error) {
+	defer recoverPanic(ctx.Source(), &err)
+	if adapter != nil {
+		return adapter(ctx, value), nil
+	}
+	return valuer.MessageValue(ctx), nil
+}
+
+// isNilPointer reports whether value is a typed nil pointer.
+func isNilPointer(value any) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
 }
 
 func unresolvedInputValue(unresolvedExpr *UnresolvedExpression) (any, bool) {
@@ -221,7 +597,9 @@ func unresolvedInputValue(unresolvedExpr *UnresolvedExpression) (any, bool) {
 	}
 
 	if originalValue, exists := getValue(unresolvedExpr.Scope, varRef.Name()); exists {
-		if _, isUnresolved := originalValue.(*UnresolvedExpression); !isUnresolved {
+		switch originalValue.(type) {
+		case *UnresolvedExpression, *getterPanic:
+		default:
 			return originalValue, true
 		}
 	}
@@ -267,7 +645,7 @@ func ResolveVariableRef(ctx *Context, ref *datamodel.VariableRef) messagevalue.M
 	source := "$" + ref.Name()
 	value, found := lookupVariableRef(ctx, ref)
 	if !found {
-		return messagevalue.NewFallbackValue(source, functions.GetFirstLocale(ctx.Locales))
+		return messagevalue.NewFallbackValue(source, ctx.Locale())
 	}
 
 	// Determine type - matches TypeScript: let type = typeof value;
@@ -279,12 +657,16 @@ func ResolveVariableRef(ctx *Context, ref *datamodel.VariableRef) messagevalue.M
 		if mv, ok := value.(messagevalue.MessageValue); ok {
 			// Check for fallback type - matches TypeScript: if (mv.type === 'fallback') return fallback(source);
 			if mv.Type() == "fallback" {
-				return messagevalue.NewFallbackValue(source, functions.GetFirstLocale(ctx.Locales))
+				return messagevalue.NewFallbackValue(source, ctx.Locale())
 			}
 			// Check if it's a local variable - matches TypeScript: if (ctx.localVars.has(mv)) return mv;
 			if ctx.LocalVars[mv] {
 				return mv
 			}
+			// Values converted by a value adapter are used as they are
+			if adapted, ok := ctx.Adapted[ref.Name()]; ok && adapted == mv {
+				return mv
+			}
 		}
 
 		// Handle Number and String objects - matches TypeScript: if (value instanceof Number) type = 'number';
@@ -293,6 +675,10 @@ func ResolveVariableRef(ctx *Context, ref *datamodel.VariableRef) messagevalue.M
 			valueType = "number"
 		case *string:
 			valueType = "string"
+		case *big.Int:
+			valueType = "bigint"
+		case *big.Float, *big.Rat, *apd.Decimal, json.Number:
+			valueType = "number"
 		}
 	}
 
@@ -309,9 +695,9 @@ func ResolveVariableRef(ctx *Context, ref *datamodel.VariableRef) messagevalue.M
 				nil,
 				"",
 				"",
-			)
+			).WithLocale(ctx.Locale()).WithContext(ctx.Context)
 			// matches TypeScript: return ctx.functions.number(msgCtx, {}, value);
-			return numberFunc(msgCtx, make(map[string]any), value)
+			return callImplicitFunction(ctx, numberFunc, msgCtx, value)
 		}
 	case "string":
 		// matches TypeScript: const msgCtx = new MessageFunctionContext(ctx, source);
@@ -324,13 +710,13 @@ func ResolveVariableRef(ctx *Context, ref *datamodel.VariableRef) messagevalue.M
 				nil,
 				"",
 				"",
-			)
+			).WithLocale(ctx.Locale()).WithContext(ctx.Context)
 			// matches TypeScript: return ctx.functions.string(msgCtx, {}, value);
-			return stringFunc(msgCtx, make(map[string]any), value)
+			return callImplicitFunction(ctx, stringFunc, msgCtx, value)
 		}
 	}
 
-	return messagevalue.NewUnknownValue(source, value, functions.GetFirstLocale(ctx.Locales))
+	return messagevalue.NewUnknownValue(source, value, ctx.Locale())
 }
 
 // getValueType determines the type of a value similar to TypeScript typeof
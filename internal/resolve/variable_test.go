@@ -1,12 +1,14 @@
 package resolve
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	pkgErrors "github.com/kaptinlin/messageformat-go/pkg/errors"
 	"github.com/kaptinlin/messageformat-go/pkg/functions"
 	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
 )
@@ -55,6 +57,234 @@ func TestGetValue(t *testing.T) {
 	assert.Nil(t, value)
 }
 
+type testAddress struct {
+	City string `mf:"city"`
+	Zip  string
+}
+
+type testBase struct {
+	ID int
+}
+
+type testUser struct {
+	testBase
+	FullName string `mf:"name"`
+	Email    string
+	Secret   string `mf:"-"`
+	Address  *testAddress
+	Manager  *testUser
+	Any      any
+	Tags     map[string]string
+	Refs     map[string]*testAddress
+	Extra    map[string]any
+	internal string
+}
+
+func (u testUser) Initials() string {
+	return u.FullName[:1]
+}
+
+func (u *testUser) GetDisplayName() string {
+	return u.FullName + " <" + u.Email + ">"
+}
+
+func (u testUser) GetBackup() *testAddress {
+	return nil
+}
+
+func (u testUser) Describe(prefix string) string {
+	return prefix + u.FullName
+}
+
+// testResource has methods that variable paths must not call.
+type testResource struct {
+	calls *[]string
+}
+
+func (r testResource) GetName() string { return "resource" }
+func (r testResource) Name() string {
+	*r.calls = append(*r.calls, "Name")
+	return "name"
+}
+func (r testResource) Commit() error {
+	*r.calls = append(*r.calls, "Commit")
+	return nil
+}
+func (r testResource) String() string {
+	*r.calls = append(*r.calls, "String")
+	return "formatted"
+}
+
+// testPanicGetter has a getter that panics without an address.
+type testPanicGetter struct {
+	Address *testAddress
+}
+
+func (g testPanicGetter) GetCity() string { return g.Address.City }
+
+func TestGetValueGetters(t *testing.T) {
+	var calls []string
+	scope := map[string]any{
+		"res":  testResource{calls: &calls},
+		"zero": testPanicGetter{},
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		want  any
+		found bool
+	}{
+		{name: "Get prefixed getter", path: "res.name", want: "resource", found: true},
+		{name: "upper-case key", path: "res.Name", want: "resource", found: true},
+		{name: "only the first letter is upper-cased", path: "res.NAME", found: false},
+		{name: "method named like the key", path: "res.commit", found: false},
+		{name: "exact method name", path: "res.Commit", found: false},
+		{name: "String is not a getter", path: "res.string", found: false},
+		{name: "panicking getter", path: "zero.city", want: &getterPanic{}, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := getValue(scope, tt.path)
+			assert.Equal(t, tt.found, found)
+			if p, ok := value.(*getterPanic); ok {
+				assert.NotNil(t, p.value)
+				return
+			}
+			assert.Equal(t, tt.want, value)
+		})
+	}
+	assert.Empty(t, calls, "methods without the Get prefix were called")
+}
+
+func TestResolveVariableRefGetterPanic(t *testing.T) {
+	var errs []error
+	ctx := NewContext(
+		[]string{"en"},
+		functions.DefaultFunctionMap(),
+		map[string]any{"item": testPanicGetter{Address: &testAddress{City: "Berlin"}}, "zero": testPanicGetter{}},
+		func(err error) { errs = append(errs, err) },
+		"best fit")
+
+	result := ResolveVariableRef(ctx, datamodel.NewVariableRef("item.city"))
+	assert.Equal(t, "string", result.Type())
+	assert.Empty(t, errs)
+
+	result = ResolveVariableRef(ctx, datamodel.NewVariableRef("zero.city"))
+	assert.Equal(t, "fallback", result.Type())
+	require.Len(t, errs, 1)
+	var panicErr *pkgErrors.PanicError
+	require.ErrorAs(t, errs[0], &panicErr)
+}
+
+func TestGetValueStructs(t *testing.T) {
+	user := &testUser{
+		testBase: testBase{ID: 7},
+		FullName: "Alice",
+		Email:    "alice@example.com",
+		Secret:   "hidden",
+		Address:  &testAddress{City: "Berlin", Zip: "10115"},
+		Tags:     map[string]string{"role": "admin"},
+		Refs:     map[string]*testAddress{"none": nil},
+		Extra:    map[string]any{"plan": map[string]any{"tier": "pro"}, "none": nil},
+		internal: "private",
+	}
+	scope := map[string]any{
+		"user":  user,
+		"value": *user,
+		"typed": map[string]int{"count": 3},
+		"any":   map[any]any{"key": "v"},
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		want  any
+		found bool
+	}{
+		{name: "tagged field", path: "user.name", want: "Alice", found: true},
+		{name: "field name", path: "user.Email", want: "alice@example.com", found: true},
+		{name: "case-insensitive field name", path: "user.email", want: "alice@example.com", found: true},
+		{name: "promoted field", path: "user.id", want: 7, found: true},
+		{name: "nested pointer struct", path: "user.address.city", want: "Berlin", found: true},
+		{name: "nested untagged field", path: "user.address.zip", want: "10115", found: true},
+		{name: "typed map in struct", path: "user.tags.role", want: "admin", found: true},
+		{name: "nested maps in struct", path: "user.extra.plan.tier", want: "pro", found: true},
+		{name: "method without Get prefix", path: "user.initials", found: false},
+		{name: "pointer getter", path: "user.displayName", want: "Alice <alice@example.com>", found: true},
+		{name: "struct value", path: "value.name", want: "Alice", found: true},
+		{name: "pointer getter on struct value", path: "value.displayName", want: "Alice <alice@example.com>", found: true},
+		{name: "typed map", path: "typed.count", want: 3, found: true},
+		{name: "interface map", path: "any.key", want: "v", found: true},
+		{name: "ignored field", path: "user.Secret", found: false},
+		{name: "unexported field", path: "user.internal", found: false},
+		{name: "method with arguments", path: "user.describe", found: false},
+		{name: "nil pointer field", path: "user.manager", want: (*testUser)(nil), found: true},
+		{name: "nil interface field", path: "user.any", want: nil, found: true},
+		{name: "nil map entry", path: "user.extra.none", want: nil, found: true},
+		{name: "typed nil map entry", path: "user.refs.none", want: (*testAddress)(nil), found: true},
+		{name: "nil getter result", path: "user.backup", want: (*testAddress)(nil), found: true},
+		{name: "through nil pointer", path: "user.manager.name", found: false},
+		{name: "missing field", path: "user.missing", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := getValue(scope, tt.path)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestLookupType(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		want  reflect.Type
+		found bool
+	}{
+		{name: "tagged field", path: "name", want: reflect.TypeFor[string](), found: true},
+		{name: "promoted field", path: "id", want: reflect.TypeFor[int](), found: true},
+		{name: "nested pointer struct", path: "address.city", want: reflect.TypeFor[string](), found: true},
+		{name: "typed map element", path: "tags.role", want: reflect.TypeFor[string](), found: true},
+		{name: "interface map element ends walk", path: "extra.plan.tier", want: reflect.TypeFor[any](), found: true},
+		{name: "method without Get prefix", path: "initials", found: false},
+		{name: "pointer getter", path: "displayName", want: reflect.TypeFor[string](), found: true},
+		{name: "ignored field", path: "secret", found: false},
+		{name: "method with arguments", path: "describe", found: false},
+		{name: "missing nested field", path: "address.country", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := LookupType(reflect.TypeFor[*testUser](), tt.path)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveVariableRefStruct(t *testing.T) {
+	ctx := NewContext(
+		[]string{"en"},
+		functions.DefaultFunctionMap(),
+		map[string]any{
+			"user": &testUser{FullName: "Alice", Address: &testAddress{City: "Berlin"}},
+		},
+		nil, "best fit")
+
+	mv := ResolveVariableRef(ctx, datamodel.NewVariableRef("user.address.city"))
+	assert.Equal(t, "string", mv.Type())
+	str, err := mv.ToString()
+	require.NoError(t, err)
+	assert.Equal(t, "Berlin", str)
+
+	mv = ResolveVariableRef(ctx, datamodel.NewVariableRef("user.manager.name"))
+	assert.Equal(t, "fallback", mv.Type())
+}
+
 func TestLookupVariableRef(t *testing.T) {
 	ctx := NewContext(
 		[]string{"en"},
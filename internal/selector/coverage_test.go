@@ -70,6 +70,41 @@ func TestSelectPatternReportsNoMatchWhenNoVariantSurvives(t *testing.T) {
 	assertSelectorCoverageErrorType(t, errs[0], pkgerrors.ErrorTypeNoMatch)
 }
 
+func TestTableSelectIsReusable(t *testing.T) {
+	t.Parallel()
+
+	message := newSelectorCoverageMessage(t, "tier",
+		newSelectorCoverageVariant(t, datamodel.NewLiteral("gold"), "literal"),
+		newSelectorCoverageVariant(t, datamodel.NewCatchallKey("*"), "catchall"),
+	)
+	table := NewTable(message)
+
+	tests := []struct {
+		tier string
+		want int
+	}{
+		{tier: "gold", want: 0},
+		{tier: "silver", want: 1},
+		{tier: "gold", want: 0},
+	}
+	for _, tt := range tests {
+		index, ok := table.Select(newSelectorCoverageContext(map[string]any{"tier": tt.tier}, nil))
+		require.True(t, ok)
+		assert.Equal(t, tt.want, index)
+	}
+
+	var errs []error
+	noMatch := NewTable(newSelectorCoverageMessage(t, "tier",
+		newSelectorCoverageVariant(t, datamodel.NewLiteral("gold"), "literal"),
+	))
+	_, ok := noMatch.Select(newSelectorCoverageContext(map[string]any{"tier": "silver"}, func(err error) {
+		errs = append(errs, err)
+	}))
+	assert.False(t, ok)
+	require.Len(t, errs, 1)
+	assertSelectorCoverageErrorType(t, errs[0], pkgerrors.ErrorTypeNoMatch)
+}
+
 func TestSelectPatternReportsBadSelectorForNonSelectableValues(t *testing.T) {
 	t.Parallel()
 
@@ -114,7 +149,13 @@ func TestSelectPatternRecoversFromSelectorPanic(t *testing.T) {
 	result := SelectPattern(ctx, message)
 	assert.Equal(t, "catchall", selectorCoverageText(t, result))
 	require.Len(t, errs, 1)
-	assertSelectorCoverageErrorType(t, errs[0], pkgerrors.ErrorTypeBadSelector)
+	var resolutionErr *pkgerrors.MessageResolutionError
+	require.ErrorAs(t, errs[0], &resolutionErr)
+	assert.Equal(t, pkgerrors.ErrorTypeBadFunctionResult, resolutionErr.Type)
+	assert.Equal(t, "$value", resolutionErr.Source)
+	var panicErr *pkgerrors.PanicError
+	require.ErrorAs(t, errs[0], &panicErr)
+	assert.NotEmpty(t, panicErr.Stack)
 }
 
 func newSelectorCoverageContext(scope map[string]any, onError func(error)) *resolve.Context {
@@ -3,6 +3,7 @@
 package selector
 
 import (
+	stderrors "errors"
 	"slices"
 
 	"github.com/kaptinlin/messageformat-go/internal/resolve"
@@ -124,9 +125,41 @@ func SelectPattern(context *resolve.Context, message datamodel.Message) datamode
 //	  keys: null as Set<string> | null
 //	}
 type selectorContext struct {
-	selectKey func([]string) *string // matches TypeScript selectKey function
-	best      *string                // matches TypeScript: best: null as string | null
-	keys      *orderedKeySet         // matches TypeScript: keys: null as Set<string> | null
+	selector messagevalue.Selector // nil when selectKey always returns null
+	best     *string               // matches TypeScript: best: null as string | null
+	keys     *orderedKeySet        // matches TypeScript: keys: null as Set<string> | null
+}
+
+// selectKey returns the best key for keys, or nil when no key matches.
+// TypeScript original code:
+// selectKey = selector.selectKey.bind(selector);
+func (sc *selectorContext) selectKey(context *resolve.Context, keys []string) *string {
+	if sc.selector == nil || len(keys) == 0 {
+		return nil
+	}
+
+	// Call the MessageValue's SelectKeys method
+	selectedKeys, err := resolve.SelectKeys(sc.selector, keys)
+	if err != nil || len(selectedKeys) == 0 {
+		if err != nil && context.OnError != nil {
+			context.OnError(selectionError(err))
+		}
+		return nil
+	}
+
+	// Return the first selected key
+	return &selectedKeys[0]
+}
+
+// selectionError returns the error reported for an error from SelectKeys. A
+// recovered panic is reported as the bad function result error it already
+// is; other errors are bad selector errors.
+func selectionError(err error) error {
+	var panicErr *errors.PanicError
+	if stderrors.As(err, &panicErr) {
+		return err
+	}
+	return errors.NewMessageSelectionError(errors.ErrorTypeBadSelector, err)
 }
 
 type selectionCapability interface {
@@ -188,19 +221,68 @@ func (s *orderedKeySet) values() []string {
 	return slices.Clone(s.keys)
 }
 
+// Table is the precomputed selection input of a select message: its selectors
+// and, for every variant in message order, the variant keys.
+// A Table is immutable and safe for concurrent use.
+type Table struct {
+	selectors []datamodel.VariableRef
+	variants  [][]tableKey
+}
+
+// tableKey is a variant key reduced to what selection compares.
+type tableKey struct {
+	value    string
+	catchall bool
+	literal  bool
+}
+
+// NewTable precomputes the selection table of msg.
+func NewTable(msg *datamodel.SelectMessage) *Table {
+	variants := msg.Variants()
+	table := &Table{
+		selectors: msg.Selectors(),
+		variants:  make([][]tableKey, len(variants)),
+	}
+	for i, variant := range variants {
+		keys := variant.Keys()
+		tableKeys := make([]tableKey, 0, len(keys))
+		for _, key := range keys {
+			switch k := key.(type) {
+			case *datamodel.Literal:
+				tableKeys = append(tableKeys, tableKey{value: k.Value(), literal: true})
+			default:
+				tableKeys = append(tableKeys, tableKey{catchall: datamodel.IsCatchallKey(key)})
+			}
+		}
+		table.variants[i] = tableKeys
+	}
+	return table
+}
+
 // selectVariantPattern selects the best matching variant pattern
 // TypeScript original code: select case logic in selectPattern function
 func selectVariantPattern(context *resolve.Context, msg *datamodel.SelectMessage) datamodel.Pattern {
-	selectors := msg.Selectors()
-	variants := msg.Variants()
+	index, ok := NewTable(msg).Select(context)
+	if !ok {
+		return datamodel.Pattern{}
+	}
+	return msg.Variants()[index].Value()
+}
 
+// Select resolves the selectors in context and returns the index of the
+// selected variant. When no variant matches, a no-match error is reported and
+// ok is false.
+// TypeScript original code: select case logic in selectPattern function
+func (t *Table) Select(context *resolve.Context) (index int, ok bool) {
 	// matches TypeScript: const ctx = message.selectors.map(sel => { ... });
-	selectorCtxs := make([]*selectorContext, len(selectors))
-	for i, selector := range selectors {
+	selectorCtxs := make([]selectorContext, len(t.selectors))
+	for i := range t.selectors {
+		// Errors of selector i are located at its variable
+		span := context.EnterSpan(t.selectors[i].GetPosition())
+
 		// matches TypeScript: const selector = resolveVariableRef(context, sel);
-		mv := resolve.ResolveVariableRef(context, &selector)
+		mv := resolve.ResolveVariableRef(context, &t.selectors[i])
 
-		var selectKeyFunc func([]string) *string
 		// matches TypeScript: if (typeof selector.selectKey === 'function')
 		if selector, ok := mv.(messagevalue.Selector); ok {
 			if capability, ok := mv.(selectionCapability); ok && !capability.CanSelect() {
@@ -210,75 +292,50 @@ func selectVariantPattern(context *resolve.Context, msg *datamodel.SelectMessage
 						messagevalue.ErrNotSelectable,
 					))
 				}
-				selectKeyFunc = func([]string) *string { return nil }
 			} else {
 				// matches TypeScript: selectKey = selector.selectKey.bind(selector);
-				selectKeyFunc = func(keys []string) *string {
-					if len(keys) == 0 {
-						return nil
-					}
-
-					// Call the MessageValue's SelectKeys method
-					selectedKeys, err := selector.SelectKeys(keys)
-					if err != nil || len(selectedKeys) == 0 {
-						if err != nil && context.OnError != nil {
-							context.OnError(errors.NewMessageSelectionError(
-								errors.ErrorTypeBadSelector,
-								err,
-							))
-						}
-						return nil
-					}
-
-					// Return the first selected key
-					return &selectedKeys[0]
-				}
+				selectorCtxs[i].selector = selector
 			}
-		} else {
+		} else if context.OnError != nil {
 			// matches TypeScript: context.onError(new MessageSelectionError('bad-selector')); selectKey = () => null;
-			if context.OnError != nil {
-				context.OnError(errors.NewMessageSelectionError(
-					errors.ErrorTypeBadSelector,
-					nil,
-				))
-			}
-			selectKeyFunc = func([]string) *string { return nil }
-		}
-
-		// matches TypeScript: return { selectKey, best: null as string | null, keys: null as Set<string> | null };
-		selectorCtxs[i] = &selectorContext{
-			selectKey: selectKeyFunc,
+			context.OnError(errors.NewMessageSelectionError(
+				errors.ErrorTypeBadSelector,
+				nil,
+			))
 		}
+		context.ExitSpan(span)
 	}
 
 	// matches TypeScript: let candidates = message.variants;
-	candidates := variants
+	all := make([]int, len(t.variants))
+	for i := range all {
+		all[i] = i
+	}
+	candidates := all
 
 	// matches TypeScript: loop: for (let i = 0; i < ctx.length; ++i) {
 	for i := 0; i < len(selectorCtxs); i++ {
-		sc := selectorCtxs[i]
+		sc := &selectorCtxs[i]
 
 		// matches TypeScript: if (!sc.keys) { sc.keys = new Set(); ... }
 		if sc.keys == nil {
 			sc.keys = newOrderedKeySet()
 			// matches TypeScript: for (const { keys } of candidates) { const key = keys[i]; ... }
 			for _, variant := range candidates {
-				keys := variant.Keys()
+				keys := t.variants[variant]
 				// matches TypeScript: if (!key) break loop; // key-mismatch error
 				if i >= len(keys) {
 					goto loopEnd // equivalent to break loop in TypeScript
 				}
-				key := keys[i]
 				// matches TypeScript: if (key.type !== '*') sc.keys.add(key.value);
-				if !datamodel.IsCatchallKey(key) {
-					if literal, ok := key.(*datamodel.Literal); ok {
-						sc.keys.add(literal.Value())
-					}
+				if !keys[i].catchall && keys[i].literal {
+					sc.keys.add(keys[i].value)
 				}
 			}
 		}
 
 		// matches TypeScript: try { sc.best = sc.keys.size ? sc.selectKey(sc.keys) : null; } catch (error) { ... }
+		span := context.EnterSpan(t.selectors[i].GetPosition())
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
@@ -290,38 +347,35 @@ func selectVariantPattern(context *resolve.Context, msg *datamodel.SelectMessage
 						))
 					}
 					// matches TypeScript: sc.selectKey = () => null; sc.best = null;
-					sc.selectKey = func([]string) *string { return nil }
+					sc.selector = nil
 					sc.best = nil
 				}
 			}()
 
 			// matches TypeScript: sc.best = sc.keys.size ? sc.selectKey(sc.keys) : null;
 			if sc.keys.len() > 0 {
-				sc.best = sc.selectKey(sc.keys.values())
+				sc.best = sc.selectKey(context, sc.keys.values())
 			}
 		}()
+		context.ExitSpan(span)
 
 		// matches TypeScript: candidates = candidates.filter(v => { ... });
-		var newCandidates []datamodel.Variant
+		var newCandidates []int
 		for _, variant := range candidates {
-			keys := variant.Keys()
+			keys := t.variants[variant]
 			if i >= len(keys) {
 				continue
 			}
 
 			key := keys[i]
 			// matches TypeScript: if (k.type === '*') return sc.best == null;
-			if datamodel.IsCatchallKey(key) {
+			if key.catchall {
 				if sc.best == nil {
 					newCandidates = append(newCandidates, variant)
 				}
-			} else {
+			} else if key.literal && sc.best != nil && *sc.best == key.value {
 				// matches TypeScript: return sc.best === k.value;
-				if literal, ok := key.(*datamodel.Literal); ok {
-					if sc.best != nil && *sc.best == literal.Value() {
-						newCandidates = append(newCandidates, variant)
-					}
-				}
+				newCandidates = append(newCandidates, variant)
 			}
 		}
 
@@ -335,7 +389,7 @@ func selectVariantPattern(context *resolve.Context, msg *datamodel.SelectMessage
 			}
 
 			// matches TypeScript: const prev = ctx[i - 1]; if (prev.best == null) prev.keys?.clear(); else prev.keys?.delete(prev.best);
-			prev := selectorCtxs[i-1]
+			prev := &selectorCtxs[i-1]
 			if prev.best == nil {
 				prev.keys = nil // equivalent to clear()
 			} else {
@@ -348,7 +402,7 @@ func selectVariantPattern(context *resolve.Context, msg *datamodel.SelectMessage
 			}
 
 			// matches TypeScript: candidates = message.variants; i = -1;
-			candidates = variants
+			candidates = all
 			i = -1 // Will be incremented to 0 in next iteration
 		}
 	}
@@ -356,9 +410,8 @@ func selectVariantPattern(context *resolve.Context, msg *datamodel.SelectMessage
 loopEnd:
 	// matches TypeScript: const res = candidates[0];
 	if len(candidates) > 0 {
-		res := candidates[0]
 		// matches TypeScript: return res.value;
-		return res.Value()
+		return candidates[0], true
 	}
 
 	// matches TypeScript: if (!res) { context.onError(new MessageSelectionError('no-match')); return []; }
@@ -369,5 +422,5 @@ loopEnd:
 		))
 	}
 
-	return datamodel.Pattern{}
+	return 0, false
 }
@@ -3,13 +3,15 @@
 package messageformat
 
 import (
+	"context"
 	"errors"
+	"io"
 	"maps"
+	"reflect"
 	"slices"
 	"strings"
 
 	"github.com/kaptinlin/messageformat-go/internal/resolve"
-	"github.com/kaptinlin/messageformat-go/internal/selector"
 	"github.com/kaptinlin/messageformat-go/pkg/bidi"
 	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
 	"github.com/kaptinlin/messageformat-go/pkg/functions"
@@ -72,6 +74,20 @@ type MessageFormatOptions struct {
 	// Custom functions to make available during message resolution.
 	// Extends the default functions.
 	Functions map[string]functions.MessageFunction `json:"functions,omitempty"`
+
+	// Schemas of custom functions, by function name. Construction fails with
+	// bad-operand and bad-option errors when a call of one of these functions
+	// has a literal operand or option that its schema does not accept.
+	Schemas map[string]functions.Schema `json:"schemas,omitempty"`
+
+	// Check the built-in functions against their schemas at construction
+	// too. Otherwise their invalid options are reported while formatting, as
+	// the specification requires.
+	StrictOptions bool `json:"strictOptions,omitempty"`
+
+	// Adapters converting variable values of application types, by exact
+	// type, to the MessageValue that placeholders and functions see.
+	ValueAdapters map[reflect.Type]functions.ValueAdapter `json:"-"`
 }
 
 // NewOptions creates a new MessageFormatOptions with defaults
@@ -109,9 +125,13 @@ type MessageFormat struct {
 	message       datamodel.Message
 	locales       []string
 	functions     map[string]functions.MessageFunction
-	bidiIsolation bool   // true for "default", false for "none"
-	dir           string // "ltr" | "rtl" | "auto"
-	localeMatcher string // "best fit" | "lookup"
+	builtins      map[string]bool // names in functions bound to built-ins
+	bidiIsolation bool            // true for "default", false for "none"
+	dir           string          // "ltr" | "rtl" | "auto"
+	localeMatcher string          // "best fit" | "lookup"
+	locale        string          // locale negotiated from locales with localeMatcher
+	valueAdapters map[reflect.Type]functions.ValueAdapter
+	plan          *executionPlan
 }
 
 // Parse creates a MessageFormat by parsing source text and applying options.
@@ -150,80 +170,222 @@ func Compile(locales []string, message datamodel.Message, options ...Option) (*M
 
 	functionMap := make(map[string]functions.MessageFunction)
 	addDefaultFunctions(functionMap)
-	if opts.Functions != nil {
-		maps.Copy(functionMap, opts.Functions)
+	// Names keep built-in behavior until a custom function replaces them
+	builtins := make(map[string]bool, len(functionMap))
+	for name := range functionMap {
+		builtins[name] = true
+	}
+	for name, fn := range opts.Functions {
+		functionMap[name] = fn
+		delete(builtins, name)
+	}
+	if err := checkLiterals(message, compileSchemas(opts)); err != nil {
+		return nil, err
 	}
 
-	return &MessageFormat{
+	mf := &MessageFormat{
 		message:       message,
 		locales:       localeList,
 		functions:     functionMap,
+		builtins:      builtins,
 		bidiIsolation: bidiIsolation,
 		dir:           dir,
 		localeMatcher: localeMatcher,
-	}, nil
+		locale:        functions.NegotiateLocale(localeList, localeMatcher),
+		valueAdapters: maps.Clone(opts.ValueAdapters),
+	}
+	mf.plan = newExecutionPlan(mf, message)
+	return mf, nil
 }
 
 // Format returns rendered text and any recoverable runtime diagnostics.
 func (mf *MessageFormat) Format(values map[string]any) (string, error) {
-	var diagnostics []error
-	onError := func(err error) { diagnostics = append(diagnostics, err) }
-
-	ctx := mf.createContext(values, onError)
-	pattern := selector.SelectPattern(ctx, mf.message)
+	return mf.FormatContext(context.Background(), values)
+}
 
+// FormatContext is like Format but passes ctx to message functions through
+// MessageFunctionContext.Context. When ctx is done, resolution stops before
+// the next expression and FormatContext returns an empty string with an error
+// that matches ctx.Err(), joined with the diagnostics collected so far.
+func (mf *MessageFormat) FormatContext(ctx context.Context, values map[string]any) (string, error) {
 	var result strings.Builder
+	_, diagnostics, err := mf.formatTo(ctx, &result, values, nil)
+	if err != nil {
+		return "", errors.Join(append(diagnostics, err)...)
+	}
+	return result.String(), errors.Join(diagnostics...)
+}
+
+// FormatTo writes rendered text to w and returns the number of bytes written.
+// Recoverable runtime diagnostics are returned as with Format. A write error
+// stops formatting and is joined with the diagnostics collected so far.
+func (mf *MessageFormat) FormatTo(w io.Writer, values map[string]any) (int, error) {
+	sw, ok := w.(io.StringWriter)
+	if !ok {
+		sw = stringWriter{w}
+	}
+	n, diagnostics, err := mf.formatTo(context.Background(), sw, values, nil)
+	return n, errors.Join(append(diagnostics, err)...)
+}
 
-	for _, element := range pattern.Elements() {
-		switch elem := element.(type) {
-		case *datamodel.TextElement:
-			result.WriteString(elem.Value())
-		case *datamodel.Expression:
-			mv := resolve.ResolveExpression(ctx, elem)
+// AppendFormat appends rendered text to dst and returns the extended buffer.
+// Recoverable runtime diagnostics are returned as with Format.
+func (mf *MessageFormat) AppendFormat(dst []byte, values map[string]any) ([]byte, error) {
+	buf := byteAppender(dst)
+	_, diagnostics, _ := mf.formatTo(context.Background(), &buf, values, nil)
+	return buf, errors.Join(diagnostics...)
+}
+
+// formatTo resolves the selected pattern and writes its string projection to
+// w, recording spans when spans is not nil. It returns the recoverable
+// diagnostics separately from the error that stopped rendering, which is
+// either a write error or the context error.
+func (mf *MessageFormat) formatTo(
+	ctx context.Context,
+	w io.StringWriter,
+	values map[string]any,
+	spans *spanRecorder,
+) (int, []error, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	var diagnostics []error
+	rctx := mf.createContext(ctx, values, &diagnostics)
+	pattern := mf.plan.selectPattern(rctx)
+
+	n, err := mf.writePattern(w, rctx, pattern, spans)
+	return n, diagnostics, err
+}
+
+// writePattern writes text elements, bidi isolation marks, and resolved
+// expression output of pattern to w, and records the spans of expressions and
+// markup. It stops at the first write error or when the formatting context is
+// done.
+func (mf *MessageFormat) writePattern(
+	w io.StringWriter,
+	ctx *resolve.Context,
+	pattern planPattern,
+	spans *spanRecorder,
+) (int, error) {
+	cw := countingWriter{w: w}
+	for _, elem := range pattern {
+		switch {
+		case elem.expr != nil:
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return cw.n, ctxErr
+			}
+			// Formatting errors are located at the expression
+			span := ctx.EnterSpan(elem.expr.Expression().GetPosition())
+			mv := resolve.ResolveCompiled(ctx, elem.expr)
 			if mv == nil {
-				result.WriteString("{}")
-				continue
+				ctx.ExitSpan(span)
+				start := cw.n
+				cw.write("{}")
+				spans.expression(nil, start, cw.n)
+				break
 			}
 
-			formatted, err := mv.ToString()
-			if err != nil {
-				ctx.OnError(err)
+			formatted, fmtErr := resolve.ToString(mv)
+			if fmtErr != nil {
+				ctx.OnError(fmtErr)
+			}
+			ctx.ExitSpan(span)
+			var isolationStart, isolationEnd string
+			switch {
+			case fmtErr != nil:
 				formatted = "{" + mv.Source() + "}"
 				if mf.bidiIsolation {
-					result.WriteString("\u2068")
-					result.WriteString(formatted)
-					result.WriteString("\u2069")
-				} else {
-					result.WriteString(formatted)
+					isolationStart, isolationEnd = "\u2068", "\u2069"
 				}
-				continue
+			case mf.shouldApplyBidiIsolation(mv):
+				isolationStart, isolationEnd = mf.getBidiIsolationStart(mv.Dir()), "\u2069"
 			}
+			cw.write(isolationStart)
+			start := cw.n
+			cw.write(formatted)
+			spans.expression(mv, start, cw.n)
+			cw.write(isolationEnd)
+		case elem.markup != nil:
+			spans.markup(resolve.FormatMarkup(ctx, elem.markup), cw.n)
+		default:
+			cw.write(elem.text)
+		}
+		if cw.err != nil {
+			return cw.n, cw.err
+		}
+	}
 
-			if mf.shouldApplyBidiIsolation(mv) {
-				result.WriteString(mf.getBidiIsolationStart(mv.Dir()))
-				result.WriteString(formatted)
-				result.WriteString("\u2069")
-			} else {
-				result.WriteString(formatted)
-			}
-		case *datamodel.Markup:
-			resolve.FormatMarkup(ctx, elem)
+	return cw.n, nil
+}
+
+// countingWriter writes strings to w until the first error and counts the
+// bytes written.
+type countingWriter struct {
+	w   io.StringWriter
+	n   int
+	err error
+}
+
+// write writes strs in order, skipping empty strings and stopping at the
+// first error.
+func (cw *countingWriter) write(strs ...string) {
+	for _, str := range strs {
+		if cw.err != nil {
+			return
+		}
+		if str == "" {
+			continue
 		}
+		n, err := cw.w.WriteString(str)
+		cw.n += n
+		cw.err = err
 	}
+}
 
-	return result.String(), errors.Join(diagnostics...)
+// stringWriter adapts an io.Writer without a WriteString method.
+type stringWriter struct {
+	w io.Writer
+}
+
+// WriteString writes s to the wrapped writer.
+func (sw stringWriter) WriteString(s string) (int, error) {
+	return io.WriteString(sw.w, s)
+}
+
+// byteAppender is an io.StringWriter that appends to a byte slice.
+type byteAppender []byte
+
+// WriteString appends s and never fails.
+func (b *byteAppender) WriteString(s string) (int, error) {
+	*b = append(*b, s...)
+	return len(s), nil
 }
 
 // FormatToParts returns structured parts and any recoverable runtime diagnostics.
 func (mf *MessageFormat) FormatToParts(values map[string]any) ([]messagevalue.MessagePart, error) {
-	var diagnostics []error
-	onError := func(err error) { diagnostics = append(diagnostics, err) }
+	return mf.FormatToPartsContext(context.Background(), values)
+}
+
+// FormatToPartsContext is like FormatToParts but passes ctx to message
+// functions through MessageFunctionContext.Context. When ctx is done,
+// resolution stops before the next expression and FormatToPartsContext returns
+// nil parts with an error that matches ctx.Err(), joined with the diagnostics
+// collected so far.
+func (mf *MessageFormat) FormatToPartsContext(
+	ctx context.Context,
+	values map[string]any,
+) ([]messagevalue.MessagePart, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	ctx := mf.createContext(values, onError)
-	pattern := selector.SelectPattern(ctx, mf.message)
-	parts, err := mf.formatPattern(ctx, pattern)
+	var diagnostics []error
+	rctx := mf.createContext(ctx, values, &diagnostics)
+	pattern := mf.plan.selectPattern(rctx)
+	parts, err := mf.formatPattern(rctx, pattern)
 	if err != nil {
-		diagnostics = append(diagnostics, err)
+		return nil, errors.Join(append(diagnostics, err)...)
 	}
 	return parts, errors.Join(diagnostics...)
 }
@@ -250,46 +412,57 @@ func (mf *MessageFormat) FormatToParts(values map[string]any) ([]messagevalue.Me
 //	  return ctx;
 //	}
 func (mf *MessageFormat) createContext(
+	ctx context.Context,
 	values map[string]any,
-	onError func(error),
+	diagnostics *[]error,
 ) *resolve.Context {
-	scope := make(map[string]any)
-	maps.Copy(scope, values)
-
-	for _, decl := range mf.message.Declarations() {
-		switch d := decl.(type) {
-		case *datamodel.InputDeclaration:
-			if expression := d.Value(); expression != nil {
-				scope[d.Name()] = resolve.NewUnresolvedExpression(expression, values)
-			}
-		case *datamodel.LocalDeclaration:
-			if localExpr := d.Value(); localExpr != nil {
-				scope[d.Name()] = resolve.NewUnresolvedExpression(localExpr, nil)
-			}
-		}
+	rctx := &resolve.Context{
+		Functions:     mf.functions,
+		LocaleMatcher: mf.localeMatcher,
+		Locales:       mf.locales,
+		Scope:         mf.plan.scope(values),
+		Context:       ctx,
+		ValueAdapters: mf.valueAdapters,
+
+		// Negotiated once in Compile
+		NegotiatedLocale: mf.locale,
 	}
-
-	return resolve.NewContext(mf.locales, mf.functions, scope, onError, mf.localeMatcher)
+	if len(mf.plan.declarations) > 0 {
+		// Shared with cloned contexts for circular reference detection
+		rctx.ResolvingVars = make(map[string]bool)
+	}
+	if diagnostics == nil {
+		rctx.OnError = func(error) {}
+		return rctx
+	}
+	// Runtime errors point back into the message source
+	rctx.TrackSpans()
+	rctx.OnError = func(err error) { *diagnostics = append(*diagnostics, rctx.LocateError(err)) }
+	return rctx
 }
 
-// formatPattern formats a pattern into message parts with bidi isolation
+// formatPattern formats a pattern into message parts with bidi isolation.
+// It stops with the context error when the formatting context is done.
 // TypeScript original code: pattern formatting logic
 func (mf *MessageFormat) formatPattern(
 	ctx *resolve.Context,
-	pattern datamodel.Pattern,
+	pattern planPattern,
 ) ([]messagevalue.MessagePart, error) {
 	var parts []messagevalue.MessagePart
 
-	for _, element := range pattern.Elements() {
-		switch elem := element.(type) {
-		case *datamodel.TextElement:
-			parts = append(parts, messagevalue.NewTextPart(elem.Value(), elem.Value(), ""))
-
-		case *datamodel.Expression:
-			mv := resolve.ResolveExpression(ctx, elem)
+	for _, elem := range pattern {
+		switch {
+		case elem.expr != nil:
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// Formatting errors are located at the expression
+			span := ctx.EnterSpan(elem.expr.Expression().GetPosition())
+			mv := resolve.ResolveCompiled(ctx, elem.expr)
 
 			if mv == nil {
-				parts = append(parts, messagevalue.NewFallbackPart("", functions.GetFirstLocale(ctx.Locales)))
+				ctx.ExitSpan(span)
+				parts = append(parts, messagevalue.NewFallbackPart("", ctx.Locale()))
 				continue
 			}
 
@@ -299,22 +472,26 @@ func (mf *MessageFormat) formatPattern(
 				parts = append(parts, messagevalue.NewBidiIsolationPart(isolationStart))
 			}
 
-			valueParts, err := mv.ToParts()
+			valueParts, err := resolve.ToParts(mv)
 			if err != nil {
 				ctx.OnError(err)
 				valueParts = []messagevalue.MessagePart{
-					messagevalue.NewFallbackPart(mv.Source(), functions.GetFirstLocale(ctx.Locales)),
+					messagevalue.NewFallbackPart(mv.Source(), ctx.Locale()),
 				}
 			}
+			ctx.ExitSpan(span)
 			parts = append(parts, valueParts...)
 
 			if applyBidiIsolation {
 				parts = append(parts, messagevalue.NewBidiIsolationPart("\u2069")) // PDI
 			}
 
-		case *datamodel.Markup:
-			markupPart := resolve.FormatMarkup(ctx, elem)
+		case elem.markup != nil:
+			markupPart := resolve.FormatMarkup(ctx, elem.markup)
 			parts = append(parts, markupPart)
+
+		default:
+			parts = append(parts, messagevalue.NewTextPart(elem.text, elem.text, ""))
 		}
 	}
 
@@ -2,6 +2,7 @@ package messageformat
 
 import (
 	"bytes"
+	"context"
 	stdErrors "errors"
 	"fmt"
 	"log/slog"
@@ -525,6 +526,68 @@ func TestFormatDiagnosticPreservesFunctionCause(t *testing.T) {
 	require.ErrorAs(t, err, &functionErr)
 }
 
+// TestFormatDiagnosticsCarrySourceSpans proves runtime errors point back into the message source.
+func TestFormatDiagnosticsCarrySourceSpans(t *testing.T) {
+	t.Parallel()
+
+	report := func(ctx functions.MessageFunctionContext, _ functions.Options, _ any) messagevalue.MessageValue {
+		ctx.OnError(pkgerrors.NewMessageFunctionError(pkgerrors.ErrorTypeBadOperand, "reported failure"))
+		return messagevalue.NewStringValue("usable", "en", ctx.Source())
+	}
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "pattern expressions",
+			source: "Hi {$x :number} and {$y}",
+			want:   []string{"{$x :number}", "{$x :number}", "{$y}"},
+		},
+		{
+			name:   "local declaration",
+			source: ".local $y = {$x :number} {{a {$y} b}}",
+			want:   []string{"{$x :number}", "{$x :number}"},
+		},
+		{
+			name:   "selector",
+			source: ".input {$n :number}\n.match $n\none {{one}}\n* {{other}}",
+			want:   []string{"{$n :number}", "{$n :number}", "$n"},
+		},
+		{
+			name:   "markup",
+			source: "{#b u:dir=rtl}x{/b}",
+			want:   []string{"{#b u:dir=rtl}"},
+		},
+		{
+			name:   "function report",
+			source: "é {:report}",
+			want:   []string{"{:report}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mf, err := Parse([]string{"en"}, tt.source, WithFunction("report", report))
+			require.NoError(t, err)
+
+			_, formatErr := mf.Format(nil)
+			_, partsErr := mf.FormatToParts(nil)
+			for _, err := range []error{formatErr, partsErr} {
+				diagnostics := pkgerrors.Diagnose(tt.source, err)
+				spans := make([]string, 0, len(diagnostics))
+				for _, d := range diagnostics {
+					require.GreaterOrEqual(t, d.Start, 0, d.String())
+					spans = append(spans, tt.source[d.Start:d.End])
+				}
+				assert.Equal(t, tt.want, spans)
+			}
+		})
+	}
+}
+
 // TestInvalidNumberOptionsReturnOneFallbackWithoutSemanticRetry proves required number semantics are never erased.
 // TypeScript original code:
 // const formatter = new Intl.NumberFormat(locales, options);
@@ -788,6 +851,253 @@ func TestFormat(t *testing.T) {
 }
 
 // TestFormatToPartsAPI tests the FormatToParts API method
+type failingWriter struct {
+	limit int
+	buf   bytes.Buffer
+}
+
+var errTestWrite = stdErrors.New("test write failure")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errTestWrite
+	}
+	return w.buf.Write(p)
+}
+
+func TestFormatToMatchesFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		values map[string]any
+	}{
+		{name: "text", source: "Hello World"},
+		{name: "isolated variable", source: "Hello {$name}!", values: map[string]any{"name": "Alice"}},
+		{name: "fallback", source: "Hello {$missing}"},
+		{name: "markup", source: "{#b}bold{/b} text"},
+		{name: "select", source: ".input {$n :integer}\n.match $n\none {{one {$n}}}\n* {{many {$n}}}", values: map[string]any{"n": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, err := Parse([]string{"en"}, tt.source)
+			require.NoError(t, err)
+
+			want, wantErr := mf.Format(tt.values)
+
+			var buf bytes.Buffer
+			n, err := mf.FormatTo(&buf, tt.values)
+			assert.Equal(t, want, buf.String())
+			assert.Equal(t, len(want), n)
+			assert.Equal(t, wantErr, err)
+
+			var sb strings.Builder
+			_, err = mf.FormatTo(&sb, tt.values)
+			assert.Equal(t, want, sb.String())
+			assert.Equal(t, wantErr, err)
+
+			prefix := []byte("> ")
+			out, err := mf.AppendFormat(prefix, tt.values)
+			assert.Equal(t, "> "+want, string(out))
+			assert.Equal(t, wantErr, err)
+		})
+	}
+}
+
+func TestFormatToWriteError(t *testing.T) {
+	mf, err := Parse([]string{"en"}, "Hello {$missing} and more text", WithBidiIsolation(BidiNone))
+	require.NoError(t, err)
+
+	w := &failingWriter{limit: 8}
+	n, err := mf.FormatTo(w, nil)
+	require.ErrorIs(t, err, errTestWrite)
+	assert.Equal(t, "Hello ", w.buf.String())
+	assert.Equal(t, len("Hello "), n)
+
+	var resolutionErr *pkgerrors.MessageResolutionError
+	require.ErrorAs(t, err, &resolutionErr)
+}
+
+type testContextKey struct{}
+
+func TestFormatContextPassesContextToFunctions(t *testing.T) {
+	lookup := func(ctx MessageFunctionContext, _ functions.Options, operand any) messagevalue.MessageValue {
+		names, _ := ctx.Context().Value(testContextKey{}).(map[string]string)
+		return messagevalue.NewStringValue(names[fmt.Sprint(operand)], "en", ctx.Source())
+	}
+	mf, err := Parse([]string{"en"}, "Hello {$id :user}", WithFunction("user", lookup), WithBidiIsolation(BidiNone))
+	require.NoError(t, err)
+
+	ctx := context.WithValue(t.Context(), testContextKey{}, map[string]string{"42": "Alice"})
+
+	result, err := mf.FormatContext(ctx, map[string]any{"id": "42"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Alice", result)
+
+	parts, err := mf.FormatToPartsContext(ctx, map[string]any{"id": "42"})
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "Alice", parts[1].Value())
+}
+
+func TestFormatContextCancellation(t *testing.T) {
+	calls := 0
+	var cancel context.CancelFunc
+	slow := func(ctx MessageFunctionContext, _ functions.Options, operand any) messagevalue.MessageValue {
+		calls++
+		cancel()
+		return messagevalue.NewStringValue(fmt.Sprint(operand), "en", ctx.Source())
+	}
+	mf, err := Parse([]string{"en"}, "{$a :slow} {$b :slow} {$c :slow}", WithFunction("slow", slow))
+	require.NoError(t, err)
+	values := map[string]any{"a": 1, "b": 2, "c": 3}
+
+	t.Run("cancelled during resolution", func(t *testing.T) {
+		calls = 0
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(t.Context())
+
+		result, err := mf.FormatContext(ctx, values)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, result)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("cancelled during parts resolution", func(t *testing.T) {
+		calls = 0
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(t.Context())
+
+		parts, err := mf.FormatToPartsContext(ctx, values)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, parts)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("cancelled before formatting", func(t *testing.T) {
+		calls = 0
+		ctx, cancelNow := context.WithCancel(t.Context())
+		cancelNow()
+
+		result, err := mf.FormatContext(ctx, values)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, result)
+
+		parts, err := mf.FormatToPartsContext(ctx, values)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, parts)
+		assert.Zero(t, calls)
+	})
+}
+
+func TestFormatUsesBackgroundContext(t *testing.T) {
+	var got context.Context
+	capture := func(ctx MessageFunctionContext, _ functions.Options, _ any) messagevalue.MessageValue {
+		got = ctx.Context()
+		return messagevalue.NewStringValue("x", "en", ctx.Source())
+	}
+	mf, err := Parse([]string{"en"}, "{:capture}", WithFunction("capture", capture))
+	require.NoError(t, err)
+
+	_, err = mf.Format(nil)
+	require.NoError(t, err)
+	assert.Equal(t, context.Background(), got)
+}
+
+func TestExecutionPlanIsReusedAcrossCalls(t *testing.T) {
+	calls := 0
+	count := func(ctx MessageFunctionContext, _ functions.Options, input any) messagevalue.MessageValue {
+		calls++
+		return messagevalue.NewStringValue(fmt.Sprint(input), "en", ctx.Source())
+	}
+	mf, err := Parse([]string{"en"}, `.input {$n :integer}
+.local $label = {|items| :count}
+.match $n
+one {{{$n} {$label} only}}
+* {{{$n} {$label}}}`, WithFunction("count", count), WithBidiIsolation(BidiNone))
+	require.NoError(t, err)
+
+	tests := []struct {
+		values map[string]any
+		want   string
+	}{
+		{values: map[string]any{"n": 1}, want: "1 items only"},
+		{values: map[string]any{"n": 5}, want: "5 items"},
+		{values: map[string]any{"n": 1}, want: "1 items only"},
+	}
+	for _, tt := range tests {
+		got, err := mf.Format(tt.values)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+	// Custom functions are never folded into constants
+	assert.Equal(t, len(tests), calls)
+
+	_, err = mf.Format(nil)
+	require.Error(t, err)
+}
+
+func TestExecutionPlanConcurrentFormat(t *testing.T) {
+	mf, err := Parse([]string{"en"}, `.local $greeting = {|Hello|}
+.input {$count :number}
+.match $count
+one {{{$greeting} {$name}, one item}}
+* {{{$greeting} {$name}, {$count} items}}`, WithBidiIsolation(BidiNone))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			name := fmt.Sprintf("user%d", i)
+			got, err := mf.Format(map[string]any{"name": name, "count": 1})
+			assert.NoError(t, err)
+			assert.Equal(t, "Hello "+name+", one item", got)
+		})
+	}
+	wg.Wait()
+}
+
+func TestLocaleNegotiation(t *testing.T) {
+	tests := []struct {
+		name    string
+		locales []string
+		matcher LocaleMatcher
+		source  string
+		want    string
+		errors  bool
+	}{
+		{name: "unknown locale falls back", locales: []string{"xx-YY", "fr"}, source: "{42 :number}", want: "fr"},
+		{name: "unknown locale falls back with lookup", locales: []string{"xx-YY", "fr"}, matcher: LocaleLookup, source: "{42 :integer}", want: "fr"},
+		{name: "percent", locales: []string{"xx", "de-CH"}, source: "{0.5 :percent}", want: "de-CH"},
+		{name: "datetime", locales: []string{"xx-YY", "fr"}, source: "{|2024-01-02| :datetime}", want: "fr"},
+		{name: "lookup truncates", locales: []string{"zh-TW"}, matcher: LocaleLookup, source: "{1 :number}", want: "zh"},
+		{name: "best fit matches by language distance", locales: []string{"zh-TW"}, matcher: LocaleBestFit, source: "{1 :number}", want: "zh-Hant-TW"},
+		{name: "nothing matches", locales: []string{"xx"}, source: "{1 :number}", want: "en"},
+		{name: "fallback part", locales: []string{"xx-YY", "fr"}, source: "{$missing}", want: "fr", errors: true},
+		{name: "fallback value", locales: []string{"xx-YY", "fr"}, source: "{|x| :nope}", want: "fr", errors: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := []Option{WithBidiIsolation(BidiNone)}
+			if tt.matcher != "" {
+				options = append(options, WithLocaleMatcher(tt.matcher))
+			}
+			mf, err := Parse(tt.locales, tt.source, append(options, WithFunctions(DraftFunctionMap()))...)
+			require.NoError(t, err)
+
+			parts, err := mf.FormatToParts(nil)
+			if tt.errors {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, parts, 1)
+			assert.Equal(t, tt.want, parts[0].Locale())
+		})
+	}
+}
+
 func TestFormatToPartsAPI(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1370,6 +1680,29 @@ func TestLocalDeclarations(t *testing.T) {
 	assert.Contains(t, result, "World")
 }
 
+func TestFormatStructValues(t *testing.T) {
+	type address struct {
+		City string `mf:"city"`
+	}
+	type account struct {
+		Name    string `mf:"name"`
+		Address *address
+	}
+
+	mf, err := Parse([]string{"en"}, "{$user.name} lives in {$user.address.city}", WithBidiIsolation(BidiNone))
+	require.NoError(t, err)
+
+	result, err := mf.Format(map[string]any{
+		"user": &account{Name: "Alice", Address: &address{City: "Berlin"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice lives in Berlin", result)
+
+	result, err = mf.Format(map[string]any{"user": account{Name: "Bob"}})
+	require.Error(t, err)
+	assert.Equal(t, "Bob lives in {$user.address.city}", result)
+}
+
 // Error Handling API Tests
 
 // TestFormatErrorReturn tests recoverable diagnostics through the error result.
@@ -0,0 +1,93 @@
+package messageformat
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// ErrMissingNodeHandler indicates NodeHandlers without a Part function.
+var ErrMissingNodeHandler = errors.New("node handlers have no part function")
+
+// MarkupHandler builds the node of markup: the open or standalone markup
+// part, with the options resolved for it, and the nodes built for the parts
+// up to its close. Children are nil for standalone markup.
+type MarkupHandler[T any] func(markup *messagevalue.MarkupPart, children []T) T
+
+// NodeHandlers are the functions that FormatToNodes builds nodes with.
+type NodeHandlers[T any] struct {
+	// Markup holds the handlers of markup, keyed by markup name.
+	Markup map[string]MarkupHandler[T]
+
+	// DefaultMarkup builds the node of markup that Markup has no handler
+	// for. When it is nil, such markup is dropped and its children take its
+	// place.
+	DefaultMarkup MarkupHandler[T]
+
+	// Part builds the node of any part that is not markup: text, string,
+	// number, date/time, unknown, and fallback parts. It is required.
+	Part func(part messagevalue.MessagePart) T
+
+	// BidiIsolation passes the bidi isolation parts around isolated values
+	// to Part. By default they are dropped.
+	BidiIsolation bool
+}
+
+// FormatToNodes formats mf with values and builds the result as nodes of any
+// type T, such as UI components, Markdown AST nodes, or terminal spans.
+// Markup pairs as in messagevalue.PartsTree: each element is built by the
+// handler of its name from the nodes of its children, and every other part
+// by handlers.Part.
+//
+// The error joins the formatting diagnostics and any markup that does not
+// pair up, which leave the nodes usable, like those of FormatToParts. It
+// matches ErrMissingNodeHandler, with nil nodes, when handlers.Part is nil.
+func FormatToNodes[T any](mf *MessageFormat, values map[string]any, handlers NodeHandlers[T]) ([]T, error) {
+	if handlers.Part == nil {
+		return nil, ErrMissingNodeHandler
+	}
+	parts, err := mf.FormatToPartsContext(context.Background(), values)
+	if parts == nil {
+		return nil, err
+	}
+	if !handlers.BidiIsolation {
+		parts = withoutBidiIsolation(parts)
+	}
+	tree, treeErr := messagevalue.PartsTree(parts)
+	return handlers.build(tree), errors.Join(err, treeErr)
+}
+
+// build builds the nodes of tree.
+func (h NodeHandlers[T]) build(tree []*messagevalue.PartNode) []T {
+	var nodes []T
+	for _, node := range tree {
+		markup := node.Markup()
+		if markup == nil {
+			nodes = append(nodes, h.Part(node.Part))
+			continue
+		}
+		children := h.build(node.Children)
+		handler := h.Markup[markup.Name()]
+		if handler == nil {
+			handler = h.DefaultMarkup
+		}
+		if handler == nil {
+			nodes = append(nodes, children...)
+			continue
+		}
+		nodes = append(nodes, handler(markup, children))
+	}
+	return nodes
+}
+
+// withoutBidiIsolation returns parts without bidi isolation parts.
+func withoutBidiIsolation(parts []messagevalue.MessagePart) []messagevalue.MessagePart {
+	kept := make([]messagevalue.MessagePart, 0, len(parts))
+	for _, part := range parts {
+		if _, ok := part.(*messagevalue.BidiIsolationPart); !ok {
+			kept = append(kept, part)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,126 @@
+package messageformat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// markdownHandlers build Markdown source as nodes.
+func markdownHandlers() NodeHandlers[string] {
+	return NodeHandlers[string]{
+		Markup: map[string]MarkupHandler[string]{
+			"b": func(_ *messagevalue.MarkupPart, children []string) string {
+				return "**" + strings.Join(children, "") + "**"
+			},
+			"link": func(markup *messagevalue.MarkupPart, children []string) string {
+				return "[" + strings.Join(children, "") + "](" + fmt.Sprint(markup.Options()["href"]) + ")"
+			},
+			"br": func(_ *messagevalue.MarkupPart, children []string) string {
+				if children != nil {
+					return "<children>"
+				}
+				return "\n"
+			},
+		},
+		Part: func(part messagevalue.MessagePart) string {
+			if text, ok := part.(interface{ Text() string }); ok {
+				return text.Text()
+			}
+			return fmt.Sprint(part.Value())
+		},
+	}
+}
+
+func TestFormatToNodes(t *testing.T) {
+	t.Parallel()
+
+	defaultMarkup := markdownHandlers()
+	defaultMarkup.DefaultMarkup = func(markup *messagevalue.MarkupPart, children []string) string {
+		return "<" + markup.Name() + ">" + strings.Join(children, "") + "</" + markup.Name() + ">"
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		handlers NodeHandlers[string]
+		expected []string
+	}{
+		{
+			name:     "text and values",
+			source:   "Hello, {$name}!",
+			handlers: markdownHandlers(),
+			expected: []string{"Hello, ", "Ada", "!"},
+		},
+		{
+			name:     "nested markup",
+			source:   "{#b}Hi {#link href=$url}{$name}{/link}{/b}{#br/}",
+			handlers: markdownHandlers(),
+			expected: []string{"**Hi [Ada](https://example.com)**", "\n"},
+		},
+		{
+			name:     "unknown markup keeps its children",
+			source:   "{#tag}x{/tag} y",
+			handlers: markdownHandlers(),
+			expected: []string{"x", " y"},
+		},
+		{
+			name:     "default markup",
+			source:   "{#tag}x{/tag} y",
+			handlers: defaultMarkup,
+			expected: []string{"<tag>x</tag>", " y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mf, err := Parse([]string{"en"}, tt.source, WithBidiIsolation(BidiNone))
+			require.NoError(t, err)
+
+			nodes, err := FormatToNodes(mf, map[string]any{"name": "Ada", "url": "https://example.com"}, tt.handlers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, nodes)
+		})
+	}
+}
+
+func TestFormatToNodesBidiIsolation(t *testing.T) {
+	t.Parallel()
+
+	mf, err := Parse([]string{"en"}, "Hi {$name}", WithBidiIsolation(BidiDefault))
+	require.NoError(t, err)
+	values := map[string]any{"name": "Ada"}
+
+	handlers := markdownHandlers()
+	nodes, err := FormatToNodes(mf, values, handlers)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hi ", "Ada"}, nodes)
+
+	handlers.BidiIsolation = true
+	nodes, err = FormatToNodes(mf, values, handlers)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hi ", "\u2068", "Ada", "\u2069"}, nodes)
+}
+
+func TestFormatToNodesErrors(t *testing.T) {
+	t.Parallel()
+
+	mf, err := Parse([]string{"en"}, "{#b}{$missing} {#link}x", WithBidiIsolation(BidiNone))
+	require.NoError(t, err)
+
+	nodes, err := FormatToNodes(mf, nil, markdownHandlers())
+	require.ErrorIs(t, err, messagevalue.ErrUnclosedMarkup)
+	assert.Contains(t, err.Error(), "$missing")
+	assert.Equal(t, []string{"**{$missing} [x](<nil>)**"}, nodes)
+
+	nodes, err = FormatToNodes(mf, nil, NodeHandlers[string]{})
+	require.ErrorIs(t, err, ErrMissingNodeHandler)
+	assert.Nil(t, nodes)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"reflect"
 
 	"github.com/kaptinlin/messageformat-go/pkg/functions"
 )
@@ -52,6 +53,12 @@ func validateMessageFormatOptions(options *MessageFormatOptions) error {
 	default:
 		return fmt.Errorf("%w: localeMatcher %q", ErrInvalidOption, options.LocaleMatcher)
 	}
+	// Values are matched by their dynamic type, which is never an interface
+	for t, adapter := range options.ValueAdapters {
+		if t == nil || t.Kind() == reflect.Interface || adapter == nil {
+			return fmt.Errorf("%w: value adapter for %v", ErrInvalidOption, t)
+		}
+	}
 
 	return nil
 }
@@ -102,6 +109,41 @@ func WithFunction(name string, fn functions.MessageFunction) Option {
 	}
 }
 
+// WithFunctionSchema adds a custom function with the schema of its operand
+// and options. Construction checks the literal values of its calls against
+// the schema.
+func WithFunctionSchema(name string, fn functions.MessageFunction, schema functions.Schema) Option {
+	return func(opts *MessageFormatOptions) {
+		WithFunction(name, fn)(opts)
+		if opts.Schemas == nil {
+			opts.Schemas = make(map[string]functions.Schema)
+		}
+		opts.Schemas[name] = schema
+	}
+}
+
+// WithValueAdapter converts variable values of type t with adapter before
+// they are formatted or passed to functions. Types that need no registration
+// can implement functions.MessageValuer instead. t must be a concrete type.
+func WithValueAdapter(t reflect.Type, adapter functions.ValueAdapter) Option {
+	return func(opts *MessageFormatOptions) {
+		if opts.ValueAdapters == nil {
+			opts.ValueAdapters = make(map[reflect.Type]functions.ValueAdapter)
+		}
+		opts.ValueAdapters[t] = adapter
+	}
+}
+
+// WithStrictOptions checks the literal operands and options of the built-in
+// functions against functions.DefaultSchemaMap and functions.DraftSchemaMap
+// at construction, so that a misspelled or invalid option is an error
+// instead of a format-time diagnostic.
+func WithStrictOptions() Option {
+	return func(opts *MessageFormatOptions) {
+		opts.StrictOptions = true
+	}
+}
+
 // WithFunctions adds multiple custom functions
 // TypeScript original code:
 // functions?: Record<string, MessageFunction<T, P>>;
@@ -0,0 +1,122 @@
+package messageformat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgerrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// panickingValue is a message value whose methods panic.
+type panickingValue struct {
+	*messagevalue.StringValue
+}
+
+func (panickingValue) ToString() (string, error) {
+	panic("ToString")
+}
+
+func (panickingValue) ToParts() ([]messagevalue.MessagePart, error) {
+	panic("ToParts")
+}
+
+func (panickingValue) SelectKeys([]string) ([]string, error) {
+	panic("SelectKeys")
+}
+
+func TestFunctionPanicsAreRecovered(t *testing.T) {
+	t.Parallel()
+
+	panicking := func(ctx functions.MessageFunctionContext, _ functions.Options, _ any) messagevalue.MessageValue {
+		panic("call")
+	}
+	panickingResult := func(ctx functions.MessageFunctionContext, _ functions.Options, _ any) messagevalue.MessageValue {
+		return panickingValue{messagevalue.NewStringValue("", ctx.Locales()[0], ctx.Source())}
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		panic    string
+	}{
+		{"call", "a {$x :panic} b", "a {$x} b", "call"},
+		{"ToString", "a {$x :result} b", "a {$x} b", "ToString"},
+		{"SelectKeys", ".local $y = {$x :result}\n.match $y\nkey {{key}}\n* {{other}}", "other", "SelectKeys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, err := Parse(
+				[]string{"en"},
+				tt.source,
+				WithBidiIsolation(BidiNone),
+				WithFunction("panic", panicking),
+				WithFunction("result", panickingResult),
+			)
+			require.NoError(t, err)
+
+			var formatted string
+			require.NotPanics(t, func() {
+				formatted, err = mf.Format(map[string]any{"x": "value"})
+			})
+			assert.Equal(t, tt.expected, formatted)
+			assertPanicError(t, err, tt.panic)
+		})
+	}
+
+	t.Run("ToParts", func(t *testing.T) {
+		mf, err := Parse([]string{"en"}, "{$x :result}", WithBidiIsolation(BidiNone), WithFunction("result", panickingResult))
+		require.NoError(t, err)
+
+		parts, err := mf.FormatToParts(map[string]any{"x": "value"})
+		require.Len(t, parts, 1)
+		assert.Equal(t, "fallback", parts[0].Type())
+		assert.Equal(t, "$x", parts[0].Source())
+		assertPanicError(t, err, "ToParts")
+	})
+
+	t.Run("implicit string function", func(t *testing.T) {
+		mf, err := Parse([]string{"en"}, "a {$x} {|literal|} b", WithBidiIsolation(BidiNone), WithFunction("string", panicking))
+		require.NoError(t, err)
+
+		formatted, err := mf.Format(map[string]any{"x": "value"})
+		assert.Equal(t, "a {$x} {|literal|} b", formatted)
+		assertPanicError(t, err, "call")
+	})
+
+	t.Run("value adapter", func(t *testing.T) {
+		mf, err := Parse(
+			[]string{"en"},
+			"{$price}",
+			WithBidiIsolation(BidiNone),
+			WithValueAdapter(reflect.TypeFor[adapterTestMoney](), func(functions.MessageFunctionContext, any) messagevalue.MessageValue {
+				panic("adapter")
+			}),
+		)
+		require.NoError(t, err)
+
+		formatted, err := mf.Format(map[string]any{"price": adapterTestMoney{}})
+		assert.Equal(t, "{$price}", formatted)
+		assertPanicError(t, err, "adapter")
+	})
+}
+
+func assertPanicError(t *testing.T, err error, value any) {
+	t.Helper()
+
+	var resolutionErr *pkgerrors.MessageResolutionError
+	require.ErrorAs(t, err, &resolutionErr)
+	assert.Equal(t, pkgerrors.ErrorTypeBadFunctionResult, resolutionErr.Type)
+
+	var panicErr *pkgerrors.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, value, panicErr.Value)
+	assert.True(t, strings.Contains(string(panicErr.Stack), "panic_test.go"), "the stack is the stack of the panic")
+}
@@ -0,0 +1,331 @@
+// Package catalog provides keyed MessageFormat 2.0 message bundles per locale
+// with locale fallback chains.
+//
+// A Catalog is the MF2 counterpart of mf1.Messages: it stores compiled
+// *messageformat.MessageFormat values by message ID for each locale and
+// resolves lookups through a fallback chain such as de-CH -> de -> en.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	messageformat "github.com/kaptinlin/messageformat-go"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// Static errors to avoid dynamic error creation
+var (
+	// ErrMessageNotFound indicates that no locale in the fallback chain has the message.
+	ErrMessageNotFound = errors.New("message not found")
+
+	// ErrInvalidLocale indicates an empty catalog locale.
+	ErrInvalidLocale = errors.New("invalid catalog locale")
+
+	// ErrInvalidMessageID indicates an empty message ID.
+	ErrInvalidMessageID = errors.New("invalid message id")
+
+	// ErrNilMessage indicates that a nil formatter was added to the catalog.
+	ErrNilMessage = errors.New("nil message")
+)
+
+// Catalog holds compiled messages keyed by locale and message ID.
+// A Catalog is safe for concurrent use; formatting does not block other readers.
+type Catalog struct {
+	mu            sync.RWMutex
+	bundles       map[string]map[string]*messageformat.MessageFormat
+	fallbacks     map[string][]string
+	defaultLocale string
+	options       []messageformat.Option
+}
+
+// New creates an empty catalog. defaultLocale is the last entry of every
+// fallback chain; options are applied to every message compiled from source
+// by AddMessage and AddMessages.
+func New(defaultLocale string, options ...messageformat.Option) *Catalog {
+	return &Catalog{
+		bundles:       make(map[string]map[string]*messageformat.MessageFormat),
+		fallbacks:     make(map[string][]string),
+		defaultLocale: normalizeLocale(defaultLocale),
+		options:       slices.Clone(options),
+	}
+}
+
+// DefaultLocale returns the locale that terminates every fallback chain.
+func (c *Catalog) DefaultLocale() string {
+	return c.defaultLocale
+}
+
+// Locales returns the locales that have at least one message, sorted.
+// TypeScript original code:
+//
+//	get availableLocales(): string[] {
+//	  return Object.keys(this._data);
+//	}
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.bundles))
+}
+
+// IDs returns the message IDs stored directly for locale, sorted.
+// Fallback locales are not consulted.
+func (c *Catalog) IDs(locale string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.bundles[normalizeLocale(locale)]))
+}
+
+// Add stores a compiled message for locale under id, replacing any previous entry.
+func (c *Catalog) Add(locale, id string, mf *messageformat.MessageFormat) error {
+	locale = normalizeLocale(locale)
+	if err := validateKey(locale, id); err != nil {
+		return err
+	}
+	if mf == nil {
+		return fmt.Errorf("%w: %s/%s", ErrNilMessage, locale, id)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(locale, id, mf)
+	return nil
+}
+
+// AddMessage parses source for locale and stores it under id.
+// Parse and validation errors are returned and leave the catalog unchanged.
+func (c *Catalog) AddMessage(locale, id, source string) error {
+	locale = normalizeLocale(locale)
+	if err := validateKey(locale, id); err != nil {
+		return err
+	}
+	mf, err := messageformat.Parse([]string{locale}, source, c.options...)
+	if err != nil {
+		return fmt.Errorf("message %s/%s: %w", locale, id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(locale, id, mf)
+	return nil
+}
+
+// AddMessages parses every source in messages for locale.
+// Messages that compile are stored even when others fail; the returned error
+// joins one diagnostic per failed message ID in sorted ID order.
+// TypeScript original code:
+// addMessages(data: MessageData | compiled message, locale?: string, keypath?: string[])
+func (c *Catalog) AddMessages(locale string, messages map[string]string) error {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		return ErrInvalidLocale
+	}
+
+	compiled := make(map[string]*messageformat.MessageFormat, len(messages))
+	var errs []error
+	for _, id := range slices.Sorted(maps.Keys(messages)) {
+		if id == "" {
+			errs = append(errs, ErrInvalidMessageID)
+			continue
+		}
+		mf, err := messageformat.Parse([]string{locale}, messages[id], c.options...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("message %s/%s: %w", locale, id, err))
+			continue
+		}
+		compiled[id] = mf
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, mf := range compiled {
+		c.add(locale, id, mf)
+	}
+	return errors.Join(errs...)
+}
+
+// add stores mf; callers must hold the write lock.
+func (c *Catalog) add(locale, id string, mf *messageformat.MessageFormat) {
+	bundle, ok := c.bundles[locale]
+	if !ok {
+		bundle = make(map[string]*messageformat.MessageFormat)
+		c.bundles[locale] = bundle
+	}
+	bundle[id] = mf
+}
+
+// SetFallback sets the explicit fallback locales consulted after locale and
+// its parent locales. A nil or empty fallback removes the explicit entry.
+// TypeScript original code:
+//
+//	setFallback(lc: string, fallback: string[] | null) {
+//	  this._fallback[lc] = Array.isArray(fallback) ? fallback : null;
+//	  return this;
+//	}
+func (c *Catalog) SetFallback(locale string, fallback []string) {
+	locale = normalizeLocale(locale)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(fallback) == 0 {
+		delete(c.fallbacks, locale)
+		return
+	}
+	normalized := make([]string, 0, len(fallback))
+	for _, lc := range fallback {
+		if lc = normalizeLocale(lc); lc != "" {
+			normalized = append(normalized, lc)
+		}
+	}
+	c.fallbacks[locale] = normalized
+}
+
+// FallbackChain returns the locales searched for locale, in order.
+//
+// The chain starts with locale and its parents obtained by removing trailing
+// subtags (de-CH-1996 -> de-CH -> de), continues with explicit fallbacks set
+// through SetFallback for any of those locales (each followed by its own
+// parents), and ends with the default locale. Duplicates are removed.
+// TypeScript original code:
+//
+//	getFallback(locale?: string | null) {
+//	  const lc = locale || String(this.locale);
+//	  return (
+//	    this._fallback[lc] ||
+//	    (lc === this.defaultLocale || !this.defaultLocale
+//	      ? []
+//	      : [this.defaultLocale])
+//	  );
+//	}
+func (c *Catalog) FallbackChain(locale string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fallbackChain(normalizeLocale(locale))
+}
+
+// fallbackChain builds the lookup chain; callers must hold the read lock.
+func (c *Catalog) fallbackChain(locale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	appendWithParents := func(lc string) {
+		for _, candidate := range parentLocales(lc) {
+			if !seen[candidate] {
+				seen[candidate] = true
+				chain = append(chain, candidate)
+			}
+		}
+	}
+
+	own := parentLocales(locale)
+	for _, lc := range own {
+		appendWithParents(lc)
+	}
+	for _, lc := range own {
+		for _, fallback := range c.fallbacks[lc] {
+			appendWithParents(fallback)
+		}
+	}
+	if c.defaultLocale != "" {
+		appendWithParents(c.defaultLocale)
+	}
+	return chain
+}
+
+// Has reports whether id resolves for locale through its fallback chain.
+// TypeScript original code:
+//
+//	hasMessage(key: string | string[], locale?: string, fallback?: boolean) {
+//	  const lc = locale || String(this.locale);
+//	  const fb = fallback ? this.getFallback(lc) : null;
+//	  return _has(this._data, lc, key, fb, 'function');
+//	}
+func (c *Catalog) Has(locale, id string) bool {
+	_, _, ok := c.Lookup(locale, id)
+	return ok
+}
+
+// Lookup resolves id for locale through its fallback chain and returns the
+// compiled message together with the locale whose bundle provided it.
+func (c *Catalog) Lookup(locale, id string) (*messageformat.MessageFormat, string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lc := range c.fallbackChain(normalizeLocale(locale)) {
+		if mf, ok := c.bundles[lc][id]; ok {
+			return mf, lc, true
+		}
+	}
+	return nil, "", false
+}
+
+// Format resolves id for locale and formats it with values.
+// A missing message returns an error matching ErrMessageNotFound; runtime
+// diagnostics follow the semantics of (*messageformat.MessageFormat).Format.
+func (c *Catalog) Format(locale, id string, values map[string]any) (string, error) {
+	mf, err := c.message(locale, id)
+	if err != nil {
+		return "", err
+	}
+	return mf.Format(values)
+}
+
+// FormatToParts resolves id for locale and formats it to structured parts.
+// A missing message returns an error matching ErrMessageNotFound; runtime
+// diagnostics follow the semantics of (*messageformat.MessageFormat).FormatToParts.
+func (c *Catalog) FormatToParts(locale, id string, values map[string]any) ([]messagevalue.MessagePart, error) {
+	mf, err := c.message(locale, id)
+	if err != nil {
+		return nil, err
+	}
+	return mf.FormatToParts(values)
+}
+
+// message returns the resolved formatter or a not-found error.
+func (c *Catalog) message(locale, id string) (*messageformat.MessageFormat, error) {
+	mf, _, ok := c.Lookup(locale, id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q for locale %q", ErrMessageNotFound, id, locale)
+	}
+	return mf, nil
+}
+
+// validateKey rejects empty locale and message ID keys.
+func validateKey(locale, id string) error {
+	if locale == "" {
+		return ErrInvalidLocale
+	}
+	if id == "" {
+		return ErrInvalidMessageID
+	}
+	return nil
+}
+
+// normalizeLocale accepts POSIX-style tags (`de_CH`) by mapping underscores
+// to hyphens, matching the locale handling of the formatting functions.
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+}
+
+// parentLocales returns locale followed by its truncated parents.
+// TypeScript original code:
+//
+//	while ((lc = lc.replace(/[-_]?[^-_]*$/, ''))) {
+//	  if (this._data[lc]) return lc;
+//	}
+func parentLocales(locale string) []string {
+	if locale == "" {
+		return nil
+	}
+	chain := []string{locale}
+	for {
+		i := strings.LastIndexByte(locale, '-')
+		if i <= 0 {
+			return chain
+		}
+		locale = locale[:i]
+		chain = append(chain, locale)
+	}
+}
@@ -0,0 +1,207 @@
+package catalog
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	messageformat "github.com/kaptinlin/messageformat-go"
+)
+
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	c := New("en")
+	require.NoError(t, c.AddMessages("en", map[string]string{
+		"greeting": "Hello {$name}!",
+		"farewell": "Goodbye",
+		"only-en":  "English only",
+	}))
+	require.NoError(t, c.AddMessages("de", map[string]string{
+		"greeting": "Hallo {$name}!",
+		"farewell": "Tschüss",
+	}))
+	require.NoError(t, c.AddMessages("de-CH", map[string]string{
+		"farewell": "Uf Wiederluege",
+	}))
+	return c
+}
+
+func TestFallbackChain(t *testing.T) {
+	c := New("en")
+	c.SetFallback("pt-BR", []string{"pt-PT"})
+	c.SetFallback("nb", []string{"no", "da"})
+
+	tests := []struct {
+		name   string
+		locale string
+		want   []string
+	}{
+		{name: "region to language to default", locale: "de-CH", want: []string{"de-CH", "de", "en"}},
+		{name: "longer tag", locale: "de-CH-1996", want: []string{"de-CH-1996", "de-CH", "de", "en"}},
+		{name: "underscore separator", locale: "de_CH", want: []string{"de-CH", "de", "en"}},
+		{name: "default locale only once", locale: "en-GB", want: []string{"en-GB", "en"}},
+		{name: "explicit fallback after parents", locale: "pt-BR", want: []string{"pt-BR", "pt", "pt-PT", "en"}},
+		{name: "explicit fallback list", locale: "nb", want: []string{"nb", "no", "da", "en"}},
+		{name: "parent fallback applies to child", locale: "nb-NO", want: []string{"nb-NO", "nb", "no", "da", "en"}},
+		{name: "empty locale", locale: "", want: []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.FallbackChain(tt.locale))
+		})
+	}
+}
+
+func TestSetFallbackRemove(t *testing.T) {
+	c := New("en")
+	c.SetFallback("pt-BR", []string{"pt-PT"})
+	c.SetFallback("pt-BR", nil)
+
+	assert.Equal(t, []string{"pt-BR", "pt", "en"}, c.FallbackChain("pt-BR"))
+}
+
+func TestLookup(t *testing.T) {
+	c := newTestCatalog(t)
+
+	tests := []struct {
+		name       string
+		locale     string
+		id         string
+		wantLocale string
+		wantFound  bool
+	}{
+		{name: "exact locale", locale: "de-CH", id: "farewell", wantLocale: "de-CH", wantFound: true},
+		{name: "parent locale", locale: "de-CH", id: "greeting", wantLocale: "de", wantFound: true},
+		{name: "default locale", locale: "de-CH", id: "only-en", wantLocale: "en", wantFound: true},
+		{name: "unknown locale falls back to default", locale: "fr", id: "greeting", wantLocale: "en", wantFound: true},
+		{name: "missing id", locale: "de", id: "missing", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, locale, ok := c.Lookup(tt.locale, tt.id)
+			assert.Equal(t, tt.wantFound, ok)
+			assert.Equal(t, tt.wantLocale, locale)
+			assert.Equal(t, tt.wantFound, mf != nil)
+			assert.Equal(t, tt.wantFound, c.Has(tt.locale, tt.id))
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	c := newTestCatalog(t)
+
+	tests := []struct {
+		name   string
+		locale string
+		id     string
+		values map[string]any
+		want   string
+	}{
+		{name: "own bundle", locale: "de-CH", id: "farewell", want: "Uf Wiederluege"},
+		{name: "parent bundle", locale: "de-CH", id: "greeting", values: map[string]any{"name": "Anna"}, want: "Hallo ⁨Anna⁩!"},
+		{name: "default bundle", locale: "de-CH", id: "only-en", want: "English only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Format(tt.locale, tt.id, tt.values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatMissingMessage(t *testing.T) {
+	c := newTestCatalog(t)
+
+	got, err := c.Format("de", "missing", nil)
+	require.ErrorIs(t, err, ErrMessageNotFound)
+	assert.Empty(t, got)
+
+	parts, err := c.FormatToParts("de", "missing", nil)
+	require.ErrorIs(t, err, ErrMessageNotFound)
+	assert.Nil(t, parts)
+}
+
+func TestFormatToParts(t *testing.T) {
+	c := newTestCatalog(t)
+
+	parts, err := c.FormatToParts("de-CH", "farewell", nil)
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	assert.Equal(t, "text", parts[0].Type())
+	assert.Equal(t, "Uf Wiederluege", parts[0].Value())
+}
+
+func TestAddMessagesReportsFailures(t *testing.T) {
+	c := New("en")
+
+	err := c.AddMessages("en", map[string]string{
+		"ok":     "Fine",
+		"broken": "Hello {$name",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "en/broken")
+	assert.True(t, c.Has("en", "ok"))
+	assert.False(t, c.Has("en", "broken"))
+}
+
+func TestAddValidation(t *testing.T) {
+	c := New("en")
+	mf, err := messageformat.Parse([]string{"en"}, "Hi")
+	require.NoError(t, err)
+
+	require.ErrorIs(t, c.Add("", "id", mf), ErrInvalidLocale)
+	require.ErrorIs(t, c.Add("en", "", mf), ErrInvalidMessageID)
+	require.ErrorIs(t, c.Add("en", "id", nil), ErrNilMessage)
+	require.ErrorIs(t, c.AddMessage("", "id", "Hi"), ErrInvalidLocale)
+	require.ErrorIs(t, c.AddMessages("", map[string]string{"id": "Hi"}), ErrInvalidLocale)
+	require.ErrorIs(t, c.AddMessages("en", map[string]string{"": "Hi"}), ErrInvalidMessageID)
+
+	require.NoError(t, c.Add("en_US", "id", mf))
+	assert.Equal(t, []string{"en-US"}, c.Locales())
+	assert.Equal(t, []string{"id"}, c.IDs("en-US"))
+}
+
+func TestAddMessageError(t *testing.T) {
+	c := New("en")
+
+	err := c.AddMessage("en", "broken", "{{")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "en/broken")
+	assert.Empty(t, c.Locales())
+}
+
+func TestCatalogOptionsApplied(t *testing.T) {
+	c := New("en", messageformat.WithBidiIsolation(messageformat.BidiNone))
+	require.NoError(t, c.AddMessage("en", "greeting", "Hello {$name}!"))
+
+	got, err := c.Format("en", "greeting", map[string]any{"name": "Anna"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Anna!", got)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	c := newTestCatalog(t)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Format("de-CH", "greeting", map[string]any{"name": "Anna"})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = c.AddMessage("fr", "greeting", "Bonjour")
+			c.SetFallback("fr-CA", []string{"fr"})
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, c.Has("fr-CA", "greeting"))
+}
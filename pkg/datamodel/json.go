@@ -0,0 +1,610 @@
+package datamodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ErrInvalidJSON reports JSON that does not describe a message data model.
+var ErrInvalidJSON = errors.New("invalid message JSON")
+
+// jsonNode is the union of every object shape in the message.json schema.
+// Decoding dispatches on Type and reads only the members that shape defines.
+type jsonNode struct {
+	Type         string                     `json:"type"`
+	Name         *string                    `json:"name"`
+	Value        json.RawMessage            `json:"value"`
+	Kind         string                     `json:"kind"`
+	Arg          json.RawMessage            `json:"arg"`
+	Function     json.RawMessage            `json:"function"`
+	FunctionRef  json.RawMessage            `json:"functionRef"`
+	Options      map[string]json.RawMessage `json:"options"`
+	Attributes   map[string]json.RawMessage `json:"attributes"`
+	Declarations []json.RawMessage          `json:"declarations"`
+	Pattern      *[]json.RawMessage         `json:"pattern"`
+	Selectors    *[]json.RawMessage         `json:"selectors"`
+	Variants     *[]json.RawMessage         `json:"variants"`
+	Keys         *[]json.RawMessage         `json:"keys"`
+	Comment      string                     `json:"comment"`
+}
+
+// MessageFromJSON decodes a message from the JSON representation defined by
+// the MessageFormat 2.0 data model schema (spec/data-model/message.json).
+//
+// The decoded message is structurally valid: every node is built with the
+// package constructors. Data model errors such as duplicate declarations or
+// missing fallback variants are reported by ValidateMessage, as for messages
+// built in code. For compatibility with the JavaScript data model, an
+// expression's function may also be given as "functionRef".
+func MessageFromJSON(data []byte) (Message, error) {
+	node, err := decodeNode(data, "message")
+	if err != nil {
+		return nil, err
+	}
+	switch node.Type {
+	case "message":
+		return decodePatternMessage(node)
+	case "select":
+		return decodeSelectMessage(node)
+	default:
+		return nil, invalidJSON("message", "unknown message type %q", node.Type)
+	}
+}
+
+// MarshalJSON encodes the message as a message.json "message" object.
+func (pm *PatternMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type         string        `json:"type"`
+		Declarations []Declaration `json:"declarations"`
+		Pattern      Pattern       `json:"pattern"`
+		Comment      string        `json:"comment,omitempty"`
+	}{pm.Type(), jsonDeclarations(pm.declarations), pm.pattern, pm.comment})
+}
+
+// UnmarshalJSON decodes a message.json "message" object.
+func (pm *PatternMessage) UnmarshalJSON(data []byte) error {
+	node, err := decodeNode(data, "message")
+	if err != nil {
+		return err
+	}
+	if node.Type != "message" {
+		return invalidJSON("message", "expected type %q, got %q", "message", node.Type)
+	}
+	msg, err := decodePatternMessage(node)
+	if err != nil {
+		return err
+	}
+	*pm = *msg
+	return nil
+}
+
+// MarshalJSON encodes the message as a message.json "select" object.
+func (sm *SelectMessage) MarshalJSON() ([]byte, error) {
+	selectors := sm.selectors
+	if selectors == nil {
+		selectors = []VariableRef{}
+	}
+	variants := sm.variants
+	if variants == nil {
+		variants = []Variant{}
+	}
+	return json.Marshal(struct {
+		Type         string        `json:"type"`
+		Declarations []Declaration `json:"declarations"`
+		Selectors    []VariableRef `json:"selectors"`
+		Variants     []Variant     `json:"variants"`
+		Comment      string        `json:"comment,omitempty"`
+	}{sm.Type(), jsonDeclarations(sm.declarations), selectors, variants, sm.comment})
+}
+
+// UnmarshalJSON decodes a message.json "select" object.
+func (sm *SelectMessage) UnmarshalJSON(data []byte) error {
+	node, err := decodeNode(data, "message")
+	if err != nil {
+		return err
+	}
+	if node.Type != "select" {
+		return invalidJSON("message", "expected type %q, got %q", "select", node.Type)
+	}
+	msg, err := decodeSelectMessage(node)
+	if err != nil {
+		return err
+	}
+	*sm = *msg
+	return nil
+}
+
+// MarshalJSON encodes the declaration as a message.json "input" object.
+func (id *InputDeclaration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string      `json:"type"`
+		Name  string      `json:"name"`
+		Value *Expression `json:"value"`
+	}{id.Type(), id.Name(), id.value})
+}
+
+// MarshalJSON encodes the declaration as a message.json "local" object.
+func (ld *LocalDeclaration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string      `json:"type"`
+		Name  string      `json:"name"`
+		Value *Expression `json:"value"`
+	}{ld.Type(), ld.name, ld.value})
+}
+
+// MarshalJSON encodes the variant as a message.json variant object.
+func (v Variant) MarshalJSON() ([]byte, error) {
+	keys := v.keys
+	if keys == nil {
+		keys = []VariantKey{}
+	}
+	return json.Marshal(struct {
+		Keys  []VariantKey `json:"keys"`
+		Value Pattern      `json:"value"`
+	}{keys, v.value})
+}
+
+// MarshalJSON encodes the key as a message.json catchall key object. The
+// value is omitted when it is the bare "*" written in message syntax.
+func (ck *CatchallKey) MarshalJSON() ([]byte, error) {
+	value := ck.value
+	if value == "*" {
+		value = ""
+	}
+	return json.Marshal(struct {
+		Type  string `json:"type"`
+		Value string `json:"value,omitempty"`
+	}{ck.Type(), value})
+}
+
+// MarshalJSON encodes the pattern as a message.json pattern array, with text
+// as plain strings. A nil pattern encodes as an empty array.
+func (p Pattern) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]PatternElement(p))
+}
+
+// MarshalJSON encodes the text as a JSON string.
+func (te *TextElement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(te.value)
+}
+
+// MarshalJSON encodes the expression as a message.json "expression" object.
+func (e *Expression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string        `json:"type"`
+		Arg        ExpressionArg `json:"arg,omitempty"`
+		Function   *FunctionRef  `json:"function,omitempty"`
+		Attributes Attributes    `json:"attributes,omitempty"`
+	}{e.Type(), e.arg, e.functionRef, e.attributes})
+}
+
+// MarshalJSON encodes the literal as a message.json "literal" object.
+func (l *Literal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}{l.Type(), l.value})
+}
+
+// MarshalJSON encodes the reference as a message.json "variable" object.
+func (vr VariableRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}{vr.Type(), vr.name})
+}
+
+// MarshalJSON encodes the reference as a message.json "function" object.
+func (fr *FunctionRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string  `json:"type"`
+		Name    string  `json:"name"`
+		Options Options `json:"options,omitempty"`
+	}{fr.Type(), fr.name, fr.options})
+}
+
+// MarshalJSON encodes the markup as a message.json "markup" object.
+func (m *Markup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string     `json:"type"`
+		Kind       MarkupKind `json:"kind"`
+		Name       string     `json:"name"`
+		Options    Options    `json:"options,omitempty"`
+		Attributes Attributes `json:"attributes,omitempty"`
+	}{m.Type(), m.kind, m.name, m.options, m.attributes})
+}
+
+// MarshalJSON encodes a boolean attribute as JSON true.
+func (ba *BooleanAttribute) MarshalJSON() ([]byte, error) {
+	return []byte("true"), nil
+}
+
+func jsonDeclarations(declarations []Declaration) []Declaration {
+	if declarations == nil {
+		return []Declaration{}
+	}
+	return declarations
+}
+
+func invalidJSON(path, format string, args ...any) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidJSON, path, fmt.Sprintf(format, args...))
+}
+
+func decodeNode(data []byte, path string) (jsonNode, error) {
+	var node jsonNode
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return node, invalidJSON(path, "expected an object")
+	}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return node, invalidJSON(path, "%v", err)
+	}
+	return node, nil
+}
+
+func decodeString(data json.RawMessage, path string) (string, error) {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", invalidJSON(path, "expected a string")
+	}
+	return value, nil
+}
+
+func requireName(node jsonNode, path string) (string, error) {
+	if node.Name == nil {
+		return "", invalidJSON(path, "missing name")
+	}
+	return *node.Name, nil
+}
+
+func decodePatternMessage(node jsonNode) (*PatternMessage, error) {
+	declarations, err := decodeDeclarations(node.Declarations)
+	if err != nil {
+		return nil, err
+	}
+	if node.Pattern == nil {
+		return nil, invalidJSON("message", "missing pattern")
+	}
+	pattern, err := decodePattern(*node.Pattern, "pattern")
+	if err != nil {
+		return nil, err
+	}
+	return NewPatternMessage(declarations, pattern, node.Comment)
+}
+
+func decodeSelectMessage(node jsonNode) (*SelectMessage, error) {
+	declarations, err := decodeDeclarations(node.Declarations)
+	if err != nil {
+		return nil, err
+	}
+	if node.Selectors == nil {
+		return nil, invalidJSON("message", "missing selectors")
+	}
+	if node.Variants == nil {
+		return nil, invalidJSON("message", "missing variants")
+	}
+
+	selectors := make([]VariableRef, 0, len(*node.Selectors))
+	for i, raw := range *node.Selectors {
+		ref, err := decodeVariableRef(raw, "selectors["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, *ref)
+	}
+
+	variants := make([]Variant, 0, len(*node.Variants))
+	for i, raw := range *node.Variants {
+		variant, err := decodeVariant(raw, "variants["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, *variant)
+	}
+	return NewSelectMessage(declarations, selectors, variants, node.Comment)
+}
+
+func decodeDeclarations(raws []json.RawMessage) ([]Declaration, error) {
+	declarations := make([]Declaration, 0, len(raws))
+	for i, raw := range raws {
+		path := "declarations[" + strconv.Itoa(i) + "]"
+		node, err := decodeNode(raw, path)
+		if err != nil {
+			return nil, err
+		}
+		name, err := requireName(node, path)
+		if err != nil {
+			return nil, err
+		}
+		if node.Value == nil {
+			return nil, invalidJSON(path, "missing value")
+		}
+		value, err := decodeExpression(node.Value, path+".value")
+		if err != nil {
+			return nil, err
+		}
+
+		switch node.Type {
+		case "input":
+			if arg, ok := value.arg.(*VariableRef); !ok || arg.name != name {
+				return nil, invalidJSON(path, "input value must be an expression of variable %q", name)
+			}
+			decl, err := NewInputDeclaration(value)
+			if err != nil {
+				return nil, err
+			}
+			declarations = append(declarations, decl)
+		case "local":
+			declarations = append(declarations, NewLocalDeclaration(name, value))
+		default:
+			return nil, invalidJSON(path, "unknown declaration type %q", node.Type)
+		}
+	}
+	return declarations, nil
+}
+
+func decodeVariant(data json.RawMessage, path string) (*Variant, error) {
+	node, err := decodeNode(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if node.Keys == nil {
+		return nil, invalidJSON(path, "missing keys")
+	}
+	if node.Value == nil {
+		return nil, invalidJSON(path, "missing value")
+	}
+
+	keys := make([]VariantKey, 0, len(*node.Keys))
+	for i, raw := range *node.Keys {
+		key, err := decodeVariantKey(raw, path+".keys["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(node.Value, &elements); err != nil || elements == nil {
+		return nil, invalidJSON(path+".value", "expected a pattern array")
+	}
+	pattern, err := decodePattern(elements, path+".value")
+	if err != nil {
+		return nil, err
+	}
+	return NewVariant(keys, pattern)
+}
+
+func decodeVariantKey(data json.RawMessage, path string) (VariantKey, error) {
+	node, err := decodeNode(data, path)
+	if err != nil {
+		return nil, err
+	}
+	switch node.Type {
+	case "literal":
+		return decodeLiteralNode(node, path)
+	case "*":
+		value := ""
+		if node.Value != nil {
+			if value, err = decodeString(node.Value, path+".value"); err != nil {
+				return nil, err
+			}
+		}
+		return NewCatchallKey(value), nil
+	default:
+		return nil, invalidJSON(path, "unknown key type %q", node.Type)
+	}
+}
+
+func decodePattern(raws []json.RawMessage, path string) (Pattern, error) {
+	elements := make([]PatternElement, 0, len(raws))
+	for i, raw := range raws {
+		elementPath := path + "[" + strconv.Itoa(i) + "]"
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte(`"`)) {
+			text, err := decodeString(raw, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, NewTextElement(text))
+			continue
+		}
+
+		node, err := decodeNode(raw, elementPath)
+		if err != nil {
+			return nil, err
+		}
+		switch node.Type {
+		case "expression":
+			expr, err := decodeExpressionNode(node, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, expr)
+		case "markup":
+			markup, err := decodeMarkupNode(node, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, markup)
+		default:
+			return nil, invalidJSON(elementPath, "unknown pattern element type %q", node.Type)
+		}
+	}
+	return NewPattern(elements)
+}
+
+func decodeExpression(data json.RawMessage, path string) (*Expression, error) {
+	node, err := decodeNode(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if node.Type != "expression" {
+		return nil, invalidJSON(path, "expected type %q, got %q", "expression", node.Type)
+	}
+	return decodeExpressionNode(node, path)
+}
+
+func decodeExpressionNode(node jsonNode, path string) (*Expression, error) {
+	var arg ExpressionArg
+	if node.Arg != nil {
+		value, err := decodeValue(node.Arg, path+".arg")
+		if err != nil {
+			return nil, err
+		}
+		arg = value.(ExpressionArg)
+	}
+
+	function := node.Function
+	if function == nil {
+		function = node.FunctionRef
+	}
+	var functionRef *FunctionRef
+	if function != nil {
+		var err error
+		if functionRef, err = decodeFunctionRef(function, path+".function"); err != nil {
+			return nil, err
+		}
+	}
+
+	attributes, err := decodeAttributes(node.Attributes, path+".attributes")
+	if err != nil {
+		return nil, err
+	}
+	expr, err := NewExpression(arg, functionRef, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidJSON, path, err)
+	}
+	return expr, nil
+}
+
+func decodeFunctionRef(data json.RawMessage, path string) (*FunctionRef, error) {
+	node, err := decodeNode(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if node.Type != "function" {
+		return nil, invalidJSON(path, "expected type %q, got %q", "function", node.Type)
+	}
+	name, err := requireName(node, path)
+	if err != nil {
+		return nil, err
+	}
+	options, err := decodeOptions(node.Options, path+".options")
+	if err != nil {
+		return nil, err
+	}
+	return NewFunctionRef(name, options)
+}
+
+func decodeMarkupNode(node jsonNode, path string) (*Markup, error) {
+	name, err := requireName(node, path)
+	if err != nil {
+		return nil, err
+	}
+	kind := MarkupKind(node.Kind)
+	if !validMarkupKind(kind) {
+		return nil, invalidJSON(path, "invalid markup kind %q", node.Kind)
+	}
+	options, err := decodeOptions(node.Options, path+".options")
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := decodeAttributes(node.Attributes, path+".attributes")
+	if err != nil {
+		return nil, err
+	}
+	return NewMarkup(kind, name, options, attributes)
+}
+
+func decodeOptions(raws map[string]json.RawMessage, path string) (Options, error) {
+	if raws == nil {
+		return nil, nil
+	}
+	options := make(Options, len(raws))
+	for _, name := range slices.Sorted(maps.Keys(raws)) {
+		value, err := decodeValue(raws[name], path+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		options[name] = value
+	}
+	return options, nil
+}
+
+func decodeAttributes(raws map[string]json.RawMessage, path string) (Attributes, error) {
+	if raws == nil {
+		return nil, nil
+	}
+	attributes := make(Attributes, len(raws))
+	for _, name := range slices.Sorted(maps.Keys(raws)) {
+		raw := raws[name]
+		if string(bytes.TrimSpace(raw)) == "true" {
+			attributes[name] = NewBooleanAttribute()
+			continue
+		}
+		node, err := decodeNode(raw, path+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		if node.Type != "literal" {
+			return nil, invalidJSON(path+"."+name, "attribute must be a literal or true")
+		}
+		literal, err := decodeLiteralNode(node, path+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		attributes[name] = literal
+	}
+	return attributes, nil
+}
+
+// decodeValue decodes a literal or variable reference, the values allowed as
+// expression arguments and option values.
+func decodeValue(data json.RawMessage, path string) (OptionValue, error) {
+	node, err := decodeNode(data, path)
+	if err != nil {
+		return nil, err
+	}
+	switch node.Type {
+	case "literal":
+		return decodeLiteralNode(node, path)
+	case "variable":
+		name, err := requireName(node, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewVariableRef(name), nil
+	default:
+		return nil, invalidJSON(path, "expected a literal or variable, got %q", node.Type)
+	}
+}
+
+func decodeVariableRef(data json.RawMessage, path string) (*VariableRef, error) {
+	value, err := decodeValue(data, path)
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := value.(*VariableRef)
+	if !ok {
+		return nil, invalidJSON(path, "expected a variable")
+	}
+	return ref, nil
+}
+
+func decodeLiteralNode(node jsonNode, path string) (*Literal, error) {
+	if node.Value == nil {
+		return nil, invalidJSON(path, "missing value")
+	}
+	value, err := decodeString(node.Value, path+".value")
+	if err != nil {
+		return nil, err
+	}
+	return NewLiteral(value), nil
+}
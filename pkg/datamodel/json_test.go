@@ -0,0 +1,140 @@
+package datamodel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	sources := []string{
+		"Hello, world!",
+		"Hello {$name}!",
+		"{|literal| :string u:id=greeting}",
+		"{:datetime dateStyle=long}",
+		"{#link href=$url @hidden @translate=no}click{/link}{#br/}",
+		".input {$count :number} .local $label = {|items| :string} {{{$count} {$label}}}",
+		".input {$n :number} .match $n 0 {{none}} one {{one}} * {{many {$n}}}",
+		".input {$a :string} .input {$b :string} .match $a $b x y {{xy}} x * {{x}} * * {{other}}",
+	}
+
+	for _, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			msg, err := ParseMessage(source)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(msg)
+			require.NoError(t, err)
+
+			decoded, err := MessageFromJSON(data)
+			require.NoError(t, err)
+			_, err = ValidateMessage(decoded, nil)
+			require.NoError(t, err)
+
+			again, err := json.Marshal(decoded)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(again))
+
+			// Options and attributes stringify in map order, so compare the
+			// reparsed source through its JSON form.
+			reparsed, err := ParseMessage(StringifyMessage(decoded))
+			require.NoError(t, err)
+			fromSource, err := json.Marshal(reparsed)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(fromSource))
+		})
+	}
+}
+
+func TestMessageJSONSchemaShape(t *testing.T) {
+	msg, err := ParseMessage(".input {$n :number minimumFractionDigits=1} .match $n one {{{#b}one{/b}}} * {{{$n} @x}}")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "select",
+		"declarations": [{
+			"type": "input",
+			"name": "n",
+			"value": {
+				"type": "expression",
+				"arg": {"type": "variable", "name": "n"},
+				"function": {
+					"type": "function",
+					"name": "number",
+					"options": {"minimumFractionDigits": {"type": "literal", "value": "1"}}
+				}
+			}
+		}],
+		"selectors": [{"type": "variable", "name": "n"}],
+		"variants": [
+			{
+				"keys": [{"type": "literal", "value": "one"}],
+				"value": [
+					{"type": "markup", "kind": "open", "name": "b"},
+					"one",
+					{"type": "markup", "kind": "close", "name": "b"}
+				]
+			},
+			{
+				"keys": [{"type": "*"}],
+				"value": [
+					{"type": "expression", "arg": {"type": "variable", "name": "n"}},
+					" @x"
+				]
+			}
+		]
+	}`, string(data))
+}
+
+func TestMessageJSONUnmarshalConcreteTypes(t *testing.T) {
+	var pm PatternMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"message","declarations":[],"pattern":["Hi ",{"type":"expression","arg":{"type":"variable","name":"x"},"attributes":{"flag":true}}]}`), &pm))
+	assert.Equal(t, "Hi {$x @flag}", StringifyMessage(&pm))
+
+	var sm SelectMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"select","declarations":[],"selectors":[{"type":"variable","name":"x"}],"variants":[{"keys":[{"type":"*","value":"other"}],"value":["x"]}]}`), &sm))
+	require.Len(t, sm.Variants(), 1)
+	assert.Equal(t, "other", sm.Variants()[0].Keys()[0].(*CatchallKey).Value())
+
+	err := json.Unmarshal([]byte(`{"type":"select","selectors":[],"variants":[]}`), &pm)
+	require.ErrorIs(t, err, ErrInvalidJSON)
+}
+
+func TestMessageFromJSONAcceptsFunctionRef(t *testing.T) {
+	msg, err := MessageFromJSON([]byte(`{"type":"message","declarations":[],"pattern":[{"type":"expression","functionRef":{"type":"function","name":"now"}}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "{:now}", StringifyMessage(msg))
+}
+
+func TestMessageFromJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "not an object", json: `[]`, want: "message: expected an object"},
+		{name: "unknown message type", json: `{"type":"other"}`, want: `unknown message type "other"`},
+		{name: "missing pattern", json: `{"type":"message","declarations":[]}`, want: "missing pattern"},
+		{name: "missing variants", json: `{"type":"select","declarations":[],"selectors":[]}`, want: "missing variants"},
+		{name: "empty expression", json: `{"type":"message","pattern":[{"type":"expression"}]}`, want: "pattern[0]: invalid expression"},
+		{name: "bad element", json: `{"type":"message","pattern":[42]}`, want: "pattern[0]: expected an object"},
+		{name: "bad markup kind", json: `{"type":"message","pattern":[{"type":"markup","kind":"self","name":"b"}]}`, want: `invalid markup kind "self"`},
+		{name: "bad option value", json: `{"type":"message","pattern":[{"type":"expression","function":{"type":"function","name":"f","options":{"o":{"type":"function","name":"g"}}}}]}`, want: "pattern[0].function.options.o: expected a literal or variable"},
+		{name: "bad attribute", json: `{"type":"message","pattern":[{"type":"expression","arg":{"type":"literal","value":"x"},"attributes":{"a":false}}]}`, want: "pattern[0].attributes.a: expected an object"},
+		{name: "input name mismatch", json: `{"type":"message","declarations":[{"type":"input","name":"a","value":{"type":"expression","arg":{"type":"variable","name":"b"}}}],"pattern":[]}`, want: `declarations[0]: input value must be an expression of variable "a"`},
+		{name: "selector literal", json: `{"type":"select","selectors":[{"type":"literal","value":"x"}],"variants":[]}`, want: "selectors[0]: expected a variable"},
+		{name: "unknown key type", json: `{"type":"select","selectors":[],"variants":[{"keys":[{"type":"variable","name":"x"}],"value":[]}]}`, want: `variants[0].keys[0]: unknown key type "variable"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MessageFromJSON([]byte(tt.json))
+			require.ErrorIs(t, err, ErrInvalidJSON)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -4,7 +4,9 @@ package datamodel
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -160,10 +162,9 @@ func stringifyExpression(expr *Expression) string {
 	}
 
 	// Add attributes
-	if expr.Attributes() != nil {
-		for name, value := range expr.Attributes() {
-			parts = append(parts, stringifyAttribute(name, value))
-		}
+	attributes := expr.Attributes()
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		parts = append(parts, stringifyAttribute(name, attributes[name]))
 	}
 
 	return fmt.Sprintf("{%s}", strings.Join(parts, " "))
@@ -185,10 +186,11 @@ func stringifyFunctionRef(fr *FunctionRef) string {
 	var result strings.Builder
 	result.WriteString(":" + fr.Name())
 
-	if fr.Options() != nil {
-		for name, value := range fr.Options() {
-			result.WriteString(" " + stringifyOption(name, value))
-		}
+	// Go maps have no insertion order; names are written sorted so the
+	// output is stable.
+	options := fr.Options()
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		result.WriteString(" " + stringifyOption(name, options[name]))
 	}
 
 	return result.String()
@@ -225,19 +227,17 @@ func stringifyMarkup(markup *Markup) string {
 	result.WriteString(markup.Name())
 
 	// Add options
-	if markup.Options() != nil {
-		for name, value := range markup.Options() {
-			result.WriteString(" ")
-			result.WriteString(stringifyOption(name, value))
-		}
+	options := markup.Options()
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		result.WriteString(" ")
+		result.WriteString(stringifyOption(name, options[name]))
 	}
 
 	// Add attributes
-	if markup.Attributes() != nil {
-		for name, value := range markup.Attributes() {
-			result.WriteString(" ")
-			result.WriteString(stringifyAttribute(name, value))
-		}
+	attributes := markup.Attributes()
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		result.WriteString(" ")
+		result.WriteString(stringifyAttribute(name, attributes[name]))
 	}
 
 	if markup.Kind() == "standalone" {
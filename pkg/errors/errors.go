@@ -4,6 +4,7 @@ package errors
 
 import (
 	"fmt"
+	"runtime/debug"
 	"strings"
 )
 
@@ -106,6 +107,11 @@ type MessageSyntaxError struct {
 	End   int // End position in source
 }
 
+// Span returns the byte offsets of the error in the message source.
+func (e *MessageSyntaxError) Span() (start, end int) {
+	return e.Start, e.End
+}
+
 // NewMessageSyntaxError creates a new syntax error
 // TypeScript original code: MessageSyntaxError constructor
 func NewMessageSyntaxError(errorType ErrorKind, start int, end *int, expected *string) *MessageSyntaxError {
@@ -208,6 +214,8 @@ type MessageResolutionError struct {
 	*MessageError
 	Source string // Source text where error occurred
 	Cause  error  // Underlying cause error (optional)
+	Start  int    // Start of the expression in the message source, or -1
+	End    int    // End of the expression in the message source, or -1
 }
 
 // NewMessageResolutionError creates a new resolution error
@@ -227,6 +235,8 @@ func NewMessageResolutionError(errorType ErrorKind, message, source string, caus
 		MessageError: NewMessageError(errorType, message),
 		Source:       source,
 		Cause:        rootCause,
+		Start:        -1,
+		End:          -1,
 	}
 }
 
@@ -235,6 +245,17 @@ func (e *MessageResolutionError) Unwrap() error {
 	return e.Cause
 }
 
+// Span returns the byte offsets of the expression in the message source, or
+// -1 when they are unknown.
+func (e *MessageResolutionError) Span() (start, end int) {
+	return e.Start, e.End
+}
+
+// SetSpan sets the byte offsets of the expression in the message source.
+func (e *MessageResolutionError) SetSpan(start, end int) {
+	e.Start, e.End = start, end
+}
+
 // MessageSelectionError represents errors in message selection
 // TypeScript original code:
 //
@@ -252,6 +273,8 @@ func (e *MessageResolutionError) Unwrap() error {
 type MessageSelectionError struct {
 	*MessageError
 	Cause error // Underlying cause error (optional)
+	Start int   // Start of the selector in the message source, or -1
+	End   int   // End of the selector in the message source, or -1
 }
 
 // NewMessageSelectionError creates a new selection error
@@ -262,6 +285,8 @@ func NewMessageSelectionError(errorType ErrorKind, cause error) *MessageSelectio
 	return &MessageSelectionError{
 		MessageError: NewMessageError(errorType, message),
 		Cause:        cause,
+		Start:        -1,
+		End:          -1,
 	}
 }
 
@@ -270,6 +295,17 @@ func (e *MessageSelectionError) Unwrap() error {
 	return e.Cause
 }
 
+// Span returns the byte offsets of the selector in the message source, or
+// -1 when they are unknown.
+func (e *MessageSelectionError) Span() (start, end int) {
+	return e.Start, e.End
+}
+
+// SetSpan sets the byte offsets of the selector in the message source.
+func (e *MessageSelectionError) SetSpan(start, end int) {
+	e.Start, e.End = start, end
+}
+
 // MessageFunctionError represents message function errors
 // TypeScript original code:
 //
@@ -295,6 +331,8 @@ type MessageFunctionError struct {
 	*MessageError
 	Source string // Source text where error occurred, defaults to '�'
 	Cause  error  // Optional underlying cause error
+	Start  int    // Start of the expression in the message source, or -1
+	End    int    // End of the expression in the message source, or -1
 }
 
 // NewMessageFunctionError creates a new function error
@@ -303,6 +341,8 @@ func NewMessageFunctionError(errorType ErrorKind, message string) *MessageFuncti
 	return &MessageFunctionError{
 		MessageError: NewMessageError(errorType, message),
 		Source:       "�", // TypeScript default value
+		Start:        -1,
+		End:          -1,
 	}
 }
 
@@ -321,6 +361,17 @@ func (e *MessageFunctionError) Unwrap() error {
 	return e.Cause
 }
 
+// Span returns the byte offsets of the expression in the message source, or
+// -1 when they are unknown.
+func (e *MessageFunctionError) Span() (start, end int) {
+	return e.Start, e.End
+}
+
+// SetSpan sets the byte offsets of the expression in the message source.
+func (e *MessageFunctionError) SetSpan(start, end int) {
+	e.Start, e.End = start, end
+}
+
 // Error type constants matching TypeScript definitions
 
 // Syntax error types
@@ -397,6 +448,31 @@ func NewBadFunctionResultError(message, source string) *MessageResolutionError {
 	return NewMessageResolutionError(ErrorTypeBadFunctionResult, message, source)
 }
 
+// PanicError is the cause of the bad function result error reported for a
+// panic in a message function or message value.
+type PanicError struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// NewPanicError creates a bad function result error for a recovered panic.
+// Call it from the deferred function that recovered value, so that the
+// recorded stack is the stack of the panic.
+func NewPanicError(value any, source string) *MessageResolutionError {
+	cause := &PanicError{Value: value, Stack: debug.Stack()}
+	return NewMessageResolutionError(ErrorTypeBadFunctionResult, cause.Error(), source, cause)
+}
+
 // NewBadSelectorError creates a bad selector error
 func NewBadSelectorError(cause error) *MessageSelectionError {
 	return NewMessageSelectionError(ErrorTypeBadSelector, cause)
@@ -489,3 +489,20 @@ func TestAllResolutionErrorTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestPanicError(t *testing.T) {
+	cause := errors.New("boom")
+	err := NewPanicError(cause, "$x")
+
+	assert.Equal(t, ErrorTypeBadFunctionResult, err.Type)
+	assert.Equal(t, "$x", err.Source)
+	assert.Contains(t, err.Error(), "panic: boom")
+	assert.ErrorIs(t, err, cause)
+
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, cause, panicErr.Value)
+	assert.Contains(t, string(panicErr.Stack), "TestPanicError")
+
+	assert.NoError(t, NewPanicError("text", "$x").Cause.(*PanicError).Unwrap())
+}
@@ -0,0 +1,168 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Spanner is implemented by errors that know where they occurred in the
+// message source. Start and end are byte offsets; both are -1 when the
+// position is unknown.
+type Spanner interface {
+	Span() (start, end int)
+}
+
+// Diagnostic is an error located in a message source. Its JSON form is the
+// machine-readable counterpart of Render.
+type Diagnostic struct {
+	Kind      ErrorKind `json:"kind,omitempty"`
+	Message   string    `json:"message"`
+	Start     int       `json:"start"`               // Byte offset, or -1 when unknown
+	End       int       `json:"end"`                 // Byte offset, exclusive
+	Line      int       `json:"line,omitempty"`      // 1-based; 0 when unknown
+	Column    int       `json:"column,omitempty"`    // 1-based, counted in characters
+	EndLine   int       `json:"endLine,omitempty"`   // 1-based
+	EndColumn int       `json:"endColumn,omitempty"` // 1-based, exclusive
+}
+
+// NewDiagnostic locates err in source. The message drops the kind prefix and
+// byte offset suffix that the error text repeats.
+func NewDiagnostic(source string, err error) Diagnostic {
+	d := Diagnostic{Message: err.Error(), Start: -1, End: -1}
+
+	var kinded interface{ Kind() ErrorKind }
+	if stderrors.As(err, &kinded) {
+		d.Kind = kinded.Kind()
+	}
+	var spanner Spanner
+	if stderrors.As(err, &spanner) {
+		d.Start, d.End = spanner.Span()
+	}
+	// Selection error messages only name the kind; a cause tells more
+	var selectionErr *MessageSelectionError
+	if stderrors.As(err, &selectionErr) {
+		d.Message = ""
+		if selectionErr.Cause != nil {
+			d.Message = selectionErr.Cause.Error()
+		}
+	}
+
+	if d.Start >= 0 {
+		d.Message = strings.TrimSuffix(d.Message, " at "+strconv.Itoa(d.Start))
+	}
+	if d.Kind != "" {
+		d.Message = strings.TrimPrefix(d.Message, d.Kind.String()+": ")
+	}
+
+	if d.Start >= 0 {
+		d.Start = min(d.Start, len(source))
+		d.End = min(max(d.End, d.Start), len(source))
+		d.Line, d.Column = Position(source, d.Start)
+		d.EndLine, d.EndColumn = Position(source, d.End)
+	}
+	return d
+}
+
+// Diagnose locates each error of err in source, expanding errors joined
+// with errors.Join.
+func Diagnose(source string, err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var diagnostics []Diagnostic
+		for _, e := range joined.Unwrap() {
+			diagnostics = append(diagnostics, Diagnose(source, e)...)
+		}
+		return diagnostics
+	}
+	return []Diagnostic{NewDiagnostic(source, err)}
+}
+
+// String formats d as "line:column: kind: message", leaving out the
+// position when it is unknown and the message when it only names the kind.
+func (d Diagnostic) String() string {
+	description := d.Kind.String()
+	if description == "" {
+		description = d.Message
+	} else if d.Message != "" && d.Message != description {
+		description += ": " + d.Message
+	}
+	if d.Start < 0 {
+		return description
+	}
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, description)
+}
+
+// Position converts a byte offset in source to a 1-based line and a 1-based
+// column counted in characters.
+func Position(source string, offset int) (line, column int) {
+	offset = min(max(offset, 0), len(source))
+	before := source[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return strings.Count(before, "\n") + 1, utf8.RuneCountInString(before[lineStart:]) + 1
+}
+
+// Render describes each error of err with its position and, when the
+// position is known, the source lines it covers with carets under the span:
+//
+//	1:14: missing-syntax: missing }
+//	  |
+//	1 | Hello {$name
+//	  |              ^
+func Render(source string, err error) string {
+	var sb strings.Builder
+	for i, d := range Diagnose(source, err) {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(d.String())
+		sb.WriteByte('\n')
+		if d.Start >= 0 {
+			renderSnippet(&sb, source, d)
+		}
+	}
+	return sb.String()
+}
+
+// renderSnippet writes the lines of source covered by d, each followed by a
+// line of carets under the part of the span it holds. An empty span gets a
+// single caret.
+func renderSnippet(sb *strings.Builder, source string, d Diagnostic) {
+	last := d.EndLine
+	if last > d.Line && d.EndColumn == 1 {
+		last-- // The span ends with a line break
+	}
+	width := len(strconv.Itoa(last))
+	gutter := strings.Repeat(" ", width) + " |"
+	sb.WriteString(gutter + "\n")
+
+	lineStart := strings.LastIndexByte(source[:d.Start], '\n') + 1
+	for n := d.Line; n <= last; n++ {
+		lineEnd := len(source)
+		if i := strings.IndexByte(source[lineStart:], '\n'); i >= 0 {
+			lineEnd = lineStart + i
+		}
+		text := strings.TrimSuffix(source[lineStart:lineEnd], "\r")
+		fmt.Fprintf(sb, "%*d | %s\n", width, n, text)
+
+		from := min(max(d.Start, lineStart)-lineStart, len(text))
+		to := max(min(d.End, lineStart+len(text))-lineStart, from)
+		sb.WriteString(gutter + " ")
+		// Tabs are copied so that the carets line up in any tab width.
+		for _, r := range text[:from] {
+			if r == '\t' {
+				sb.WriteByte('\t')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(strings.Repeat("^", max(utf8.RuneCountInString(text[from:to]), 1)))
+		sb.WriteByte('\n')
+
+		lineStart = lineEnd + 1
+	}
+}
@@ -0,0 +1,204 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPosition(t *testing.T) {
+	source := "ab\ncdé\nf"
+	tests := []struct {
+		offset       int
+		line, column int
+	}{
+		{offset: 0, line: 1, column: 1},
+		{offset: 2, line: 1, column: 3},
+		{offset: 3, line: 2, column: 1},
+		{offset: 7, line: 2, column: 4},
+		{offset: 8, line: 3, column: 1},
+		{offset: 99, line: 3, column: 2},
+		{offset: -1, line: 1, column: 1},
+	}
+
+	for _, tt := range tests {
+		line, column := Position(source, tt.offset)
+		assert.Equal(t, tt.line, line, "offset %d", tt.offset)
+		assert.Equal(t, tt.column, column, "offset %d", tt.offset)
+	}
+}
+
+func located[E interface{ SetSpan(start, end int) }](err E, start, end int) E {
+	err.SetSpan(start, end)
+	return err
+}
+
+func TestNewDiagnostic(t *testing.T) {
+	end := 14
+	tests := []struct {
+		name   string
+		source string
+		err    error
+		want   Diagnostic
+		text   string
+	}{
+		{
+			name:   "syntax error drops the offset",
+			source: "Héllo {$name",
+			err:    NewMessageSyntaxError(ErrorTypeMissingSyntax, 13, &end, new("}")),
+			want: Diagnostic{
+				Kind: ErrorTypeMissingSyntax, Message: "missing }",
+				Start: 13, End: 13, Line: 1, Column: 13, EndLine: 1, EndColumn: 13,
+			},
+			text: "1:13: missing-syntax: missing }",
+		},
+		{
+			name:   "resolution error drops the kind",
+			source: "a\n{$x}",
+			err:    located(NewMessageResolutionError(ErrorTypeUnresolvedVariable, "variable not available: $x", "$x"), 2, 6),
+			want: Diagnostic{
+				Kind: ErrorTypeUnresolvedVariable, Message: "variable not available: $x",
+				Start: 2, End: 6, Line: 2, Column: 1, EndLine: 2, EndColumn: 5,
+			},
+			text: "2:1: unresolved-variable: variable not available: $x",
+		},
+		{
+			name:   "selection error message is its cause",
+			source: ".match $x * {{}}",
+			err:    located(NewBadSelectorError(errTestCause), 7, 9),
+			want: Diagnostic{
+				Kind: ErrorTypeBadSelector, Message: "test cause",
+				Start: 7, End: 9, Line: 1, Column: 8, EndLine: 1, EndColumn: 10,
+			},
+			text: "1:8: bad-selector: test cause",
+		},
+		{
+			name:   "unknown position",
+			source: "{$x}",
+			err:    NewBadOptionError("bad option", "$x"),
+			want:   Diagnostic{Kind: ErrorTypeBadOption, Message: "bad option", Start: -1, End: -1},
+			text:   "bad-option: bad option",
+		},
+		{
+			name:   "plain error",
+			source: "{$x}",
+			err:    errTestCause,
+			want:   Diagnostic{Message: "test cause", Start: -1, End: -1},
+			text:   "test cause",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiagnostic(tt.source, tt.err)
+			assert.Equal(t, tt.want, d)
+			assert.Equal(t, tt.text, d.String())
+		})
+	}
+}
+
+var errTestCause = errors.New("test cause")
+
+func TestDiagnoseJoinedErrors(t *testing.T) {
+	assert.Nil(t, Diagnose("x", nil))
+
+	err := errors.Join(
+		NewMessageSyntaxError(ErrorTypeEmptyToken, 1, nil, nil),
+		errors.Join(located(NewMessageFunctionError(ErrorTypeBadOperand, "not a number"), 0, 3)),
+	)
+	diagnostics := Diagnose("{$}", err)
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, "1:2: empty-token", diagnostics[0].String())
+	assert.Equal(t, "1:1: bad-operand: not a number", diagnostics[1].String())
+
+	data, jsonErr := json.Marshal(diagnostics[0])
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"kind":"empty-token","message":"empty-token","start":1,"end":2,"line":1,"column":2,"endLine":1,"endColumn":3}`, string(data))
+
+	data, jsonErr = json.Marshal(NewDiagnostic("", errTestCause))
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"message":"test cause","start":-1,"end":-1}`, string(data))
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    error
+		want   string
+	}{
+		{
+			name:   "caret under an empty span",
+			source: "Hello {$name",
+			err:    NewMessageSyntaxError(ErrorTypeMissingSyntax, 12, nil, new("}")),
+			want: "1:13: missing-syntax: missing }\n" +
+				"  |\n" +
+				"1 | Hello {$name\n" +
+				"  |             ^\n",
+		},
+		{
+			name:   "carets count characters and copy tabs",
+			source: "\té {$x :number}",
+			err:    located(NewBadOperandError("not a number", "$x"), 4, 16),
+			want: "1:4: bad-operand: not a number\n" +
+				"  |\n" +
+				"1 | \té {$x :number}\n" +
+				"  | \t  ^^^^^^^^^^^^\n",
+		},
+		{
+			name:   "span over several lines",
+			source: ".local $x = {\n$y\n}\r\n{{}}",
+			err:    located(NewMessageResolutionError(ErrorTypeUnresolvedVariable, "variable not available: $y", "$y"), 12, 18),
+			want: "1:13: unresolved-variable: variable not available: $y\n" +
+				"  |\n" +
+				"1 | .local $x = {\n" +
+				"  |             ^\n" +
+				"2 | $y\n" +
+				"  | ^^\n" +
+				"3 | }\n" +
+				"  | ^\n",
+		},
+		{
+			name:   "joined errors with and without positions",
+			source: "{$x}",
+			err: errors.Join(
+				NewBadOptionError("bad option", "$x"),
+				located(NewNoMatchError(nil), 0, 4),
+			),
+			want: "bad-option: bad option\n" +
+				"\n" +
+				"1:1: no-match\n" +
+				"  |\n" +
+				"1 | {$x}\n" +
+				"  | ^^^^\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.source, tt.err))
+		})
+	}
+}
+
+func TestSpans(t *testing.T) {
+	resolution := NewBadOperandError("bad", "$x")
+	start, end := resolution.Span()
+	assert.Equal(t, []int{-1, -1}, []int{start, end})
+	resolution.SetSpan(1, 3)
+	start, end = resolution.Span()
+	assert.Equal(t, []int{1, 3}, []int{start, end})
+
+	var spanner Spanner = NewNoMatchError(nil)
+	start, _ = spanner.Span()
+	assert.Equal(t, -1, start)
+	spanner = NewMessageFunctionError(ErrorTypeBadOperand, "bad")
+	start, _ = spanner.Span()
+	assert.Equal(t, -1, start)
+	spanner = NewMessageDataModelError(ErrorTypeDuplicateDeclaration, &mockNode{start: 4, end: 9})
+	start, end = spanner.Span()
+	assert.Equal(t, []int{4, 9}, []int{start, end})
+}
@@ -0,0 +1,20 @@
+package functions
+
+import "github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+
+// ValueAdapter converts a variable value of an application type to a
+// MessageValue, such as a money type to the NumberValue that CurrencyFunction
+// returns for its amount and currency code. ctx carries the locales of the
+// message and the variable reference, such as "$price", as its source.
+//
+// The adapted value is what both placeholders and function operands see, so
+// {$price} and {$price :currency} format the same value. A nil result is
+// reported as a bad-function-result error.
+type ValueAdapter func(ctx MessageFunctionContext, value any) messagevalue.MessageValue
+
+// MessageValuer is implemented by application types that convert themselves
+// to a MessageValue when read as a message variable. A ValueAdapter
+// registered for the exact type takes precedence.
+type MessageValuer interface {
+	MessageValue(ctx MessageFunctionContext) messagevalue.MessageValue
+}
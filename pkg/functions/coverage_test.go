@@ -1,6 +1,7 @@
 package functions
 
 import (
+	"context"
 	"errors"
 	"math"
 	"math/big"
@@ -41,6 +42,23 @@ func TestMessageFunctionContextCarriesMetadata(t *testing.T) {
 	ctx.OnError(errors.New("ignored"))
 }
 
+func TestMessageFunctionContextCarriesContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := NewMessageFunctionContext([]string{"en"}, "$value", "lookup", nil, nil, "", "")
+	assert.Equal(t, context.Background(), ctx.Context())
+
+	type key struct{}
+	parent := context.WithValue(t.Context(), key{}, "request")
+	withCtx := ctx.WithContext(parent)
+	assert.Equal(t, "request", withCtx.Context().Value(key{}))
+	assert.Equal(t, context.Background(), ctx.Context())
+	assert.Equal(t, "$value", withCtx.Source())
+
+	var missing context.Context
+	assert.Equal(t, context.Background(), ctx.WithContext(missing).Context())
+}
+
 func TestMessageFunctionContextOwnsCollections(t *testing.T) {
 	t.Parallel()
 
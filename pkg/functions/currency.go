@@ -109,14 +109,14 @@ func CurrencyFunction(
 	numericOperand, err := readNumericOperand(operand, source)
 	if err != nil {
 		ctx.OnError(err)
-		return messagevalue.NewFallbackValue(source, GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(source, ctx.Locale())
 	}
 
 	mergedOptions := make(map[string]any)
 	maps.Copy(mergedOptions, numericOperand.Options)
 	if existingStyle, ok := numericOperand.Options["style"]; ok && existingStyle == "percent" {
 		ctx.OnError(errors.NewBadOperandError("Cannot format a percent-formatted number as currency", source))
-		return messagevalue.NewFallbackValue(source, GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(source, ctx.Locale())
 	}
 	mergedOptions["localeMatcher"] = ctx.LocaleMatcher()
 	mergedOptions["style"] = "currency"
@@ -176,7 +176,7 @@ func CurrencyFunction(
 
 	if _, hasCurrency := mergedOptions["currency"]; !hasCurrency {
 		ctx.OnError(errors.NewBadOperandError("A currency code is required for :currency", source))
-		return messagevalue.NewFallbackValue(source, GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(source, ctx.Locale())
 	}
 
 	return getMessageNumber(ctx, numericOperand.Value, mergedOptions, false)
@@ -75,7 +75,7 @@ func dateTimeImplementation(
 	operand any,
 ) messagevalue.MessageValue {
 	source := ctx.Source()
-	locale := GetFirstLocale(ctx.Locales())
+	locale := ctx.Locale()
 
 	// Parse datetime value (matches TypeScript lines 94-112)
 	dateTime, err := parseDateTimeValue(operand)
@@ -0,0 +1,132 @@
+package functions
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/cockroachdb/apd/v3"
+)
+
+// Exact decimal operands are *apd.Decimal, json.Number, *big.Int, and
+// *big.Rat values, and numeric strings that a float64 cannot hold exactly.
+// They are passed on without a float64 step, so that 12345678901234567890
+// and 1.50 format and select with every digit they have.
+
+// normalizeDecimal returns an exact decimal operand in the form that the
+// number functions handle: *apd.Decimal for finite decimals, *big.Int for
+// integers, and *big.Rat for rationals without a finite decimal expansion.
+// Infinite and NaN decimals become float64 values, and nil pointers nil. It
+// reports false for values that are not exact decimals.
+func normalizeDecimal(value any) (any, bool) {
+	switch v := value.(type) {
+	case apd.Decimal:
+		return normalizeDecimal(&v)
+	case *apd.Decimal:
+		if v == nil {
+			return nil, true
+		}
+		switch v.Form {
+		case apd.Infinite:
+			if v.Negative {
+				return math.Inf(-1), true
+			}
+			return math.Inf(1), true
+		case apd.NaN, apd.NaNSignaling:
+			return math.NaN(), true
+		}
+		return v, true
+	case big.Int:
+		return &v, true
+	case *big.Int:
+		if v == nil {
+			return nil, true
+		}
+		return v, true
+	case big.Rat:
+		return normalizeDecimal(&v)
+	case *big.Rat:
+		if v == nil {
+			return nil, true
+		}
+		if digits, exact := v.FloatPrec(); exact {
+			d, _, err := apd.NewFromString(v.FloatString(digits))
+			return d, err == nil
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// exactJSONNumber returns s, a valid JSON number, as an *apd.Decimal when
+// f, its float64 value, loses any of its digits: those of a large integer,
+// or the trailing fraction zeros of a number like 1.50.
+func exactJSONNumber(s string, f float64) (*apd.Decimal, bool) {
+	d, _, err := apd.NewFromString(s)
+	if err != nil {
+		return nil, false
+	}
+	shortest, _, err := apd.NewFromString(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return nil, false
+	}
+	if d.Cmp(shortest) != 0 || min(d.Exponent, 0) != min(shortest.Exponent, 0) {
+		return d, true
+	}
+	return nil, false
+}
+
+// roundDecimal rounds an exact decimal operand to an integer, with halves
+// rounded away from zero like math.Round.
+func roundDecimal(value any) any {
+	switch v := value.(type) {
+	case *apd.Decimal:
+		if v.Exponent >= 0 {
+			return v
+		}
+		ctx := apd.BaseContext.WithPrecision(uint32(v.NumDigits()) + 1)
+		ctx.Rounding = apd.RoundHalfUp
+		rounded := new(apd.Decimal)
+		if _, err := ctx.Quantize(rounded, v, 0); err != nil {
+			return v
+		}
+		return rounded
+	case *big.Rat:
+		quotient, remainder := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+		if remainder.Lsh(remainder.Abs(remainder), 1).Cmp(v.Denom()) >= 0 {
+			quotient.Add(quotient, big.NewInt(int64(v.Sign())))
+		}
+		return quotient
+	}
+	return value
+}
+
+// addDecimal adds delta to an exact decimal operand without rounding.
+func addDecimal(value any, delta int) (any, bool) {
+	switch v := value.(type) {
+	case *big.Int:
+		return new(big.Int).Add(v, big.NewInt(int64(delta))), true
+	case *big.Rat:
+		return new(big.Rat).Add(v, big.NewRat(int64(delta), 1)), true
+	case *apd.Decimal:
+		sum := new(apd.Decimal)
+		if _, err := apd.BaseContext.Add(sum, v, apd.New(int64(delta), 0)); err != nil {
+			return nil, false
+		}
+		return sum, true
+	}
+	return nil, false
+}
+
+// numericText returns the text of a string or json.Number operand, which is
+// read as a JSON number.
+func numericText(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return string(v), true
+	}
+	return "", false
+}
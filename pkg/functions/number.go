@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/big"
 
+	"github.com/cockroachdb/apd/v3"
 	"github.com/go-json-experiment/json"
 
 	"github.com/kaptinlin/messageformat-go/pkg/bidi"
@@ -109,7 +110,7 @@ func readNumericOperand(value any, source string) (*NumericInput, error) {
 
 	// Parse string values as JSON numbers - matches TypeScript logic
 	// TypeScript: if (typeof value === 'string') { try { value = JSON.parse(value); } catch { } }
-	if str, ok := value.(string); ok {
+	if str, ok := numericText(value); ok {
 		if parsed, err := parseJSONNumber(str); err == nil {
 			value = parsed
 		} else {
@@ -123,11 +124,14 @@ func readNumericOperand(value any, source string) (*NumericInput, error) {
 
 	// Validate numeric type - matches TypeScript logic
 	// TypeScript: if (typeof value !== 'bigint' && typeof value !== 'number') { throw ... }
+	if decimal, ok := normalizeDecimal(value); ok {
+		value = decimal
+	}
 	switch value.(type) {
 	case int, int8, int16, int32, int64:
 	case uint, uint8, uint16, uint32, uint64:
 	case float32, float64:
-	case *big.Int, *big.Float:
+	case *big.Int, *big.Float, *apd.Decimal, *big.Rat:
 	default:
 		return nil, pkgErrors.NewMessageResolutionError(
 			pkgErrors.ErrorTypeBadOperand,
@@ -217,14 +221,14 @@ func NumberFunction(
 	numInput, err := readNumericOperand(operand, ctx.Source())
 	if err != nil {
 		ctx.OnError(err)
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	// Start with operand options and set defaults - matches TypeScript Object.assign
 	mergedOptions := mergeNumberOptions(numInput.Options, nil, ctx.LocaleMatcher())
 	if existingStyle, ok := numInput.Options["style"]; ok && existingStyle != "decimal" {
 		ctx.OnError(pkgErrors.NewBadOperandError("Cannot format a non-decimal number as :number", ctx.Source()))
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	// Process expression options - matches TypeScript for loop
@@ -255,12 +259,12 @@ func NumberFunction(
 			if strVal, err := asString(optval); err != nil || strVal != "decimal" {
 				msg := fmt.Sprintf("Value %v is not valid for :number option %s", optval, name)
 				ctx.OnError(pkgErrors.NewBadOptionError(msg, ctx.Source()))
-				return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+				return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 			}
 		case "currency", "currencyDisplay", "currencySign":
 			msg := fmt.Sprintf("Value %v is not valid for :number option %s", optval, name)
 			ctx.OnError(pkgErrors.NewBadOptionError(msg, ctx.Source()))
-			return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+			return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 		default:
 			// Unknown option - silently ignore to match TypeScript behavior
 		}
@@ -317,7 +321,7 @@ func IntegerFunction(
 	numInput, err := readNumericOperand(operand, ctx.Source())
 	if err != nil {
 		ctx.OnError(err)
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	// Round to integer - matches TypeScript: Number.isFinite(input.value) ? Math.round(input.value as number) : input.value;
@@ -342,6 +346,8 @@ func IntegerFunction(
 			intVal, _ := v.Int64()
 			value = intVal
 		}
+	case *apd.Decimal, *big.Rat:
+		value = roundDecimal(v)
 	default:
 		value = numInput.Value
 	}
@@ -420,8 +426,8 @@ func getMessageNumber(
 		}
 	}
 
-	// Get first locale - matches TypeScript locale handling
-	locale := GetFirstLocale(ctx.Locales())
+	// Negotiate the locale with data - matches Intl.NumberFormat locale resolution
+	locale := ctx.Locale()
 
 	// Determine direction - matches TypeScript: let { dir, locales } = ctx;
 	dir := ctx.Dir()
@@ -483,6 +489,10 @@ func parseJSONNumber(s string) (any, error) {
 
 	// JSON.Unmarshal only returns float64 for numbers
 	if floatVal, ok := jsonVal.(float64); ok {
+		// Keep the digits that a float64 would lose
+		if d, ok := exactJSONNumber(s, floatVal); ok {
+			return d, nil
+		}
 		// Check if it's actually an integer value
 		if floatVal == float64(int64(floatVal)) && floatVal >= float64(math.MinInt64) && floatVal <= float64(math.MaxInt64) {
 			return int64(floatVal), nil
@@ -1,12 +1,17 @@
 package functions
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
 	"testing"
 
+	"github.com/cockroachdb/apd/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	pkgerrors "github.com/kaptinlin/messageformat-go/pkg/errors"
 )
 
 func TestReadNumericOperand(t *testing.T) {
@@ -553,3 +558,96 @@ func TestNumberTypeScriptCompatibility(t *testing.T) {
 		assert.Contains(t, result.Type(), "fallback")
 	})
 }
+
+func TestReadNumericOperandExactDecimals(t *testing.T) {
+	huge, ok := new(big.Int).SetString("12345678901234567890", 10)
+	require.True(t, ok)
+	var nilDecimal *apd.Decimal
+
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{"large integer string", "12345678901234567890", "12345678901234567890"},
+		{"fraction zeros", "1.50", "1.50"},
+		{"json.Number", json.Number("0.10"), "0.10"},
+		{"apd value", *apd.New(-25, -1), "-2.5"},
+		{"big.Int value", *huge, "12345678901234567890"},
+		{"finite rational", big.NewRat(1, 8), "0.125"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := readNumericOperand(tt.input, "test")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprint(result.Value))
+		})
+	}
+
+	result, err := readNumericOperand(big.NewRat(1, 3), "test")
+	require.NoError(t, err)
+	assert.IsType(t, &big.Rat{}, result.Value)
+
+	result, err = readNumericOperand(&apd.Decimal{Form: apd.Infinite, Negative: true}, "test")
+	require.NoError(t, err)
+	assert.Equal(t, math.Inf(-1), result.Value)
+
+	_, err = readNumericOperand(nilDecimal, "test")
+	assertResolutionErrorType(t, err, pkgerrors.ErrorTypeBadOperand)
+}
+
+func TestParseJSONNumberKeepsDigits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"42", "int64 42"},
+		{"1e3", "int64 1000"},
+		{"0.5", "float64 0.5"},
+		{"1e-7", "float64 1e-07"},
+		{"1.0", "*apd.Decimal 1.0"},
+		{"9007199254740993", "*apd.Decimal 9007199254740993"},
+		{"0.30000000000000000001", "*apd.Decimal 0.30000000000000000001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := parseJSONNumber(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprintf("%T %v", result, result))
+		})
+	}
+}
+
+func TestIntegerFunctionExactDecimals(t *testing.T) {
+	ctx := newTestContext(nil)
+
+	tests := []struct {
+		name     string
+		operand  any
+		expected string
+	}{
+		{"half away from zero", apd.New(25, -1), "3"},
+		{"negative half", apd.New(-25, -1), "-3"},
+		{"large decimal", mustParseDecimal(t, "12345678901234567890.4"), "12345678901234567890"},
+		{"infinite rational", big.NewRat(-5, 3), "-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IntegerFunction(ctx, Options{"useGrouping": "never"}, tt.operand)
+			value, err := result.ValueOf()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprint(value))
+		})
+	}
+}
+
+func mustParseDecimal(t *testing.T, s string) *apd.Decimal {
+	t.Helper()
+
+	d, _, err := apd.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
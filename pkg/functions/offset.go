@@ -55,7 +55,7 @@ func OffsetFunction(
 	numInput, err := readNumericOperand(operand, ctx.Source())
 	if err != nil {
 		ctx.OnError(err)
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	value := numInput.Value
@@ -68,7 +68,7 @@ func OffsetFunction(
 		} else {
 			msg := fmt.Sprintf("Value %v is not valid for :offset option add", addVal)
 			ctx.OnError(pkgErrors.NewBadOptionError(msg, ctx.Source()))
-			return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+			return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 		}
 	}
 
@@ -80,7 +80,7 @@ func OffsetFunction(
 		} else {
 			msg := fmt.Sprintf("Value %v is not valid for :offset option subtract", subVal)
 			ctx.OnError(pkgErrors.NewBadOptionError(msg, ctx.Source()))
-			return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+			return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 		}
 	}
 
@@ -88,7 +88,7 @@ func OffsetFunction(
 	if (add < 0) == (sub < 0) {
 		msg := "Exactly one of \"add\" or \"subtract\" is required as an :offset option"
 		ctx.OnError(pkgErrors.NewBadOptionError(msg, ctx.Source()))
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	// Calculate delta - matches TypeScript: const delta = add < 0 ? -sub : add;
@@ -113,13 +113,16 @@ func OffsetFunction(
 		deltaFloat := big.NewFloat(float64(delta))
 		value = new(big.Float).Add(v, deltaFloat)
 	default:
-		// For other numeric types, try to convert to float64 and add delta
-		if floatVal, ok := convertToFloat64(value); ok {
+		// For exact decimals, add delta exactly; for other numeric types, try
+		// to convert to float64 and add delta
+		if sum, ok := addDecimal(value, delta); ok {
+			value = sum
+		} else if floatVal, ok := convertToFloat64(value); ok {
 			value = floatVal + float64(delta)
 		} else {
 			msg := fmt.Sprintf("Cannot apply offset to value of type %T", value)
 			ctx.OnError(pkgErrors.NewBadOperandError(msg, ctx.Source()))
-			return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+			return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 		}
 	}
 
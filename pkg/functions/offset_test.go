@@ -1,6 +1,7 @@
 package functions
 
 import (
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -257,3 +258,29 @@ func TestOffsetFunctionEdgeCases(t *testing.T) {
 		assert.Empty(t, errors)
 	})
 }
+
+func TestOffsetFunctionExactDecimals(t *testing.T) {
+	ctx := newTestContext(nil)
+	huge, ok := new(big.Int).SetString("12345678901234567890", 10)
+	require.True(t, ok)
+
+	tests := []struct {
+		name     string
+		operand  any
+		options  Options
+		expected string
+	}{
+		{"decimal", "0.10", Options{"add": 1}, "1.10"},
+		{"large integer", huge, Options{"subtract": 1}, "12345678901234567889"},
+		{"rational", big.NewRat(1, 3), Options{"add": 1}, "4/3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := OffsetFunction(ctx, tt.options, tt.operand)
+			value, err := result.ValueOf()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fmt.Sprint(value))
+		})
+	}
+}
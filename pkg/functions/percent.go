@@ -75,12 +75,12 @@ func PercentFunction(
 	numInput, err := readNumericOperand(operand, ctx.Source())
 	if err != nil {
 		ctx.OnError(err)
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	if existingStyle, ok := numInput.Options["style"]; ok && existingStyle == "currency" {
 		ctx.OnError(pkgErrors.NewBadOperandError("Cannot format a currency-formatted number as percent", ctx.Source()))
-		return messagevalue.NewFallbackValue(ctx.Source(), GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(ctx.Source(), ctx.Locale())
 	}
 
 	// Start with operand options and set defaults - matches TypeScript Object.assign
@@ -0,0 +1,182 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// integerRegex matches integer literals without a plus sign or leading zeros.
+var integerRegex = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// Schema check errors.
+var (
+	// ErrInvalidLiteral indicates a literal option or operand value that a
+	// function schema does not accept.
+	ErrInvalidLiteral = errors.New("invalid literal value")
+
+	// ErrUnknownOption indicates an option that a function schema does not
+	// list.
+	ErrUnknownOption = errors.New("unknown option")
+
+	// ErrNotLiteral indicates a variable given to a literal-only option.
+	ErrNotLiteral = errors.New("variable value for literal-only option")
+
+	// ErrMissingOperand indicates a call without the operand that a function
+	// schema requires.
+	ErrMissingOperand = errors.New("missing operand")
+)
+
+// ValueType is the type of literal value an option or operand accepts.
+type ValueType string
+
+// Value types of a schema.
+const (
+	ValueAny     ValueType = ""        // Any literal
+	ValueString  ValueType = "string"  // Any literal, read as a string
+	ValueNumber  ValueType = "number"  // A JSON number, such as -1.5e3
+	ValueInteger ValueType = "integer" // An integer within the option's bounds
+	ValueBoolean ValueType = "boolean" // true or false
+)
+
+// Schema describes the operand and options of a MessageFunction, so that
+// literal values can be checked when a message is compiled and tools can
+// document and complete them. Option names starting with "u:" are
+// MessageFormat options and are never part of a schema.
+type Schema struct {
+	// One-sentence description of the function
+	Summary string
+
+	// Operand the function expects
+	Operand OperandSchema
+
+	// Options the function reads, in documentation order
+	Options []OptionSchema
+}
+
+// OperandSchema describes the operand of a function.
+type OperandSchema struct {
+	// The function reports an error without an operand
+	Required bool
+
+	// Type of a literal operand
+	Type ValueType
+}
+
+// OptionSchema describes one option of a function.
+type OptionSchema struct {
+	// Option name
+	Name string
+
+	// Type of a literal value
+	Type ValueType
+
+	// Keyword values. A string option with values accepts only them; other
+	// types accept them besides values of the type.
+	Values []string
+
+	// Inclusive bounds of integer values. Integers are non-negative by
+	// default, and a zero Max leaves them unbounded above.
+	Min, Max int
+
+	// The value must be a literal; a variable is an error
+	LiteralOnly bool
+
+	// One-sentence description of the option
+	Doc string
+}
+
+// Option returns the schema of the named option.
+func (s Schema) Option(name string) (OptionSchema, bool) {
+	i := slices.IndexFunc(s.Options, func(o OptionSchema) bool { return o.Name == name })
+	if i < 0 {
+		return OptionSchema{}, false
+	}
+	return s.Options[i], true
+}
+
+// OptionNames returns the names of the options in documentation order.
+func (s Schema) OptionNames() []string {
+	names := make([]string, len(s.Options))
+	for i, option := range s.Options {
+		names[i] = option.Name
+	}
+	return names
+}
+
+// CheckLiteral reports an error wrapping ErrInvalidLiteral when value is not
+// a valid literal for the option.
+func (o OptionSchema) CheckLiteral(value string) error {
+	if slices.Contains(o.Values, value) {
+		return nil
+	}
+	if len(o.Values) > 0 && o.Type == ValueString {
+		return fmt.Errorf("%w: %q is not valid for option %s", ErrInvalidLiteral, value, o.Name)
+	}
+	if err := checkLiteral(o.Type, value, o.Min, o.Max); err != nil {
+		return fmt.Errorf("%w for option %s", err, o.Name)
+	}
+	return nil
+}
+
+// CheckLiteral reports an error wrapping ErrInvalidLiteral when value is not
+// a valid literal operand.
+func (o OperandSchema) CheckLiteral(value string) error {
+	if err := checkLiteral(o.Type, value, 0, 0); err != nil {
+		return fmt.Errorf("%w for the operand", err)
+	}
+	return nil
+}
+
+// checkLiteral checks a literal against a value type.
+func checkLiteral(typ ValueType, value string, lower, upper int) error {
+	switch typ {
+	case ValueNumber:
+		if _, err := parseJSONNumber(value); err != nil {
+			return fmt.Errorf("%w: %q is not a number", ErrInvalidLiteral, value)
+		}
+	case ValueInteger:
+		n, err := strconv.Atoi(value)
+		if !integerRegex.MatchString(value) || err != nil || n < lower || upper != 0 && n > upper {
+			if upper != 0 {
+				return fmt.Errorf("%w: %q is not an integer from %d to %d", ErrInvalidLiteral, value, lower, upper)
+			}
+			return fmt.Errorf("%w: %q is not an integer from %d", ErrInvalidLiteral, value, lower)
+		}
+	case ValueBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%w: %q is not true or false", ErrInvalidLiteral, value)
+		}
+	case ValueAny, ValueString:
+	}
+	return nil
+}
+
+// DefaultSchemaMap returns a snapshot of the schemas of the functions in
+// DefaultFunctionMap, by function name.
+func DefaultSchemaMap() map[string]Schema {
+	return cloneSchemas(defaultSchemas)
+}
+
+// DraftSchemaMap returns a snapshot of the schemas of the functions in
+// DraftFunctionMap, by function name.
+func DraftSchemaMap() map[string]Schema {
+	return cloneSchemas(draftSchemas)
+}
+
+// cloneSchemas copies schemas deeply enough that callers cannot change the
+// package tables.
+func cloneSchemas(schemas map[string]Schema) map[string]Schema {
+	result := maps.Clone(schemas)
+	for name, schema := range result {
+		schema.Options = slices.Clone(schema.Options)
+		for i := range schema.Options {
+			schema.Options[i].Values = slices.Clone(schema.Options[i].Values)
+		}
+		result[name] = schema
+	}
+	return result
+}
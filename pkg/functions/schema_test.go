@@ -0,0 +1,136 @@
+package functions
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemasDescribeBuiltinFunctions(t *testing.T) {
+	assert.ElementsMatch(t,
+		slices.Collect(maps.Keys(DefaultFunctionMap())),
+		slices.Collect(maps.Keys(DefaultSchemaMap())))
+	assert.ElementsMatch(t,
+		slices.Collect(maps.Keys(DraftFunctionMap())),
+		slices.Collect(maps.Keys(DraftSchemaMap())))
+
+	for name, schema := range DefaultSchemaMap() {
+		assert.NotEmpty(t, schema.Summary, name)
+		for _, option := range schema.Options {
+			assert.NotEmpty(t, option.Doc, "%s option %s", name, option.Name)
+		}
+	}
+}
+
+func TestSchemaKeywordsMatchDateTimeOptions(t *testing.T) {
+	schemas := DraftSchemaMap()
+	tests := []struct {
+		function, option string
+		values           map[string]bool
+	}{
+		{function: "date", option: "fields", values: dateFieldsValues},
+		{function: "date", option: "length", values: dateLengthValues},
+		{function: "datetime", option: "dateFields", values: dateFieldsValues},
+		{function: "datetime", option: "dateLength", values: dateLengthValues},
+		{function: "datetime", option: "timePrecision", values: timePrecisionValues},
+		{function: "datetime", option: "timeZoneStyle", values: timeZoneStyleValues},
+		{function: "time", option: "precision", values: timePrecisionValues},
+		{function: "time", option: "timeZoneStyle", values: timeZoneStyleValues},
+	}
+
+	for _, tt := range tests {
+		option, ok := schemas[tt.function].Option(tt.option)
+		require.True(t, ok, "%s option %s", tt.function, tt.option)
+		assert.ElementsMatch(t, slices.Collect(maps.Keys(tt.values)), option.Values, "%s option %s", tt.function, tt.option)
+	}
+}
+
+func TestSchemaMapsReturnSnapshots(t *testing.T) {
+	defaults := DefaultSchemaMap()
+	number := defaults["number"]
+	number.Options[0].Name = "changed"
+	signDisplay, ok := number.Option("signDisplay")
+	require.True(t, ok)
+	signDisplay.Values[0] = "changed"
+	delete(defaults, "string")
+
+	fresh := DefaultSchemaMap()
+	assert.Contains(t, fresh, "string")
+	assert.Equal(t, "maximumFractionDigits", fresh["number"].Options[0].Name)
+	signDisplay, _ = fresh["number"].Option("signDisplay")
+	assert.Equal(t, "auto", signDisplay.Values[0])
+}
+
+func TestOptionSchemaCheckLiteral(t *testing.T) {
+	tests := []struct {
+		name   string
+		option OptionSchema
+		value  string
+		want   string
+	}{
+		{name: "any string", option: OptionSchema{Name: "o", Type: ValueString}, value: "x"},
+		{name: "keyword", option: signDisplayOption, value: "never"},
+		{
+			name:   "unknown keyword",
+			option: signDisplayOption, value: "sometimes",
+			want: `invalid literal value: "sometimes" is not valid for option signDisplay`,
+		},
+		{name: "integer in bounds", option: maximumSignificantDigitsOption, value: "21"},
+		{
+			name:   "integer out of bounds",
+			option: maximumSignificantDigitsOption, value: "0",
+			want: `invalid literal value: "0" is not an integer from 1 to 21 for option maximumSignificantDigits`,
+		},
+		{
+			name:   "integer with leading zero",
+			option: minimumFractionDigitsOption, value: "02",
+			want: `invalid literal value: "02" is not an integer from 0 to 100 for option minimumFractionDigits`,
+		},
+		{
+			name:   "unbounded integer",
+			option: OptionSchema{Name: "add", Type: ValueInteger}, value: "1000000",
+		},
+		{
+			name:   "negative integer",
+			option: OptionSchema{Name: "add", Type: ValueInteger}, value: "-1",
+			want: `invalid literal value: "-1" is not an integer from 0 for option add`,
+		},
+		{
+			name:   "integer keyword",
+			option: OptionSchema{Name: "fractionDigits", Type: ValueInteger, Values: []string{"auto"}}, value: "auto",
+		},
+		{name: "boolean", option: hour12Option, value: "false"},
+		{
+			name:   "not a boolean",
+			option: hour12Option, value: "yes",
+			want: `invalid literal value: "yes" is not true or false for option hour12`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.option.CheckLiteral(tt.value)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidLiteral))
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestOperandSchemaCheckLiteral(t *testing.T) {
+	assert.NoError(t, numericOperand.CheckLiteral("-1.5e3"))
+	assert.NoError(t, dateTimeOperand.CheckLiteral("2006-01-02"))
+	assert.NoError(t, OperandSchema{}.CheckLiteral("anything"))
+
+	err := numericOperand.CheckLiteral("1,5")
+	assert.ErrorIs(t, err, ErrInvalidLiteral)
+	assert.EqualError(t, err, `invalid literal value: "1,5" is not a number for the operand`)
+}
@@ -0,0 +1,263 @@
+package functions
+
+// Options, keywords, and operands shared by built-in functions. An option
+// means the same thing for every function that reads it.
+var (
+	calendarOption = OptionSchema{
+		Name: "calendar", Type: ValueString,
+		Doc: "Calendar to use, such as `gregory` or `japanese`.",
+	}
+	dateFieldsKeywords = []string{"weekday", "day-weekday", "month-day", "month-day-weekday", "year-month-day", "year-month-day-weekday"}
+	dateLengthKeywords = []string{"long", "medium", "short"}
+	hour12Option       = OptionSchema{
+		Name: "hour12", Type: ValueBoolean,
+		Doc: "`true` for a 12-hour clock, `false` for a 24-hour clock.",
+	}
+	maximumFractionDigitsOption = OptionSchema{
+		Name: "maximumFractionDigits", Type: ValueInteger, Max: 100,
+		Doc: "Maximum number of fraction digits, from 0 to 100.",
+	}
+	maximumSignificantDigitsOption = OptionSchema{
+		Name: "maximumSignificantDigits", Type: ValueInteger, Min: 1, Max: 21,
+		Doc: "Maximum number of significant digits, from 1 to 21.",
+	}
+	minimumFractionDigitsOption = OptionSchema{
+		Name: "minimumFractionDigits", Type: ValueInteger, Max: 100,
+		Doc: "Minimum number of fraction digits, from 0 to 100.",
+	}
+	minimumIntegerDigitsOption = OptionSchema{
+		Name: "minimumIntegerDigits", Type: ValueInteger, Min: 1, Max: 21,
+		Doc: "Minimum number of integer digits, from 1 to 21.",
+	}
+	minimumSignificantDigitsOption = OptionSchema{
+		Name: "minimumSignificantDigits", Type: ValueInteger, Min: 1, Max: 21,
+		Doc: "Minimum number of significant digits, from 1 to 21.",
+	}
+	numberingSystemOption = OptionSchema{
+		Name: "numberingSystem", Type: ValueString,
+		Doc: "Numbering system for digits, such as `latn` or `arab`.",
+	}
+	roundingIncrementOption = OptionSchema{
+		Name: "roundingIncrement", Type: ValueString,
+		Values: []string{"1", "2", "5", "10", "20", "25", "50", "100", "200", "250", "500", "1000", "2000", "2500", "5000"},
+		Doc:    "Rounding increment: 1, 2, 5, 10, 20, 25, 50, 100, 200, 250, 500, 1000, 2000, 2500, or 5000.",
+	}
+	roundingModeOption = OptionSchema{
+		Name: "roundingMode", Type: ValueString,
+		Values: []string{"ceil", "floor", "expand", "trunc", "halfCeil", "halfFloor", "halfExpand", "halfTrunc", "halfEven"},
+		Doc:    "Rounding mode, such as `halfExpand`, `halfEven`, `ceil`, `floor`, or `trunc`.",
+	}
+	roundingPriorityOption = OptionSchema{
+		Name: "roundingPriority", Type: ValueString,
+		Values: []string{"auto", "morePrecision", "lessPrecision"},
+		Doc:    "`auto`, `morePrecision`, or `lessPrecision` when both fraction and significant digits are set.",
+	}
+	selectOption = OptionSchema{
+		Name: "select", Type: ValueString, LiteralOnly: true,
+		Values: []string{"plural", "ordinal", "exact"},
+		Doc:    "Selection by `plural` category, `ordinal` category, or `exact` value.",
+	}
+	signDisplayOption = OptionSchema{
+		Name: "signDisplay", Type: ValueString,
+		Values: []string{"auto", "always", "exceptZero", "negative", "never"},
+		Doc:    "When to show the sign: `auto`, `always`, `exceptZero`, `negative`, or `never`.",
+	}
+	timePrecisionKeywords = []string{"hour", "minute", "second"}
+	timeZoneOption        = OptionSchema{
+		Name: "timeZone", Type: ValueString,
+		Doc: "IANA time zone, such as `Europe/Paris`, or `input` for the operand's own zone.",
+	}
+	timeZoneStyleOption = OptionSchema{
+		Name: "timeZoneStyle", Type: ValueString,
+		Values: []string{"long", "short"},
+		Doc:    "Time zone name to show: `long` or `short`.",
+	}
+	trailingZeroDisplayOption = OptionSchema{
+		Name: "trailingZeroDisplay", Type: ValueString,
+		Values: []string{"auto", "stripIfInteger"},
+		Doc:    "`auto`, or `stripIfInteger` to hide the fraction of whole numbers.",
+	}
+	useGroupingOption = OptionSchema{
+		Name: "useGrouping", Type: ValueString,
+		Values: []string{"auto", "always", "never", "min2"},
+		Doc:    "Grouping separators: `auto`, `always`, `min2`, or `never`.",
+	}
+
+	numericOperand  = OperandSchema{Required: true, Type: ValueNumber}
+	dateTimeOperand = OperandSchema{Required: true, Type: ValueString}
+)
+
+// defaultSchemas describes the functions of defaultFunctions.
+var defaultSchemas = map[string]Schema{
+	"currency": {
+		Summary: "Formats a number as an amount of money in the currency given by the operand or the currency option.",
+		Operand: numericOperand,
+		Options: []OptionSchema{
+			{Name: "currency", Type: ValueString, Doc: "ISO 4217 currency code, such as `EUR`."},
+			{
+				Name: "currencyDisplay", Type: ValueString,
+				Values: []string{"narrowSymbol", "symbol", "name", "code", "formalSymbol", "never"},
+				Doc:    "How to show the currency: `narrowSymbol`, `symbol`, `name`, `code`, or `formalSymbol`.",
+			},
+			{
+				Name: "currencySign", Type: ValueString,
+				Values: []string{"standard", "accounting"},
+				Doc:    "`standard`, or `accounting` to show negative amounts in parentheses.",
+			},
+			{
+				Name: "fractionDigits", Type: ValueInteger, Max: 100, Values: []string{"auto"},
+				Doc: "Number of fraction digits, or `auto` for the currency's default.",
+			},
+			maximumSignificantDigitsOption,
+			minimumIntegerDigitsOption,
+			minimumSignificantDigitsOption,
+			roundingIncrementOption,
+			roundingModeOption,
+			roundingPriorityOption,
+			trailingZeroDisplayOption,
+			useGroupingOption,
+		},
+	},
+	"integer": {
+		Summary: "Formats a number rounded to an integer, and selects by its plural category.",
+		Operand: numericOperand,
+		Options: []OptionSchema{
+			maximumSignificantDigitsOption,
+			minimumIntegerDigitsOption,
+			selectOption,
+			signDisplayOption,
+			useGroupingOption,
+		},
+	},
+	"number": {
+		Summary: "Formats a number, and selects by its plural category.",
+		Operand: numericOperand,
+		Options: []OptionSchema{
+			maximumFractionDigitsOption,
+			maximumSignificantDigitsOption,
+			minimumFractionDigitsOption,
+			minimumIntegerDigitsOption,
+			minimumSignificantDigitsOption,
+			numberingSystemOption,
+			roundingIncrementOption,
+			roundingModeOption,
+			roundingPriorityOption,
+			selectOption,
+			signDisplayOption,
+			{
+				Name: "style", Type: ValueString, Values: []string{"decimal"},
+				Doc: "Only `decimal`; use :percent for percentages.",
+			},
+			trailingZeroDisplayOption,
+			useGroupingOption,
+		},
+	},
+	"offset": {
+		Summary: "Adds an integer to or subtracts one from a number, keeping its formatting options.",
+		Operand: numericOperand,
+		Options: []OptionSchema{
+			{Name: "add", Type: ValueInteger, Doc: "Integer to add to the operand."},
+			{Name: "subtract", Type: ValueInteger, Doc: "Integer to subtract from the operand."},
+		},
+	},
+	"percent": {
+		Summary: "Formats a number as a percentage, where 1 is 100%.",
+		Operand: numericOperand,
+		Options: []OptionSchema{
+			maximumFractionDigitsOption,
+			maximumSignificantDigitsOption,
+			minimumFractionDigitsOption,
+			minimumSignificantDigitsOption,
+			roundingModeOption,
+			roundingPriorityOption,
+			signDisplayOption,
+			trailingZeroDisplayOption,
+			useGroupingOption,
+		},
+	},
+	"string": {
+		Summary: "Formats a value as a string, and selects by exact string match.",
+	},
+}
+
+// draftSchemas describes the functions of draftFunctions.
+var draftSchemas = map[string]Schema{
+	"date": {
+		Summary: "Formats the date of a date/time value.",
+		Operand: dateTimeOperand,
+		Options: []OptionSchema{
+			calendarOption,
+			{
+				Name: "fields", Type: ValueString, Values: dateFieldsKeywords,
+				Doc: "Date fields to show: `weekday`, `day-weekday`, `month-day`, `month-day-weekday`, `year-month-day`, or `year-month-day-weekday`.",
+			},
+			{
+				Name: "length", Type: ValueString, Values: dateLengthKeywords,
+				Doc: "Length of the date: `long`, `medium`, or `short`.",
+			},
+			numberingSystemOption,
+			timeZoneOption,
+		},
+	},
+	"datetime": {
+		Summary: "Formats the date and time of a date/time value.",
+		Operand: dateTimeOperand,
+		Options: []OptionSchema{
+			calendarOption,
+			{
+				Name: "dateFields", Type: ValueString, Values: dateFieldsKeywords,
+				Doc: "Date fields to show: `weekday`, `day-weekday`, `month-day`, `month-day-weekday`, `year-month-day`, or `year-month-day-weekday`.",
+			},
+			{
+				Name: "dateLength", Type: ValueString, Values: dateLengthKeywords,
+				Doc: "Length of the date: `long`, `medium`, or `short`.",
+			},
+			hour12Option,
+			numberingSystemOption,
+			{
+				Name: "timePrecision", Type: ValueString, Values: timePrecisionKeywords,
+				Doc: "Precision of the time: `hour`, `minute`, or `second`.",
+			},
+			timeZoneOption,
+			timeZoneStyleOption,
+		},
+	},
+	"time": {
+		Summary: "Formats the time of a date/time value.",
+		Operand: dateTimeOperand,
+		Options: []OptionSchema{
+			calendarOption,
+			hour12Option,
+			numberingSystemOption,
+			{
+				Name: "precision", Type: ValueString, Values: timePrecisionKeywords,
+				Doc: "Precision of the time: `hour`, `minute`, or `second`.",
+			},
+			timeZoneOption,
+			timeZoneStyleOption,
+		},
+	},
+	"unit": {
+		Summary: "Formats a number with a unit of measurement.",
+		Operand: numericOperand,
+		Options: []OptionSchema{
+			maximumFractionDigitsOption,
+			maximumSignificantDigitsOption,
+			minimumFractionDigitsOption,
+			minimumIntegerDigitsOption,
+			minimumSignificantDigitsOption,
+			roundingIncrementOption,
+			roundingModeOption,
+			roundingPriorityOption,
+			signDisplayOption,
+			trailingZeroDisplayOption,
+			{Name: "unit", Type: ValueString, Doc: "Unit of measurement, such as `kilometer` or `kilometer-per-hour`."},
+			{
+				Name: "unitDisplay", Type: ValueString,
+				Values: []string{"short", "narrow", "long"},
+				Doc:    "How to show the unit: `short`, `narrow`, or `long`.",
+			},
+			useGroupingOption,
+		},
+	},
+}
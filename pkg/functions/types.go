@@ -3,6 +3,7 @@
 package functions
 
 import (
+	"context"
 	"maps"
 	"slices"
 
@@ -52,11 +53,17 @@ type MessageFunctionContext struct {
 	// Locale matcher strategy
 	localeMatcher string
 
+	// Negotiated locale, or "" to negotiate on each call of Locale
+	locale string
+
 	// Error handler
 	onError func(error)
 
 	// Set of literal option keys
 	literalOptionKeys map[string]bool
+
+	// Context of the formatting call
+	ctx context.Context
 }
 
 // NewMessageFunctionContext creates a new function context
@@ -69,10 +76,6 @@ func NewMessageFunctionContext(
 	dir string,
 	id string,
 ) MessageFunctionContext {
-	if literalOptionKeys == nil {
-		literalOptionKeys = make(map[string]bool)
-	}
-
 	return MessageFunctionContext{
 		dir:               dir,
 		id:                id,
@@ -118,5 +121,51 @@ func (ctx MessageFunctionContext) OnError(err error) {
 
 // LiteralOptionKeys returns the set of literal option keys
 func (ctx MessageFunctionContext) LiteralOptionKeys() map[string]bool {
+	if len(ctx.literalOptionKeys) == 0 {
+		return make(map[string]bool)
+	}
 	return maps.Clone(ctx.literalOptionKeys)
 }
+
+// Context returns the context of the formatting call.
+// It carries deadlines, cancellation, and request-scoped values passed to
+// FormatContext or FormatToPartsContext, and is never nil.
+func (ctx MessageFunctionContext) Context() context.Context {
+	if ctx.ctx == nil {
+		return context.Background()
+	}
+	return ctx.ctx
+}
+
+// Locale returns the locale that formatting functions use: the first of
+// Locales that has locale data, negotiated with LocaleMatcher, unless
+// WithLocale set it. See NegotiateLocale.
+func (ctx MessageFunctionContext) Locale() string {
+	if ctx.locale != "" {
+		return ctx.locale
+	}
+	return NegotiateLocale(ctx.locales, ctx.localeMatcher)
+}
+
+// WithLocale returns a copy of the function context whose Locale is locale,
+// already negotiated from Locales, so that it is not negotiated again.
+func (ctx MessageFunctionContext) WithLocale(locale string) MessageFunctionContext {
+	ctx.locale = locale
+	return ctx
+}
+
+// WithOnError returns a copy of the function context that reports errors to onError.
+func (ctx MessageFunctionContext) WithOnError(onError func(error)) MessageFunctionContext {
+	ctx.onError = onError
+	return ctx
+}
+
+// WithContext returns a copy of the function context that carries c.
+// A nil c is replaced with context.Background().
+func (ctx MessageFunctionContext) WithContext(c context.Context) MessageFunctionContext {
+	if c == nil {
+		c = context.Background()
+	}
+	ctx.ctx = c
+	return ctx
+}
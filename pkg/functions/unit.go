@@ -75,7 +75,7 @@ func UnitFunction(
 	numericOperand, err := readNumericOperand(operand, source)
 	if err != nil {
 		ctx.OnError(err)
-		return messagevalue.NewFallbackValue(source, GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(source, ctx.Locale())
 	}
 
 	// Start with operand options and set unit style
@@ -113,7 +113,7 @@ func UnitFunction(
 	if _, hasUnit := mergedOptions["unit"]; !hasUnit {
 		msg := "A unit identifier is required for :unit"
 		ctx.OnError(pkgErrors.NewBadOperandError(msg, source))
-		return messagevalue.NewFallbackValue(source, GetFirstLocale(ctx.Locales()))
+		return messagevalue.NewFallbackValue(source, ctx.Locale())
 	}
 
 	return getMessageNumber(ctx, numericOperand.Value, mergedOptions, false)
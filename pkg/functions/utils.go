@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+
+	"github.com/kaptinlin/messageformat-go/internal/intlbridge"
 )
 
 // ErrNotBoolean indicates the value cannot be converted to a boolean.
@@ -130,6 +132,17 @@ func asString(value any) (string, error) {
 	return "", ErrNotString
 }
 
+// NegotiateLocale returns the locale to format with for the requested locales,
+// in priority order, among the CLDR locales that go-intl has locale data for.
+// localeMatcher "lookup" applies BCP 47 lookup, truncating each tag subtag by
+// subtag; any other value, including "best fit", picks the closest locale of
+// the same language by CLDR language distance (zh-TW -> zh-Hant-TW, fr-XX ->
+// fr). Unknown and malformed tags are skipped, and "en" is returned when
+// nothing matches. For example, ["xx-YY", "fr"] negotiates "fr".
+func NegotiateLocale(locales []string, localeMatcher string) string {
+	return intlbridge.NegotiateLocale(locales, localeMatcher)
+}
+
 // GetFirstLocale returns the first locale from a list, or "en" as fallback
 func GetFirstLocale(locales []string) string {
 	if len(locales) > 0 {
@@ -103,3 +103,17 @@ func TestGetFirstLocale(t *testing.T) {
 	assert.Equal(t, "en", GetFirstLocale([]string{}))
 	assert.Equal(t, "en", GetFirstLocale(nil))
 }
+
+func TestNegotiateLocale(t *testing.T) {
+	assert.Equal(t, "fr", NegotiateLocale([]string{"xx-YY", "fr"}, "best fit"))
+	assert.Equal(t, "zh-Hant-TW", NegotiateLocale([]string{"zh-TW"}, "best fit"))
+	assert.Equal(t, "zh", NegotiateLocale([]string{"zh-TW"}, "lookup"))
+	assert.Equal(t, "es-AR", NegotiateLocale([]string{"es-AR-x-test"}, "lookup"))
+	assert.Equal(t, "en", NegotiateLocale(nil, "lookup"))
+
+	ctx := NewMessageFunctionContext([]string{"xx-YY", "de-AT"}, "$x", "lookup", nil, nil, "", "")
+	assert.Equal(t, "de-AT", ctx.Locale())
+	assert.Equal(t, "fr", ctx.WithLocale("fr").Locale(), "negotiated locale")
+	assert.Equal(t, "de-AT", ctx.Locale())
+	assert.Equal(t, []string{"xx-YY", "de-AT"}, ctx.Locales())
+}
@@ -0,0 +1,285 @@
+// Package htmlrender renders formatted MessageFormat 2.0 parts as HTML.
+//
+// All text is escaped, and markup is mapped to HTML through an allowlist:
+// {#b}…{/b} renders as <b>…</b>, and {#link href=$url}…{/link} as
+// <a href="…">…</a> when the URL is safe. Markup that the allowlist does not
+// name is dropped, keeping its content, or rejected. The result is always
+// well formed: close markup without a matching open is dropped, and open
+// elements are closed at the end.
+package htmlrender
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"slices"
+	"strings"
+
+	messageformat "github.com/kaptinlin/messageformat-go"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// Static errors to avoid dynamic error creation
+var (
+	// ErrUnknownMarkup indicates markup that the allowlist does not name,
+	// rendered with RejectUnknown.
+	ErrUnknownMarkup = errors.New("unknown markup")
+
+	// ErrInvalidElement indicates an allowlist element with an invalid tag
+	// or attribute name, or an attribute that can run script.
+	ErrInvalidElement = errors.New("invalid html element")
+)
+
+// Element is the HTML element that a markup name renders as.
+type Element struct {
+	// Tag is the HTML tag name, such as "strong".
+	Tag string
+
+	// Attributes lists the markup options rendered as attributes of the
+	// same name, in order. The values of URL attributes, such as href in any
+	// letter case, are dropped unless they are relative or use a safe scheme.
+	Attributes []string
+}
+
+// UnknownMarkup selects how markup missing from the allowlist renders.
+type UnknownMarkup int
+
+const (
+	// DropUnknown renders the content of unknown markup without a tag.
+	DropUnknown UnknownMarkup = iota
+
+	// RejectUnknown fails rendering with ErrUnknownMarkup.
+	RejectUnknown
+)
+
+// DefaultElements returns the allowlist used when New is given no elements:
+// common inline formatting tags under their own names, br, and link as an a
+// element with an href attribute.
+func DefaultElements() map[string]Element {
+	elements := map[string]Element{
+		"br":   {Tag: "br"},
+		"link": {Tag: "a", Attributes: []string{"href"}},
+	}
+	for _, tag := range []string{"b", "strong", "i", "em", "u", "s", "small", "mark", "sub", "sup", "code", "span"} {
+		elements[tag] = Element{Tag: tag}
+	}
+	return elements
+}
+
+// voidElements are the HTML elements without content or a close tag.
+var voidElements = map[string]bool{"br": true, "hr": true, "img": true, "wbr": true}
+
+// urlAttributes are the attributes whose values are URLs.
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true, "action": true, "formaction": true, "poster": true}
+
+// unsafeTags are the elements whose content is not parsed as escaped text or
+// that load or run active content. Allowlists cannot render markup as them.
+var unsafeTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true,
+	"object": true, "embed": true, "textarea": true, "title": true, "xmp": true,
+	"plaintext": true, "noembed": true, "noframes": true, "noscript": true,
+	"template": true, "svg": true, "math": true, "base": true, "meta": true, "link": true,
+}
+
+// safeSchemes are the URL schemes allowed in URL attributes.
+var safeSchemes = map[string]bool{"http": true, "https": true, "mailto": true, "tel": true}
+
+// Renderer renders parts as HTML. It is safe for concurrent use.
+type Renderer struct {
+	elements map[string]Element
+	unknown  UnknownMarkup
+}
+
+// Option configures a Renderer.
+type Option func(*Renderer)
+
+// WithElements replaces the allowlist with elements, keyed by markup name.
+func WithElements(elements map[string]Element) Option {
+	return func(r *Renderer) {
+		r.elements = make(map[string]Element, len(elements))
+		for name, element := range elements {
+			r.elements[name] = Element{Tag: element.Tag, Attributes: slices.Clone(element.Attributes)}
+		}
+	}
+}
+
+// WithElement adds element to the allowlist under the markup name.
+func WithElement(name string, element Element) Option {
+	return func(r *Renderer) {
+		r.elements[name] = Element{Tag: element.Tag, Attributes: slices.Clone(element.Attributes)}
+	}
+}
+
+// WithUnknownMarkup sets how markup missing from the allowlist renders.
+// The default is DropUnknown.
+func WithUnknownMarkup(unknown UnknownMarkup) Option {
+	return func(r *Renderer) {
+		r.unknown = unknown
+	}
+}
+
+// New creates a renderer with the DefaultElements allowlist, changed by
+// options. It fails with ErrInvalidElement for tag or attribute names that
+// are not plain names, for tags such as script, style, iframe, object, and
+// textarea whose content is not escaped text, and for event handler, style,
+// and srcdoc attributes.
+func New(options ...Option) (*Renderer, error) {
+	r := &Renderer{elements: DefaultElements()}
+	for _, option := range options {
+		option(r)
+	}
+	for name, element := range r.elements {
+		if err := validateElement(element); err != nil {
+			return nil, fmt.Errorf("%w: markup %s: %w", ErrInvalidElement, name, err)
+		}
+	}
+	return r, nil
+}
+
+var defaultRenderer, _ = New()
+
+// Render renders parts with the default renderer.
+func Render(parts []messagevalue.MessagePart) (template.HTML, error) {
+	return defaultRenderer.Render(parts)
+}
+
+// Format formats mf with values and renders the parts with the default
+// renderer. The error joins the formatting diagnostics and any render error.
+func Format(mf *messageformat.MessageFormat, values map[string]any) (template.HTML, error) {
+	return defaultRenderer.Format(mf, values)
+}
+
+// Format formats mf with values and renders the parts. The error joins the
+// formatting diagnostics, which leave the output usable, and any render
+// error, which leaves it empty.
+func (r *Renderer) Format(mf *messageformat.MessageFormat, values map[string]any) (template.HTML, error) {
+	parts, formatErr := mf.FormatToParts(values)
+	html, renderErr := r.Render(parts)
+	return html, errors.Join(formatErr, renderErr)
+}
+
+// Render renders parts as HTML. Text, string, number, date/time, unknown,
+// and fallback parts are escaped; markup parts render through the
+// allowlist.
+func (r *Renderer) Render(parts []messagevalue.MessagePart) (template.HTML, error) {
+	var b strings.Builder
+	var open []string // markup names of the open elements
+	for _, part := range parts {
+		markup, ok := part.(*messagevalue.MarkupPart)
+		if !ok {
+			b.WriteString(template.HTMLEscapeString(partText(part)))
+			continue
+		}
+
+		element, known := r.elements[markup.Name()]
+		if !known {
+			if r.unknown == RejectUnknown {
+				return "", fmt.Errorf("%w: %s", ErrUnknownMarkup, markup.Name())
+			}
+			continue
+		}
+
+		switch markup.Kind() {
+		case "open":
+			writeStartTag(&b, element, markup.Options())
+			if !voidElements[element.Tag] {
+				open = append(open, markup.Name())
+			}
+		case "standalone":
+			writeStartTag(&b, element, markup.Options())
+			if !voidElements[element.Tag] {
+				writeEndTag(&b, element)
+			}
+		case "close":
+			i := slices.Index(open, markup.Name())
+			if i < 0 {
+				continue
+			}
+			// Elements opened inside this one and not closed end with it
+			for len(open) > i {
+				writeEndTag(&b, r.elements[open[len(open)-1]])
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	for len(open) > 0 {
+		writeEndTag(&b, r.elements[open[len(open)-1]])
+		open = open[:len(open)-1]
+	}
+	return template.HTML(b.String()), nil //nolint:gosec // built from escaped text and allowlisted tags
+}
+
+// partText returns the text of a non-markup part.
+func partText(part messagevalue.MessagePart) string {
+	if text, ok := part.(interface{ Text() string }); ok {
+		return text.Text()
+	}
+	return fmt.Sprint(part.Value())
+}
+
+// writeStartTag writes the start tag of element with the attributes it takes
+// from options.
+func writeStartTag(b *strings.Builder, element Element, options map[string]any) {
+	b.WriteString("<" + element.Tag)
+	for _, name := range element.Attributes {
+		value, ok := options[name]
+		if !ok || value == nil {
+			continue
+		}
+		text := fmt.Sprint(value)
+		if urlAttributes[strings.ToLower(name)] && !isSafeURL(text) {
+			continue
+		}
+		b.WriteString(" " + name + `="` + template.HTMLEscapeString(text) + `"`)
+	}
+	b.WriteString(">")
+}
+
+// writeEndTag writes the end tag of element.
+func writeEndTag(b *strings.Builder, element Element) {
+	b.WriteString("</" + element.Tag + ">")
+}
+
+// isSafeURL reports whether s is a relative URL or one with a safe scheme.
+func isSafeURL(s string) bool {
+	if strings.ContainsFunc(s, func(r rune) bool { return r < ' ' || r == 0x7f }) {
+		return false
+	}
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || safeSchemes[strings.ToLower(u.Scheme)]
+}
+
+// validateElement checks that element renders as a plain tag with plain,
+// script-free attributes.
+func validateElement(element Element) error {
+	if !isName(element.Tag) || unsafeTags[strings.ToLower(element.Tag)] {
+		return fmt.Errorf("tag %q", element.Tag)
+	}
+	for _, name := range element.Attributes {
+		lower := strings.ToLower(name)
+		if !isName(name) || strings.HasPrefix(lower, "on") || lower == "style" || lower == "srcdoc" {
+			return fmt.Errorf("attribute %q", name)
+		}
+	}
+	return nil
+}
+
+// isName reports whether s is a non-empty ASCII tag or attribute name.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
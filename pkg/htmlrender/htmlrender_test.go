@@ -0,0 +1,134 @@
+package htmlrender
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	messageformat "github.com/kaptinlin/messageformat-go"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		values   map[string]any
+		expected template.HTML
+	}{
+		{
+			name:     "escapes text and values",
+			source:   "a < b & {$x}",
+			values:   map[string]any{"x": `<script>"'`},
+			expected: "a &lt; b &amp; &lt;script&gt;&#34;&#39;",
+		},
+		{
+			name:     "allowlisted markup",
+			source:   "{#b}bold{/b}{#br/}{#em}em{/em}",
+			expected: "<b>bold</b><br><em>em</em>",
+		},
+		{
+			name:     "link",
+			source:   "{#link href=$url}docs{/link}",
+			values:   map[string]any{"url": "https://example.com/?a=1&b=2"},
+			expected: `<a href="https://example.com/?a=1&amp;b=2">docs</a>`,
+		},
+		{
+			name:     "relative link",
+			source:   "{#link href=|/help|}help{/link}",
+			expected: `<a href="/help">help</a>`,
+		},
+		{
+			name:     "unsafe url",
+			source:   "{#link href=$url}click{/link}",
+			values:   map[string]any{"url": " JavaScript:alert(1)"},
+			expected: "<a>click</a>",
+		},
+		{
+			name:     "options that are not attributes",
+			source:   "{#b onclick=|alert(1)| class=x}b{/b}",
+			expected: "<b>b</b>",
+		},
+		{
+			name:     "unknown markup keeps its content",
+			source:   "{#script}alert(1){/script}",
+			expected: "alert(1)",
+		},
+		{
+			name:     "unmatched close and unclosed open",
+			source:   "{/b}{#i}{#b}x{/i}y{#em}z",
+			expected: "<i><b>x</b></i>y<em>z</em>",
+		},
+		{
+			name:     "fallback",
+			source:   "{$missing}",
+			expected: "{$missing}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, err := messageformat.Parse([]string{"en"}, tt.source, messageformat.WithBidiIsolation(messageformat.BidiNone))
+			require.NoError(t, err)
+
+			html, _ := Format(mf, tt.values)
+			assert.Equal(t, tt.expected, html)
+		})
+	}
+}
+
+func TestRendererOptions(t *testing.T) {
+	r, err := New(
+		WithElements(map[string]Element{"term": {Tag: "dfn", Attributes: []string{"title"}}}),
+		WithUnknownMarkup(RejectUnknown),
+	)
+	require.NoError(t, err)
+
+	parts := []messagevalue.MessagePart{
+		messagevalue.NewMarkupPart("open", "term", "", "", map[string]any{"title": `"quoted"`}),
+		messagevalue.NewTextPart("word", "word", "en"),
+		messagevalue.NewMarkupPart("close", "term", "", "", nil),
+	}
+	html, err := r.Render(parts)
+	require.NoError(t, err)
+	assert.Equal(t, template.HTML(`<dfn title="&#34;quoted&#34;">word</dfn>`), html)
+
+	html, err = r.Render([]messagevalue.MessagePart{messagevalue.NewMarkupPart("standalone", "b", "", "", nil)})
+	require.ErrorIs(t, err, ErrUnknownMarkup)
+	assert.Empty(t, html)
+}
+
+func TestURLAttributesIgnoreCase(t *testing.T) {
+	r, err := New(WithElement("link", Element{Tag: "a", Attributes: []string{"HREF"}}))
+	require.NoError(t, err)
+
+	html, err := r.Render([]messagevalue.MessagePart{
+		messagevalue.NewMarkupPart("open", "link", "", "", map[string]any{"HREF": "javascript:alert(1)"}),
+		messagevalue.NewTextPart("x", "x", "en"),
+		messagevalue.NewMarkupPart("close", "link", "", "", nil),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, template.HTML("<a>x</a>"), html)
+}
+
+func TestNewRejectsUnsafeElements(t *testing.T) {
+	for _, element := range []Element{
+		{Tag: ""},
+		{Tag: "a href"},
+		{Tag: "a", Attributes: []string{"onclick"}},
+		{Tag: "a", Attributes: []string{"style"}},
+		{Tag: "a", Attributes: []string{`x"`}},
+		{Tag: "a", Attributes: []string{"OnClick"}},
+		{Tag: "script"},
+		{Tag: "Style"},
+		{Tag: "iframe"},
+		{Tag: "object"},
+		{Tag: "embed"},
+		{Tag: "textarea"},
+	} {
+		_, err := New(WithElement("x", element))
+		assert.ErrorIs(t, err, ErrInvalidElement, "%+v", element)
+	}
+}
@@ -0,0 +1,450 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
+)
+
+// Consistency rule IDs. These rules compare a translation with its
+// source-locale message and report nothing when Pass.Source is nil.
+const (
+	RuleVariableMismatch   = "variable-mismatch"
+	RuleAnnotationMismatch = "annotation-mismatch"
+	RuleMarkupMismatch     = "markup-mismatch"
+	RuleMarkupOrder        = "markup-order"
+	RuleIDMismatch         = "id-mismatch"
+	RuleLostAttribute      = "lost-attribute"
+)
+
+// ConsistencyRules returns the rules run by Compare.
+func ConsistencyRules() []Rule {
+	return []Rule{
+		{
+			ID:       RuleVariableMismatch,
+			Severity: SeverityError,
+			Doc:      "reports external variables used by only one of the source and the translation",
+			Check:    checkVariableMismatch,
+		},
+		{
+			ID:       RuleAnnotationMismatch,
+			Severity: SeverityWarning,
+			Doc:      "reports variables annotated with different functions in the source and the translation",
+			Check:    checkAnnotationMismatch,
+		},
+		{
+			ID:       RuleMarkupMismatch,
+			Severity: SeverityError,
+			Doc:      "reports markup added to or dropped from a translated pattern",
+			Check:    checkMarkupMismatch,
+		},
+		{
+			ID:       RuleMarkupOrder,
+			Severity: SeverityWarning,
+			Doc:      "reports translated patterns with the source's markup in a different order",
+			Check:    checkMarkupOrder,
+		},
+		{
+			ID:       RuleIDMismatch,
+			Severity: SeverityError,
+			Doc:      "reports u:id values present in only one of the source and the translation",
+			Check:    checkIDMismatch,
+		},
+		{
+			ID:       RuleLostAttribute,
+			Severity: SeverityWarning,
+			Doc:      "reports attributes of source expressions and markup missing from their translation",
+			Check:    checkLostAttributes,
+		},
+	}
+}
+
+// Compare runs ConsistencyRules over translation against source, its
+// source-locale message. Findings about something the translation lacks
+// have no span; all other spans refer to the translation.
+func Compare(source, translation datamodel.Message) []Finding {
+	return New(WithoutRules(ruleIDs(DefaultRules())...), WithRules(ConsistencyRules()...)).Compare(source, translation)
+}
+
+func ruleIDs(rules []Rule) []string {
+	ids := make([]string, len(rules))
+	for i, rule := range rules {
+		ids[i] = rule.ID
+	}
+	return ids
+}
+
+// checkVariableMismatch compares the external variables that ValidateMessage
+// collects for both messages.
+func checkVariableMismatch(pass *Pass) {
+	if pass.Source == nil {
+		return
+	}
+	source := externalVariables(pass.Source)
+	translation := externalVariables(pass.Message)
+	refs := variableRefs(pass.Message)
+
+	for _, name := range source {
+		if !slices.Contains(translation, name) {
+			pass.Reportf(nil, "$%s is missing from the translation", name)
+		}
+	}
+	for _, name := range translation {
+		if !slices.Contains(source, name) {
+			pass.Reportf(refs[name], "$%s is not in the source", name)
+		}
+	}
+}
+
+func externalVariables(msg datamodel.Message) []string {
+	result, _ := datamodel.ValidateMessage(msg, nil)
+	if result == nil {
+		return nil
+	}
+	return result.Variables
+}
+
+// variableRefs returns the first reference to each variable of msg.
+func variableRefs(msg datamodel.Message) map[string]*datamodel.VariableRef {
+	refs := make(map[string]*datamodel.VariableRef)
+	datamodel.Visit(msg, &datamodel.Visitor{
+		Value: func(value datamodel.ExpressionArg, _ datamodel.VisitContext, _ datamodel.ValuePosition) {
+			if ref, ok := value.(*datamodel.VariableRef); ok && refs[ref.Name()] == nil {
+				refs[ref.Name()] = ref
+			}
+		},
+	})
+	return refs
+}
+
+// annotations are the functions applied to one variable across a message.
+type annotations struct {
+	names []string               // Function names, sorted
+	first *datamodel.FunctionRef // First annotation, for the span
+}
+
+// variableAnnotations returns the function annotations of each variable used
+// as an operand in msg. Variables used without an annotation have no names.
+func variableAnnotations(msg datamodel.Message) map[string]*annotations {
+	result := make(map[string]*annotations)
+	datamodel.Visit(msg, &datamodel.Visitor{
+		Expression: func(expr *datamodel.Expression, _ datamodel.VisitContext) func() {
+			ref, ok := expr.Arg().(*datamodel.VariableRef)
+			if !ok {
+				return nil
+			}
+			a := result[ref.Name()]
+			if a == nil {
+				a = &annotations{}
+				result[ref.Name()] = a
+			}
+			if fn := expr.FunctionRef(); fn != nil {
+				if a.first == nil {
+					a.first = fn
+				}
+				if i, found := slices.BinarySearch(a.names, fn.Name()); !found {
+					a.names = slices.Insert(a.names, i, fn.Name())
+				}
+			}
+			return nil
+		},
+	})
+	return result
+}
+
+// checkAnnotationMismatch reports variables that both messages use as an
+// operand but annotate with different functions.
+func checkAnnotationMismatch(pass *Pass) {
+	if pass.Source == nil {
+		return
+	}
+	source := variableAnnotations(pass.Source)
+	translation := variableAnnotations(pass.Message)
+	refs := variableRefs(pass.Message)
+
+	for _, name := range slices.Sorted(maps.Keys(translation)) {
+		src, ok := source[name]
+		if !ok {
+			continue
+		}
+		tr := translation[name]
+		if slices.Equal(src.names, tr.names) {
+			continue
+		}
+		var node errors.Node = refs[name]
+		if tr.first != nil {
+			node = tr.first
+		}
+		pass.Reportf(node, "$%s is annotated with %s, but with %s in the source",
+			name, describeFunctions(tr.names), describeFunctions(src.names))
+	}
+}
+
+func describeFunctions(names []string) string {
+	if len(names) == 0 {
+		return "no function"
+	}
+	described := make([]string, len(names))
+	for i, name := range names {
+		described[i] = ":" + name
+	}
+	return strings.Join(described, " and ")
+}
+
+// translatedPattern is a pattern of the translation with the source pattern
+// it translates.
+type translatedPattern struct {
+	source      datamodel.Pattern
+	translation datamodel.Pattern
+	variant     errors.Node // Translation variant; nil for pattern messages
+}
+
+// translatedPatterns pairs each translation pattern with the source variant
+// that has the same keys. Translation variants without one, such as plural
+// categories the source locale lacks, pair with the source's catchall variant.
+func translatedPatterns(source, translation datamodel.Message) []translatedPattern {
+	var fallback datamodel.Pattern
+	byKeys := make(map[string]datamodel.Pattern)
+	switch msg := source.(type) {
+	case *datamodel.PatternMessage:
+		fallback = msg.Pattern()
+		byKeys[""] = fallback
+	case *datamodel.SelectMessage:
+		for _, variant := range msg.Variants() {
+			keys := variantKeyString(variant)
+			if _, ok := byKeys[keys]; !ok {
+				byKeys[keys] = variant.Value()
+			}
+			if strings.Trim(keys, "* ") == "" {
+				fallback = variant.Value()
+			}
+		}
+	}
+
+	var pairs []translatedPattern
+	switch msg := translation.(type) {
+	case *datamodel.PatternMessage:
+		pattern, ok := byKeys[""]
+		if !ok {
+			pattern = fallback
+		}
+		pairs = append(pairs, translatedPattern{source: pattern, translation: msg.Pattern()})
+	case *datamodel.SelectMessage:
+		for _, variant := range msg.Variants() {
+			pattern, ok := byKeys[variantKeyString(variant)]
+			if !ok {
+				pattern = fallback
+			}
+			pairs = append(pairs, translatedPattern{source: pattern, translation: variant.Value(), variant: variant})
+		}
+	}
+	return pairs
+}
+
+// variantKeyString joins the keys of variant, writing catchall keys as "*".
+func variantKeyString(variant datamodel.Variant) string {
+	keys := make([]string, len(variant.Keys()))
+	for i, key := range variant.Keys() {
+		keys[i] = "*"
+		if literal, ok := key.(*datamodel.Literal); ok {
+			keys[i] = "|" + literal.Value() + "|"
+		}
+	}
+	return strings.Join(keys, " ")
+}
+
+// patternMarkup returns the markup elements of pattern, in order.
+func patternMarkup(pattern datamodel.Pattern) []*datamodel.Markup {
+	var markup []*datamodel.Markup
+	for _, element := range pattern.Elements() {
+		if m, ok := element.(*datamodel.Markup); ok {
+			markup = append(markup, m)
+		}
+	}
+	return markup
+}
+
+// markupTag writes markup as "{#name}", "{/name}", or "{#name/}".
+func markupTag(markup *datamodel.Markup) string {
+	switch markup.Kind() {
+	case datamodel.MarkupClose:
+		return "{/" + markup.Name() + "}"
+	case datamodel.MarkupStandalone:
+		return "{#" + markup.Name() + "/}"
+	default:
+		return "{#" + markup.Name() + "}"
+	}
+}
+
+func markupTags(markup []*datamodel.Markup) []string {
+	tags := make([]string, len(markup))
+	for i, m := range markup {
+		tags[i] = markupTag(m)
+	}
+	return tags
+}
+
+// checkMarkupMismatch reports, per translated pattern, markup the source
+// pattern does not have and source markup the translation dropped.
+func checkMarkupMismatch(pass *Pass) {
+	if pass.Source == nil {
+		return
+	}
+	for _, pair := range translatedPatterns(pass.Source, pass.Message) {
+		sourceTags := markupTags(patternMarkup(pair.source))
+		remaining := make(map[string]int)
+		for _, tag := range sourceTags {
+			remaining[tag]++
+		}
+		for _, markup := range patternMarkup(pair.translation) {
+			tag := markupTag(markup)
+			if remaining[tag] == 0 {
+				pass.Reportf(markup, "%s is not in the source", tag)
+				continue
+			}
+			remaining[tag]--
+		}
+		for _, tag := range sourceTags {
+			if remaining[tag] > 0 {
+				remaining[tag]--
+				pass.Reportf(pair.variant, "%s is missing from the translation", tag)
+			}
+		}
+	}
+}
+
+// checkMarkupOrder reports translated patterns with the same markup as the
+// source pattern in a different order. Patterns with added or dropped markup
+// are left to the markup-mismatch rule.
+func checkMarkupOrder(pass *Pass) {
+	if pass.Source == nil {
+		return
+	}
+	for _, pair := range translatedPatterns(pass.Source, pass.Message) {
+		sourceTags := markupTags(patternMarkup(pair.source))
+		markup := patternMarkup(pair.translation)
+		tags := markupTags(markup)
+		if slices.Equal(sourceTags, tags) || !slices.Equal(slices.Sorted(slices.Values(sourceTags)), slices.Sorted(slices.Values(tags))) {
+			continue
+		}
+		i := 0
+		for sourceTags[i] == tags[i] {
+			i++
+		}
+		pass.Reportf(markup[i], "markup order differs from the source: %s where the source has %s",
+			strings.Join(tags, ""), strings.Join(sourceTags, ""))
+	}
+}
+
+// idNodes returns the expressions and markup of msg by their literal u:id
+// option, in order of first appearance.
+func idNodes(msg datamodel.Message) ([]string, map[string]errors.Node) {
+	var ids []string
+	nodes := make(map[string]errors.Node)
+	record := func(options datamodel.Options, node errors.Node) {
+		literal, ok := options["u:id"].(*datamodel.Literal)
+		if !ok {
+			return
+		}
+		if _, seen := nodes[literal.Value()]; !seen {
+			ids = append(ids, literal.Value())
+			nodes[literal.Value()] = node
+		}
+	}
+	datamodel.Visit(msg, &datamodel.Visitor{
+		Expression: func(expr *datamodel.Expression, _ datamodel.VisitContext) func() {
+			if fn := expr.FunctionRef(); fn != nil {
+				record(fn.Options(), expr)
+			}
+			return nil
+		},
+		Markup: func(markup *datamodel.Markup, _ datamodel.VisitContext) func() {
+			record(markup.Options(), markup)
+			return nil
+		},
+	})
+	return ids, nodes
+}
+
+// checkIDMismatch reports u:id values that only one of the messages uses.
+func checkIDMismatch(pass *Pass) {
+	if pass.Source == nil {
+		return
+	}
+	sourceIDs, sourceNodes := idNodes(pass.Source)
+	ids, nodes := idNodes(pass.Message)
+	for _, id := range sourceIDs {
+		if _, ok := nodes[id]; !ok {
+			pass.Reportf(nil, "u:id %s is missing from the translation", id)
+		}
+	}
+	for _, id := range ids {
+		if _, ok := sourceNodes[id]; !ok {
+			pass.Reportf(nodes[id], "u:id %s is not in the source", id)
+		}
+	}
+}
+
+// attributedNode is an expression with a variable operand, or markup, with
+// its attributes.
+type attributedNode struct {
+	key        string // "{$name}" for expressions, the markup tag for markup
+	node       errors.Node
+	attributes datamodel.Attributes
+}
+
+// attributedNodes returns the expressions with a variable operand and the
+// markup of msg. Expressions with literal operands are translated text and
+// have no counterpart to compare with.
+func attributedNodes(msg datamodel.Message) []attributedNode {
+	var result []attributedNode
+	datamodel.Visit(msg, &datamodel.Visitor{
+		Expression: func(expr *datamodel.Expression, _ datamodel.VisitContext) func() {
+			if ref, ok := expr.Arg().(*datamodel.VariableRef); ok {
+				result = append(result, attributedNode{key: "{$" + ref.Name() + "}", node: expr, attributes: expr.Attributes()})
+			}
+			return nil
+		},
+		Markup: func(markup *datamodel.Markup, _ datamodel.VisitContext) func() {
+			result = append(result, attributedNode{key: markupTag(markup), node: markup, attributes: markup.Attributes()})
+			return nil
+		},
+	})
+	return result
+}
+
+// checkLostAttributes reports source attributes that no translation
+// counterpart of the attributed expression or markup carries.
+func checkLostAttributes(pass *Pass) {
+	if pass.Source == nil {
+		return
+	}
+	translation := attributedNodes(pass.Message)
+	reported := make(map[string]bool)
+	for _, src := range attributedNodes(pass.Source) {
+		var counterparts []attributedNode
+		for _, node := range translation {
+			if node.key == src.key {
+				counterparts = append(counterparts, node)
+			}
+		}
+		if len(counterparts) == 0 {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(src.attributes)) {
+			report := fmt.Sprintf("%s @%s", src.key, name)
+			if reported[report] || slices.ContainsFunc(counterparts, func(node attributedNode) bool {
+				_, ok := node.attributes[name]
+				return ok
+			}) {
+				continue
+			}
+			reported[report] = true
+			pass.Reportf(counterparts[0].node, "attribute @%s of %s is missing from the translation", name, src.key)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+)
+
+func compareSources(t *testing.T, source, translation string) []finding {
+	t.Helper()
+
+	src, err := datamodel.ParseMessage(source)
+	require.NoError(t, err)
+	tr, err := datamodel.ParseMessage(translation)
+	require.NoError(t, err)
+
+	result := []finding{}
+	for _, f := range Compare(src, tr) {
+		text := ""
+		if f.Start >= 0 {
+			text = translation[f.Start:f.End]
+		}
+		result = append(result, finding{rule: f.Rule, message: f.Message, text: text})
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		translation string
+		want        []finding
+	}{
+		{
+			name:        "consistent translation",
+			source:      "Hello {$name}, you have {#b}{$count :integer}{/b} messages",
+			translation: "Hallo {$name}, du hast {#b}{$count :integer}{/b} Nachrichten",
+		},
+		{
+			name:        "missing and extra variables",
+			source:      "Hello {$name} from {$city}",
+			translation: "Hallo {$nmae} aus {$city}",
+			want: []finding{
+				{rule: RuleVariableMismatch, message: "$name is missing from the translation"},
+				{rule: RuleVariableMismatch, message: "$nmae is not in the source", text: "$nmae"},
+			},
+		},
+		{
+			name:        "different annotations",
+			source:      ".input {$n :number} {{{$n} {$d :datetime} {$s :string}}}",
+			translation: "{$n :integer} {$d} {$s :string}",
+			want: []finding{
+				{rule: RuleAnnotationMismatch, message: "$n is annotated with :integer, but with :number in the source", text: ":integer"},
+				{rule: RuleAnnotationMismatch, message: "$d is annotated with no function, but with :datetime in the source", text: "$d"},
+			},
+		},
+		{
+			name:        "markup added and dropped",
+			source:      "{#b}bold{/b} and {#br/}",
+			translation: "{#i}kursiv{/i} und",
+			want: []finding{
+				{rule: RuleMarkupMismatch, message: "{#b} is missing from the translation"},
+				{rule: RuleMarkupMismatch, message: "{/b} is missing from the translation"},
+				{rule: RuleMarkupMismatch, message: "{#br/} is missing from the translation"},
+				{rule: RuleMarkupMismatch, message: "{#i} is not in the source", text: "{#i}"},
+				{rule: RuleMarkupMismatch, message: "{/i} is not in the source", text: "{/i}"},
+			},
+		},
+		{
+			name:        "markup reordered",
+			source:      "{#a}x{/a} {#b}y{/b}",
+			translation: "{#a}x {#b}y{/a}{/b}",
+			want: []finding{
+				{rule: RuleMarkupOrder, message: "markup order differs from the source: {#a}{#b}{/a}{/b} where the source has {#a}{/a}{#b}{/b}", text: "{#b}"},
+			},
+		},
+		{
+			name: "variants pair by keys, extra variants with the catchall",
+			source: ".input {$n :number} .match $n " +
+				"one {{{#b}one{/b}}} " +
+				"* {{{#b}{$n}{/b} items}}",
+			translation: ".input {$n :number} .match $n " +
+				"one {{{#b}jeden{/b}}} " +
+				"few {{{$n} pliki}} " +
+				"* {{{#b}{$n}{/b} plików}}",
+			want: []finding{
+				{rule: RuleMarkupMismatch, message: "{#b} is missing from the translation", text: "few {{{$n} pliki}}"},
+				{rule: RuleMarkupMismatch, message: "{/b} is missing from the translation", text: "few {{{$n} pliki}}"},
+			},
+		},
+		{
+			name:        "changed u:id",
+			source:      "{$x :string u:id=name} {#link u:id=home}home{/link}",
+			translation: "{$x :string u:id=nom} {#link u:id=home}accueil{/link}",
+			want: []finding{
+				{rule: RuleIDMismatch, message: "u:id name is missing from the translation"},
+				{rule: RuleIDMismatch, message: "u:id nom is not in the source", text: "{$x :string u:id=nom}"},
+			},
+		},
+		{
+			name:        "lost attributes",
+			source:      "{$brand @translate=no} {#b @track}x{/b}",
+			translation: "{$brand} {#b}y{/b}",
+			want: []finding{
+				{rule: RuleLostAttribute, message: "attribute @translate of {$brand} is missing from the translation", text: "{$brand}"},
+				{rule: RuleLostAttribute, message: "attribute @track of {#b} is missing from the translation", text: "{#b}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == nil {
+				want = []finding{}
+			}
+			assert.Equal(t, want, compareSources(t, tt.source, tt.translation))
+		})
+	}
+}
+
+func TestLinterCompare(t *testing.T) {
+	src, err := datamodel.ParseMessage("Hello {$name}")
+	require.NoError(t, err)
+	tr, err := datamodel.ParseMessage(".local $x = {1} {{Hallo {$nom}}}")
+	require.NoError(t, err)
+
+	findings := New(WithRules(ConsistencyRules()...), WithoutRules(RuleLostAttribute)).Compare(src, tr)
+	rules := make([]string, len(findings))
+	for i, f := range findings {
+		rules[i] = f.Rule
+	}
+	assert.ElementsMatch(t, []string{RuleVariableMismatch, RuleUnusedDeclaration, RuleVariableMismatch}, rules)
+
+	assert.Empty(t, New(WithRules(ConsistencyRules()...)).Lint(src))
+	assert.Nil(t, Compare(nil, tr))
+	assert.Nil(t, Compare(src, nil))
+}
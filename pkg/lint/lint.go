@@ -0,0 +1,276 @@
+// Package lint reports likely mistakes in MessageFormat 2.0 messages that the
+// data model allows: declarations nobody reads, functions or options the
+// formatter will not recognize, variant keys that can never be selected, and
+// markup that does not pair up.
+//
+// ValidateMessage reports errors that make a message invalid; lint reports
+// problems in valid messages. Each rule walks the message with datamodel.Visit
+// and reports findings with a rule ID, a severity, and the source span of the
+// node at fault.
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+)
+
+// Severity classifies how serious a finding is.
+type Severity string
+
+// Severities, from most to least serious.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding is one problem reported by a rule.
+type Finding struct {
+	Rule     string   // ID of the rule that reported the finding
+	Severity Severity // Severity configured for the rule
+	Message  string   // Human-readable description
+	Start    int      // Start byte offset in the source, or -1 if unknown
+	End      int      // End byte offset in the source, or -1 if unknown
+}
+
+// String formats the finding as "start-end: severity: message (rule)".
+func (f Finding) String() string {
+	if f.Start < 0 {
+		return fmt.Sprintf("%s: %s (%s)", f.Severity, f.Message, f.Rule)
+	}
+	return fmt.Sprintf("%d-%d: %s: %s (%s)", f.Start, f.End, f.Severity, f.Message, f.Rule)
+}
+
+// Rule checks a message for one kind of problem.
+type Rule struct {
+	ID       string           // Stable rule identifier, such as "unused-declaration"
+	Severity Severity         // Default severity of the rule's findings
+	Doc      string           // One-line description of what the rule reports
+	Check    func(pass *Pass) // Reports findings for pass.Message
+}
+
+// Pass holds the message and configuration a rule checks.
+type Pass struct {
+	Message   datamodel.Message
+	Functions map[string]functions.MessageFunction
+
+	// Source is the source-locale message that Message translates when the
+	// pass runs from Compare, and nil otherwise.
+	Source datamodel.Message
+
+	builtins map[string]bool // names in Functions bound to built-ins
+	rule     Rule
+	findings *[]Finding
+}
+
+// Reportf records a finding for node, which provides the source span.
+// A nil node reports a finding without a span.
+func (p *Pass) Reportf(node errors.Node, format string, args ...any) {
+	start, end := -1, -1
+	if node != nil {
+		start, end = node.GetPosition()
+	}
+	*p.findings = append(*p.findings, Finding{
+		Rule:     p.rule.ID,
+		Severity: p.rule.Severity,
+		Message:  fmt.Sprintf(format, args...),
+		Start:    start,
+		End:      end,
+	})
+}
+
+// IsBuiltin reports whether name resolves to the built-in function of that
+// name, so its options and selection behavior are known. As for a formatter,
+// a name is built-in when it comes from the default function map and
+// WithFunctions did not replace it.
+func (p *Pass) IsBuiltin(name string) bool {
+	return p.builtins[name] && p.Functions[name] != nil
+}
+
+// Annotation returns the function that annotates the variable name, following
+// .local declarations that rename another variable. It returns nil when the
+// variable has no annotation.
+func (p *Pass) Annotation(name string) *datamodel.FunctionRef {
+	declarations := p.Message.Declarations()
+	seen := make(map[string]bool)
+	for !seen[name] {
+		seen[name] = true
+		var value *datamodel.Expression
+		for _, decl := range declarations {
+			if decl.Name() == name {
+				value = declarationValue(decl)
+				break
+			}
+		}
+		if value == nil {
+			return nil
+		}
+		if fn := value.FunctionRef(); fn != nil {
+			return fn
+		}
+		ref, ok := value.Arg().(*datamodel.VariableRef)
+		if !ok {
+			return nil
+		}
+		name = ref.Name()
+	}
+	return nil
+}
+
+func declarationValue(decl datamodel.Declaration) *datamodel.Expression {
+	switch d := decl.(type) {
+	case *datamodel.InputDeclaration:
+		return d.Value()
+	case *datamodel.LocalDeclaration:
+		return d.Value()
+	}
+	return nil
+}
+
+// Linter runs a set of rules over messages. A Linter is safe for concurrent
+// use after construction.
+type Linter struct {
+	rules     []Rule
+	functions map[string]functions.MessageFunction
+	builtins  map[string]bool // names in functions bound to built-ins
+}
+
+// Option configures a Linter.
+type Option func(*Linter)
+
+// WithFunctions adds functions to the default function map, as
+// messageformat.WithFunctions does for a formatter. Names it sets are no
+// longer treated as built-ins, whatever function they are mapped to.
+func WithFunctions(fns map[string]functions.MessageFunction) Option {
+	return func(l *Linter) {
+		maps.Copy(l.functions, fns)
+		for name := range fns {
+			delete(l.builtins, name)
+		}
+	}
+}
+
+// WithRules adds rules after the default rules. A rule with the ID of an
+// existing rule replaces it.
+func WithRules(rules ...Rule) Option {
+	return func(l *Linter) {
+		for _, rule := range rules {
+			if i := l.ruleIndex(rule.ID); i >= 0 {
+				l.rules[i] = rule
+				continue
+			}
+			l.rules = append(l.rules, rule)
+		}
+	}
+}
+
+// WithoutRules disables the rules with the given IDs.
+func WithoutRules(ids ...string) Option {
+	return func(l *Linter) {
+		l.rules = slices.DeleteFunc(l.rules, func(rule Rule) bool {
+			return slices.Contains(ids, rule.ID)
+		})
+	}
+}
+
+// WithSeverity changes the severity reported by the rule with the given ID.
+func WithSeverity(id string, severity Severity) Option {
+	return func(l *Linter) {
+		if i := l.ruleIndex(id); i >= 0 {
+			l.rules[i].Severity = severity
+		}
+	}
+}
+
+// New returns a Linter running DefaultRules against the stable built-in
+// functions, adjusted by options.
+func New(options ...Option) *Linter {
+	l := &Linter{
+		rules:     DefaultRules(),
+		functions: functions.DefaultFunctionMap(),
+		builtins:  make(map[string]bool),
+	}
+	for name := range l.functions {
+		l.builtins[name] = true
+	}
+	for _, option := range options {
+		if option != nil {
+			option(l)
+		}
+	}
+	return l
+}
+
+// Rules returns the rules the linter runs, in order.
+func (l *Linter) Rules() []Rule {
+	return slices.Clone(l.rules)
+}
+
+func (l *Linter) ruleIndex(id string) int {
+	return slices.IndexFunc(l.rules, func(rule Rule) bool { return rule.ID == id })
+}
+
+// Lint runs every rule over msg and returns the findings ordered by source
+// position. Findings without a span come first.
+func (l *Linter) Lint(msg datamodel.Message) []Finding {
+	return l.run(msg, nil)
+}
+
+// Compare runs every rule over translation with Pass.Source set to source,
+// its source-locale message, so that consistency rules compare the two. Add
+// ConsistencyRules with WithRules to include them.
+func (l *Linter) Compare(source, translation datamodel.Message) []Finding {
+	if source == nil {
+		return nil
+	}
+	return l.run(translation, source)
+}
+
+func (l *Linter) run(msg, source datamodel.Message) []Finding {
+	if msg == nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, rule := range l.rules {
+		if rule.Check == nil {
+			continue
+		}
+		rule.Check(&Pass{
+			Message:   msg,
+			Functions: l.functions,
+			Source:    source,
+			builtins:  l.builtins,
+			rule:      rule,
+			findings:  &findings,
+		})
+	}
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return findings
+}
+
+// LintSource parses source and lints the resulting message. Syntax and data
+// model errors are returned as the error, with no findings.
+func (l *Linter) LintSource(source string) ([]Finding, error) {
+	msg, err := datamodel.ParseMessage(source)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := datamodel.ValidateMessage(msg, nil); err != nil {
+		return nil, err
+	}
+	return l.Lint(msg), nil
+}
+
+// Lint runs the default rules over msg with the stable built-in functions.
+func Lint(msg datamodel.Message) []Finding {
+	return New().Lint(msg)
+}
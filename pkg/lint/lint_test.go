@@ -0,0 +1,286 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// finding is the comparable projection of a Finding: the rule, the message,
+// and the source text under the span.
+type finding struct {
+	rule    string
+	message string
+	text    string
+}
+
+func lintSource(t *testing.T, linter *Linter, source string) []finding {
+	t.Helper()
+
+	findings, err := linter.LintSource(source)
+	require.NoError(t, err)
+	result := make([]finding, 0, len(findings))
+	for _, f := range findings {
+		text := ""
+		if f.Start >= 0 {
+			text = source[f.Start:f.End]
+		}
+		result = append(result, finding{rule: f.Rule, message: f.Message, text: text})
+	}
+	return result
+}
+
+func TestDefaultRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []finding
+	}{
+		{
+			name:   "clean message",
+			source: ".input {$n :number} .local $x = {$n :integer} .match $x 0 {{none}} one {{{$x} item}} * {{{#b}{$x}{/b} items}}",
+		},
+		{
+			name:   "unused declarations",
+			source: ".input {$a :string} .input {$b} .local $c = {$b :string} .local $d = {|x|} {{{$c}}}",
+			want: []finding{
+				{rule: RuleUnusedDeclaration, message: "$a is declared but never used", text: ".input {$a :string}"},
+				{rule: RuleUnusedDeclaration, message: "$d is declared but never used", text: ".local $d = {|x|}"},
+			},
+		},
+		{
+			name:   "variable used as option value",
+			source: ".local $digits = {2} {{{$n :number minimumFractionDigits=$digits}}}",
+		},
+		{
+			name:   "unknown function",
+			source: "{$x :nope} {$y :date}",
+			want: []finding{
+				{rule: RuleUnknownFunction, message: "unknown function :nope", text: ":nope"},
+				{rule: RuleUnknownFunction, message: "unknown function :date", text: ":date"},
+			},
+		},
+		{
+			name:   "unsupported options",
+			source: "{$x :integer minimumFractionDigits=2 u:id=n} {$y :string foo=bar}",
+			want: []finding{
+				{rule: RuleUnsupportedOption, message: "option minimumFractionDigits is not supported by :integer", text: ":integer minimumFractionDigits=2 u:id=n"},
+				{rule: RuleUnsupportedOption, message: "option foo is not supported by :string", text: ":string foo=bar"},
+			},
+		},
+		{
+			name:   "invalid literals",
+			source: ".input {$s :string} {{{|x| :number minimumFractionDigits=200 select=$s} {1 :integer signDisplay=sometimes}}}",
+			want: []finding{
+				{rule: RuleInvalidLiteral, message: `"x" is not a number for the operand of :number`, text: "|x|"},
+				{rule: RuleInvalidLiteral, message: `"200" is not an integer from 0 to 100 for option minimumFractionDigits of :number`, text: "200"},
+				{rule: RuleInvalidLiteral, message: "option select of :number must be a literal", text: "$s"},
+				{rule: RuleInvalidLiteral, message: `"sometimes" is not valid for option signDisplay of :integer`, text: "sometimes"},
+			},
+		},
+		{
+			name:   "impossible numeric keys",
+			source: ".input {$n :number} .match $n 1.0 {{a}} 01 {{b}} lots {{c}} 1.5 {{d}} 1e3 {{e}} * {{f}}",
+			want: []finding{
+				{rule: RuleImpossibleNumberKey, message: "key 01 never matches: it is neither a plural category nor a number as formatted for selection", text: "01"},
+				{rule: RuleImpossibleNumberKey, message: "key lots never matches: it is neither a plural category nor a number as formatted for selection", text: "lots"},
+				{rule: RuleImpossibleNumberKey, message: "key 1e3 never matches: it is neither a plural category nor a number as formatted for selection", text: "1e3"},
+			},
+		},
+		{
+			name:   "decimal keys with trailing zeros",
+			source: ".input {$n :number} .match $n 1.5 {{a}} 1.50 {{b}} |=2| {{c}} 2.0 {{d}} * {{e}}",
+			want: []finding{
+				{rule: RuleShadowedVariant, message: "variant is never selected: key 2.0 is shadowed by the equivalent key =2", text: "2.0 {{d}}"},
+			},
+		},
+		{
+			name:   "decimal keys of integer selectors",
+			source: ".input {$n :integer} .match $n 1.50 {{a}} |=3.0| {{b}} * {{c}}",
+			want: []finding{
+				{rule: RuleImpossibleNumberKey, message: "key 1.50 never matches an :integer value", text: "1.50"},
+			},
+		},
+		{
+			name:   "integer and exact selectors",
+			source: ".input {$n :integer} .local $m = {$n :number select=exact} .match $n $m 1.5 one {{a}} * * {{b}}",
+			want: []finding{
+				{rule: RuleImpossibleNumberKey, message: "key 1.5 never matches an :integer value", text: "1.5"},
+				{rule: RuleImpossibleNumberKey, message: "key one never matches: plural categories are not selected with select=exact", text: "one"},
+			},
+		},
+		{
+			name:   "annotation through local rename",
+			source: ".input {$n :number} .local $m = {$n} .match $m few {{a}} several {{b}} * {{c}}",
+			want: []finding{
+				{rule: RuleImpossibleNumberKey, message: "key several never matches: it is neither a plural category nor a number as formatted for selection", text: "several"},
+			},
+		},
+		{
+			name:   "custom selectors are not checked",
+			source: ".input {$n :string} .match $n 1.0 {{a}} * {{b}}",
+		},
+		{
+			name:   "exact number key shadows plain key",
+			source: ".input {$n :number} .input {$s :string} .match $n $s 1 a {{one a}} |=1| b {{exactly one b}} * * {{other}}",
+			want: []finding{
+				{rule: RuleShadowedVariant, message: "variant is never selected: key 1 is shadowed by the equivalent key =1", text: "1 a {{one a}}"},
+			},
+		},
+		{
+			name:   "earlier equivalent exact key wins",
+			source: ".input {$n :number} .input {$s :string} .match $n $s |=1| a {{a}} |=1.0| b {{b}} * * {{other}}",
+			want: []finding{
+				{rule: RuleShadowedVariant, message: "variant is never selected: key =1.0 is shadowed by the equivalent key =1", text: "|=1.0| b {{b}}"},
+			},
+		},
+		{
+			name:   "NFC-equivalent string keys",
+			source: ".input {$s :string} .input {$t :string} .match $s $t é x {{a}} é y {{b}} * * {{c}}",
+			want: []finding{
+				{rule: RuleShadowedVariant, message: "variant is never selected: key é is shadowed by the equivalent key é", text: "é y {{b}}"},
+			},
+		},
+		{
+			name:   "unbalanced markup",
+			source: "{/x}{#a}{#b}text{/a}{#c}",
+			want: []finding{
+				{rule: RuleUnbalancedMarkup, message: "{/x} has no matching {#x}", text: "{/x}"},
+				{rule: RuleUnbalancedMarkup, message: "{#b} is never closed", text: "{#b}"},
+				{rule: RuleUnbalancedMarkup, message: "{#c} is never closed", text: "{#c}"},
+			},
+		},
+		{
+			name:   "markup balanced per variant",
+			source: ".input {$n :number} .match $n one {{{#b}one{/b}}} * {{{#i}many{/b}}}",
+			want: []finding{
+				{rule: RuleUnbalancedMarkup, message: "{#i} is never closed", text: "{#i}"},
+				{rule: RuleUnbalancedMarkup, message: "{/b} has no matching {#b}", text: "{/b}"},
+			},
+		},
+		{
+			name:   "standalone markup",
+			source: "{#br/}{#img src=|x| /}",
+		},
+	}
+
+	linter := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lintSource(t, linter, tt.source)
+			if tt.want == nil {
+				tt.want = []finding{}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFindingSeverityAndSpan(t *testing.T) {
+	assert.Nil(t, Lint(nil))
+
+	findings, err := New().LintSource("{$x :nope}")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, Finding{
+		Rule:     RuleUnknownFunction,
+		Severity: SeverityError,
+		Message:  "unknown function :nope",
+		Start:    4,
+		End:      9,
+	}, findings[0])
+	assert.Equal(t, "4-9: error: unknown function :nope (unknown-function)", findings[0].String())
+}
+
+func TestFindingsWithoutSpan(t *testing.T) {
+	fn, err := datamodel.NewFunctionRef("nope", nil)
+	require.NoError(t, err)
+	expr, err := datamodel.NewExpression(datamodel.NewVariableRef("x"), fn, nil)
+	require.NoError(t, err)
+	pattern, err := datamodel.NewPattern([]datamodel.PatternElement{expr})
+	require.NoError(t, err)
+	msg, err := datamodel.NewPatternMessage(nil, pattern, "")
+	require.NoError(t, err)
+
+	findings := Lint(msg)
+	require.Len(t, findings, 1)
+	assert.Equal(t, -1, findings[0].Start)
+	assert.Equal(t, "error: unknown function :nope (unknown-function)", findings[0].String())
+}
+
+func TestLintSourceReportsInvalidMessages(t *testing.T) {
+	_, err := New().LintSource("{$x")
+	require.Error(t, err)
+
+	_, err = New().LintSource(".input {$x} .input {$x} {{}}")
+	require.Error(t, err)
+}
+
+func TestLinterOptions(t *testing.T) {
+	custom := func(_ functions.MessageFunctionContext, _ functions.Options, _ any) messagevalue.MessageValue {
+		return nil
+	}
+	source := ".local $unused = {1} {{{$x :number foo=bar} {$y :custom}}}"
+
+	t.Run("functions", func(t *testing.T) {
+		got := lintSource(t, New(WithFunctions(map[string]functions.MessageFunction{
+			"custom": custom,
+			"number": custom,
+		})), source)
+		assert.Equal(t, []finding{
+			{rule: RuleUnusedDeclaration, message: "$unused is declared but never used", text: ".local $unused = {1}"},
+		}, got)
+	})
+
+	t.Run("built-in implementation under a custom name", func(t *testing.T) {
+		got := lintSource(t, New(WithFunctions(map[string]functions.MessageFunction{
+			"custom": functions.NumberFunction,
+			"number": functions.NumberFunction,
+		})), source)
+		assert.Equal(t, []finding{
+			{rule: RuleUnusedDeclaration, message: "$unused is declared but never used", text: ".local $unused = {1}"},
+		}, got)
+	})
+
+	t.Run("without rules", func(t *testing.T) {
+		got := lintSource(t, New(WithoutRules(RuleUnusedDeclaration, RuleUnsupportedOption)), source)
+		assert.Equal(t, []finding{
+			{rule: RuleUnknownFunction, message: "unknown function :custom", text: ":custom"},
+		}, got)
+	})
+
+	t.Run("severity", func(t *testing.T) {
+		findings, err := New(WithSeverity(RuleUnusedDeclaration, SeverityInfo)).LintSource(source)
+		require.NoError(t, err)
+		require.NotEmpty(t, findings)
+		assert.Equal(t, RuleUnusedDeclaration, findings[0].Rule)
+		assert.Equal(t, SeverityInfo, findings[0].Severity)
+	})
+
+	t.Run("custom rule", func(t *testing.T) {
+		longMessage := Rule{
+			ID:       "long-message",
+			Severity: SeverityInfo,
+			Check: func(pass *Pass) {
+				if pm, ok := pass.Message.(*datamodel.PatternMessage); ok && pm.Pattern().Len() > 3 {
+					pass.Reportf(nil, "pattern has %d elements", pm.Pattern().Len())
+				}
+			},
+		}
+		linter := New(WithoutRules(RuleUnusedDeclaration, RuleUnknownFunction, RuleUnsupportedOption), WithRules(longMessage))
+		assert.Len(t, linter.Rules(), len(DefaultRules())-2)
+		got := lintSource(t, linter, "{$a}{$b}{$c}{$d}")
+		assert.Equal(t, []finding{{rule: "long-message", message: "pattern has 4 elements"}}, got)
+	})
+
+	t.Run("replace rule", func(t *testing.T) {
+		linter := New(WithRules(Rule{ID: RuleUnknownFunction, Severity: SeverityError}))
+		assert.Len(t, linter.Rules(), len(DefaultRules()))
+		assert.Empty(t, lintSource(t, linter, "{$x :nope}"))
+	})
+}
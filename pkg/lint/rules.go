@@ -0,0 +1,419 @@
+package lint
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/apd/v3"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+)
+
+// Default rule IDs.
+const (
+	RuleUnusedDeclaration   = "unused-declaration"
+	RuleUnknownFunction     = "unknown-function"
+	RuleUnsupportedOption   = "unsupported-option"
+	RuleInvalidLiteral      = "invalid-literal"
+	RuleImpossibleNumberKey = "impossible-numeric-key"
+	RuleShadowedVariant     = "shadowed-variant"
+	RuleUnbalancedMarkup    = "unbalanced-markup"
+)
+
+// DefaultRules returns the rules run by New.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			ID:       RuleUnusedDeclaration,
+			Severity: SeverityWarning,
+			Doc:      "reports .input and .local declarations whose variable is never referenced",
+			Check:    checkUnusedDeclarations,
+		},
+		{
+			ID:       RuleUnknownFunction,
+			Severity: SeverityError,
+			Doc:      "reports functions missing from the function map, which format as fallbacks",
+			Check:    checkUnknownFunctions,
+		},
+		{
+			ID:       RuleUnsupportedOption,
+			Severity: SeverityWarning,
+			Doc:      "reports options that built-in functions ignore",
+			Check:    checkUnsupportedOptions,
+		},
+		{
+			ID:       RuleInvalidLiteral,
+			Severity: SeverityError,
+			Doc:      "reports literal operands and option values that built-in functions reject",
+			Check:    checkInvalidLiterals,
+		},
+		{
+			ID:       RuleImpossibleNumberKey,
+			Severity: SeverityWarning,
+			Doc:      "reports :number and :integer variant keys that no value can match",
+			Check:    checkImpossibleNumberKeys,
+		},
+		{
+			ID:       RuleShadowedVariant,
+			Severity: SeverityWarning,
+			Doc:      "reports variants with a key that an equivalent key always wins over",
+			Check:    checkShadowedVariants,
+		},
+		{
+			ID:       RuleUnbalancedMarkup,
+			Severity: SeverityWarning,
+			Doc:      "reports open markup without a matching close, and close markup without an open",
+			Check:    checkUnbalancedMarkup,
+		},
+	}
+}
+
+// checkUnusedDeclarations reports declarations whose variable is not read by
+// a later declaration, a selector, or a pattern. The operand of an .input
+// declaration is the declared variable itself and does not count as a use.
+func checkUnusedDeclarations(pass *Pass) {
+	used := make(map[string]bool)
+	var current datamodel.Declaration
+	datamodel.Visit(pass.Message, &datamodel.Visitor{
+		Declaration: func(decl datamodel.Declaration) func() {
+			current = decl
+			return func() { current = nil }
+		},
+		Value: func(value datamodel.ExpressionArg, _ datamodel.VisitContext, position datamodel.ValuePosition) {
+			ref, ok := value.(*datamodel.VariableRef)
+			if !ok {
+				return
+			}
+			if _, input := current.(*datamodel.InputDeclaration); input && position == datamodel.ValueArg {
+				return
+			}
+			used[ref.Name()] = true
+		},
+	})
+
+	for _, decl := range pass.Message.Declarations() {
+		if !used[decl.Name()] {
+			node, _ := decl.(errors.Node)
+			pass.Reportf(node, "$%s is declared but never used", decl.Name())
+		}
+	}
+}
+
+// checkUnknownFunctions reports function references that the function map
+// does not define.
+func checkUnknownFunctions(pass *Pass) {
+	datamodel.Visit(pass.Message, &datamodel.Visitor{
+		FunctionRef: func(fn *datamodel.FunctionRef, _ datamodel.VisitContext, _ datamodel.ExpressionArg) func() {
+			if _, ok := pass.Functions[fn.Name()]; !ok {
+				pass.Reportf(fn, "unknown function :%s", fn.Name())
+			}
+			return nil
+		},
+	})
+}
+
+// builtinSchemas describes the operand and options of each built-in
+// function.
+var builtinSchemas = func() map[string]functions.Schema {
+	schemas := functions.DraftSchemaMap()
+	maps.Copy(schemas, functions.DefaultSchemaMap())
+	return schemas
+}()
+
+// checkUnsupportedOptions reports options of built-in functions that the
+// function does not read.
+func checkUnsupportedOptions(pass *Pass) {
+	datamodel.Visit(pass.Message, &datamodel.Visitor{
+		FunctionRef: func(fn *datamodel.FunctionRef, _ datamodel.VisitContext, _ datamodel.ExpressionArg) func() {
+			schema, ok := builtinSchemas[fn.Name()]
+			if !ok || !pass.IsBuiltin(fn.Name()) {
+				return nil
+			}
+			options := fn.Options()
+			for _, name := range slices.Sorted(maps.Keys(options)) {
+				if _, known := schema.Option(name); known || strings.HasPrefix(name, "u:") {
+					continue
+				}
+				pass.Reportf(fn, "option %s is not supported by :%s", name, fn.Name())
+			}
+			return nil
+		},
+	})
+}
+
+// literalProblem describes a schema check error without its sentinel prefix.
+func literalProblem(err error) string {
+	return strings.TrimPrefix(err.Error(), functions.ErrInvalidLiteral.Error()+": ")
+}
+
+// checkInvalidLiterals reports literal operands and option values of built-in
+// functions that their schema rejects, and variables given to options that
+// must be literals.
+func checkInvalidLiterals(pass *Pass) {
+	datamodel.Visit(pass.Message, &datamodel.Visitor{
+		FunctionRef: func(fn *datamodel.FunctionRef, _ datamodel.VisitContext, arg datamodel.ExpressionArg) func() {
+			schema, ok := builtinSchemas[fn.Name()]
+			if !ok || !pass.IsBuiltin(fn.Name()) {
+				return nil
+			}
+			if literal, ok := arg.(*datamodel.Literal); ok {
+				if err := schema.Operand.CheckLiteral(literal.Value()); err != nil {
+					pass.Reportf(literal, "%s of :%s", literalProblem(err), fn.Name())
+				}
+			}
+			options := fn.Options()
+			for _, name := range slices.Sorted(maps.Keys(options)) {
+				option, known := schema.Option(name)
+				if !known {
+					continue
+				}
+				switch value := options[name].(type) {
+				case *datamodel.Literal:
+					if err := option.CheckLiteral(value.Value()); err != nil {
+						pass.Reportf(value, "%s of :%s", literalProblem(err), fn.Name())
+					}
+				case *datamodel.VariableRef:
+					if option.LiteralOnly {
+						pass.Reportf(value, "option %s of :%s must be a literal", name, fn.Name())
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// pluralCategories are the CLDR plural categories a number selector can
+// match.
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// numberSelector describes a selector annotated with a built-in number
+// function.
+type numberSelector struct {
+	integer bool // :integer selects on integer values only
+	exact   bool // select=exact disables plural categories
+}
+
+// numberSelectors returns the number selector at each selector position, or
+// nil for positions not annotated with built-in :number or :integer.
+func numberSelectors(pass *Pass, msg *datamodel.SelectMessage) []*numberSelector {
+	selectors := msg.Selectors()
+	result := make([]*numberSelector, len(selectors))
+	for i, selector := range selectors {
+		fn := pass.Annotation(selector.Name())
+		if fn == nil || (fn.Name() != "number" && fn.Name() != "integer") || !pass.IsBuiltin(fn.Name()) {
+			continue
+		}
+		sel := &numberSelector{integer: fn.Name() == "integer"}
+		if literal, ok := fn.Options()["select"].(*datamodel.Literal); ok {
+			sel.exact = literal.Value() == "exact"
+		}
+		result[i] = sel
+	}
+	return result
+}
+
+// exactNumber returns the number a key matches exactly. Selection compares
+// "=N" keys to the value by number, and other keys to its text: the decimal
+// text of exact decimal operands, trailing zeros included, so 1.50 matches
+// "1.50" and 1.5 matches 1.5; or the shortest text of float operands.
+func exactNumber(key string) (*apd.Decimal, bool) {
+	if suffix, ok := strings.CutPrefix(key, "="); ok {
+		d, _, err := apd.NewFromString(suffix)
+		return d, err == nil && d.Form == apd.Finite
+	}
+	d, _, err := apd.NewFromString(key)
+	if err != nil || d.Form != apd.Finite {
+		return nil, false
+	}
+	if d.Text('f') == key {
+		return d, true
+	}
+	if n, err := strconv.ParseFloat(key, 64); err == nil && formatSelectionNumber(n) == key {
+		return d, true
+	}
+	return nil, false
+}
+
+// isIntegerKey reports whether key, which matches the number d, can match an
+// :integer value: "=N" keys when N is an integer, and other keys when they
+// have no fraction digits.
+func isIntegerKey(key string, d *apd.Decimal) bool {
+	if strings.HasPrefix(key, "=") {
+		d, _ = new(apd.Decimal).Reduce(d)
+	}
+	return d.Exponent >= 0
+}
+
+// formatSelectionNumber formats n the way number selection compares it to
+// keys when n is not an exact decimal.
+func formatSelectionNumber(n float64) string {
+	if n == float64(int64(n)) {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+// checkImpossibleNumberKeys reports keys of :number and :integer selectors
+// that are neither an exactly matching number nor a plural category.
+func checkImpossibleNumberKeys(pass *Pass) {
+	msg, ok := pass.Message.(*datamodel.SelectMessage)
+	if !ok {
+		return
+	}
+	selectors := numberSelectors(pass, msg)
+	for _, variant := range msg.Variants() {
+		for i, key := range variant.Keys() {
+			literal, ok := key.(*datamodel.Literal)
+			if !ok || i >= len(selectors) || selectors[i] == nil {
+				continue
+			}
+			sel, value := selectors[i], literal.Value()
+			if slices.Contains(pluralCategories, value) {
+				if sel.exact {
+					pass.Reportf(literal, "key %s never matches: plural categories are not selected with select=exact", value)
+				}
+				continue
+			}
+			n, ok := exactNumber(value)
+			switch {
+			case !ok:
+				pass.Reportf(literal, "key %s never matches: it is neither a plural category nor a number as formatted for selection", value)
+			case sel.integer && !isIntegerKey(value, n):
+				pass.Reportf(literal, "key %s never matches an :integer value", value)
+			}
+		}
+	}
+}
+
+// checkShadowedVariants reports variants that can never be selected because
+// one of their keys loses to an equivalent key at the same position. Number
+// selection prefers =N keys to plain numbers and otherwise returns the first
+// matching key, and string selection returns the first key equal under NFC,
+// so of two keys that match the same values only one is ever selected.
+// Identical keys are left to ValidateMessage.
+func checkShadowedVariants(pass *Pass) {
+	msg, ok := pass.Message.(*datamodel.SelectMessage)
+	if !ok {
+		return
+	}
+	selectors := msg.Selectors()
+	numbers := numberSelectors(pass, msg)
+	variants := msg.Variants()
+
+	for i := range selectors {
+		var equivalent func(a, b string) bool
+		switch {
+		case numbers[i] != nil:
+			// Other keys match by text, so only an =N key matches
+			// every value of an equal key.
+			equivalent = func(a, b string) bool {
+				x, okA := exactNumber(a)
+				y, okB := exactNumber(b)
+				exact := strings.HasPrefix(a, "=") || strings.HasPrefix(b, "=")
+				return okA && okB && exact && x.Cmp(y) == 0
+			}
+		case isStringSelector(pass, selectors[i].Name()):
+			equivalent = func(a, b string) bool { return norm.NFC.String(a) == norm.NFC.String(b) }
+		default:
+			continue
+		}
+
+		// Keys in variant order, the order selection sees them.
+		var keys []string
+		for _, variant := range variants {
+			if literal, ok := keyAt(variant, i).(*datamodel.Literal); ok && !slices.Contains(keys, literal.Value()) {
+				keys = append(keys, literal.Value())
+			}
+		}
+
+		for _, variant := range variants {
+			literal, ok := keyAt(variant, i).(*datamodel.Literal)
+			if !ok {
+				continue
+			}
+			key := literal.Value()
+			winner := key
+			for _, candidate := range keys {
+				if candidate == key || !equivalent(candidate, key) {
+					continue
+				}
+				if prefersKey(candidate, key, keys, numbers[i] != nil) {
+					winner = candidate
+					break
+				}
+			}
+			if winner != key {
+				pass.Reportf(variant, "variant is never selected: key %s is shadowed by the equivalent key %s", key, winner)
+			}
+		}
+	}
+}
+
+// prefersKey reports whether selection returns candidate rather than key when
+// both match.
+func prefersKey(candidate, key string, keys []string, number bool) bool {
+	if number {
+		candidateExact := strings.HasPrefix(candidate, "=")
+		if candidateExact != strings.HasPrefix(key, "=") {
+			return candidateExact
+		}
+	}
+	return slices.Index(keys, candidate) < slices.Index(keys, key)
+}
+
+func isStringSelector(pass *Pass, name string) bool {
+	fn := pass.Annotation(name)
+	return fn != nil && fn.Name() == "string" && pass.IsBuiltin("string")
+}
+
+func keyAt(variant datamodel.Variant, i int) datamodel.VariantKey {
+	keys := variant.Keys()
+	if i >= len(keys) {
+		return nil
+	}
+	return keys[i]
+}
+
+// checkUnbalancedMarkup reports, for each pattern, close markup without a
+// matching open and open markup that is never closed. Markup must nest: a
+// close that skips over open elements leaves those elements unclosed.
+func checkUnbalancedMarkup(pass *Pass) {
+	var open []*datamodel.Markup
+	datamodel.Visit(pass.Message, &datamodel.Visitor{
+		Pattern: func(datamodel.Pattern) func() {
+			open = open[:0]
+			return func() {
+				for _, markup := range open {
+					pass.Reportf(markup, "{#%s} is never closed", markup.Name())
+				}
+				open = open[:0]
+			}
+		},
+		Markup: func(markup *datamodel.Markup, _ datamodel.VisitContext) func() {
+			switch markup.Kind() {
+			case datamodel.MarkupOpen:
+				open = append(open, markup)
+			case datamodel.MarkupClose:
+				i := len(open) - 1
+				for i >= 0 && open[i].Name() != markup.Name() {
+					i--
+				}
+				if i < 0 {
+					pass.Reportf(markup, "{/%s} has no matching {#%s}", markup.Name(), markup.Name())
+					return nil
+				}
+				for _, unclosed := range open[i+1:] {
+					pass.Reportf(unclosed, "{#%s} is never closed", unclosed.Name())
+				}
+				open = open[:i]
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package messagevalue
+
+import (
+	"github.com/agentable/go-intl/datetimeformat"
+	"github.com/agentable/go-intl/numberformat"
+	"github.com/agentable/go-intl/pluralrules"
+	"github.com/kaptinlin/messageformat-go/internal/cache"
+)
+
+// DefaultFormatterCacheSize is the default number of number and date/time
+// formatters each kept by the formatter cache.
+const DefaultFormatterCacheSize = 512
+
+// Formatter caches shared by NumberValue and DateTimeValue construction.
+// go-intl formatters and plural rules are immutable once built, so one
+// instance serves every value with the same locale and normalized options.
+var (
+	numberFormatters   = cache.NewLRU[string, *numberFormatter](DefaultFormatterCacheSize)
+	dateTimeFormatters = cache.NewLRU[string, *dateTimeFormatter](DefaultFormatterCacheSize)
+)
+
+// numberFormatter is a cached number formatting plan.
+type numberFormatter struct {
+	formatter   *numberformat.NumberFormat
+	locale      string
+	pluralRules *pluralrules.PluralRules
+}
+
+// dateTimeFormatter is a cached date/time formatting plan.
+type dateTimeFormatter struct {
+	formatter *datetimeformat.DateTimeFormat
+	locale    string
+	calendar  string
+	timeZone  string
+}
+
+// SetFormatterCacheSize sets how many number and date/time formatters are
+// each kept across formatting calls, evicting the least recently used ones.
+// The cache is shared by :number, :integer, :percent, :currency, :unit,
+// :date, :time and :datetime, and by the NumberValue and DateTimeValue
+// constructors. A size of zero or less disables caching and drops all cached
+// formatters. It is safe to call concurrently with formatting.
+func SetFormatterCacheSize(size int) {
+	numberFormatters.Resize(size)
+	dateTimeFormatters.Resize(size)
+}
+
+// FormatterCacheSize returns the capacity set by SetFormatterCacheSize.
+func FormatterCacheSize() int {
+	return numberFormatters.Capacity()
+}
@@ -0,0 +1,106 @@
+package messagevalue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFormatterCacheSize runs a test with a fresh formatter cache of size.
+func withFormatterCacheSize(t *testing.T, size int) {
+	t.Helper()
+	previous := FormatterCacheSize()
+	SetFormatterCacheSize(0)
+	SetFormatterCacheSize(size)
+	t.Cleanup(func() {
+		SetFormatterCacheSize(0)
+		SetFormatterCacheSize(previous)
+	})
+}
+
+func TestNumberValueReusesCachedFormatter(t *testing.T) {
+	withFormatterCacheSize(t, DefaultFormatterCacheSize)
+
+	first, err := NewNumberValue(1, "en", "$a", map[string]any{"minimumFractionDigits": 2})
+	require.NoError(t, err)
+	second, err := NewNumberValue(2, "en", "$b", map[string]any{"minimumFractionDigits": "2"})
+	require.NoError(t, err)
+	ordinal, err := NewNumberValue(3, "en", "$c", map[string]any{"minimumFractionDigits": 2, "select": "ordinal"})
+	require.NoError(t, err)
+	german, err := NewNumberValue(4, "de", "$d", map[string]any{"minimumFractionDigits": 2})
+	require.NoError(t, err)
+
+	assert.Same(t, first.formatter, second.formatter)
+	assert.Same(t, first.pluralRules, second.pluralRules)
+	assert.NotSame(t, first.pluralRules, ordinal.pluralRules)
+	assert.NotSame(t, first.formatter, german.formatter)
+	assert.Equal(t, map[string]any{"minimumFractionDigits": "2"}, second.Options())
+	assert.Equal(t, 3, numberFormatters.Len())
+}
+
+func TestDateTimeValueReusesCachedFormatter(t *testing.T) {
+	withFormatterCacheSize(t, DefaultFormatterCacheSize)
+
+	options := map[string]any{"dateStyle": "medium"}
+	first, err := NewDateTimeValue(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "en", "$a", options)
+	require.NoError(t, err)
+	second, err := NewDateTimeValue(time.Date(2025, 6, 7, 8, 9, 10, 0, time.UTC), "en", "$b", options)
+	require.NoError(t, err)
+	otherZone, err := NewDateTimeValue(time.Date(2025, 6, 7, 8, 9, 10, 0, time.FixedZone("", 3600)), "en", "$c", options)
+	require.NoError(t, err)
+
+	assert.Same(t, first.formatter, second.formatter)
+	assert.NotSame(t, first.formatter, otherZone.formatter)
+	firstString, err := first.ToString()
+	require.NoError(t, err)
+	secondString, err := second.ToString()
+	require.NoError(t, err)
+	assert.NotEqual(t, firstString, secondString)
+}
+
+func TestSetFormatterCacheSizeDisablesCache(t *testing.T) {
+	withFormatterCacheSize(t, 0)
+
+	first, err := NewNumberValue(1, "en", "$a", nil)
+	require.NoError(t, err)
+	second, err := NewNumberValue(1, "en", "$a", nil)
+	require.NoError(t, err)
+
+	assert.NotSame(t, first.formatter, second.formatter)
+	assert.Equal(t, 0, FormatterCacheSize())
+	assert.Equal(t, 0, numberFormatters.Len())
+	assert.Equal(t, 0, dateTimeFormatters.Len())
+}
+
+func TestFormatterCacheIsBounded(t *testing.T) {
+	withFormatterCacheSize(t, 2)
+
+	for _, locale := range []string{"en", "de", "fr", "es"} {
+		_, err := NewNumberValue(1, locale, "$a", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, numberFormatters.Len())
+}
+
+func TestFormatterCacheConcurrentUse(t *testing.T) {
+	withFormatterCacheSize(t, 4)
+
+	locales := []string{"en", "de", "fr", "es", "ja", "ar"}
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Go(func() {
+			for j := range 50 {
+				nv, err := NewNumberValue(j, locales[(i+j)%len(locales)], "$n", map[string]any{"maximumFractionDigits": j % 3})
+				if assert.NoError(t, err) {
+					_, err = nv.ToString()
+					assert.NoError(t, err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, numberFormatters.Len(), 4)
+}
@@ -61,21 +61,43 @@ func NewDateTimeValueWithDir(value time.Time, locale, source string, dir bidi.Di
 		}
 		formatOptions.TimeZone = stringPtr(timeZone)
 	}
-	formatter, err := datetimeformat.New(intlbridge.ParseLocale(locale), formatOptions)
+	plan, err := dateTimeFormatPlan(locale, formatOptions)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidDateTimeOptions, err)
+		return nil, err
 	}
-	resolved := formatter.ResolvedOptions()
 	return &DateTimeValue{
 		value:     value,
-		locale:    resolved.Locale.String(),
+		locale:    plan.locale,
 		dir:       dir,
 		source:    source,
 		options:   cloneOptions(options),
+		calendar:  plan.calendar,
+		timeZone:  plan.timeZone,
+		formatter: plan.formatter,
+	}, nil
+}
+
+// dateTimeFormatPlan returns the formatter for locale and options, building
+// and caching it on first use.
+func dateTimeFormatPlan(locale string, formatOptions datetimeformat.Options) (*dateTimeFormatter, error) {
+	key := intlbridge.CacheKey(locale, formatOptions)
+	if plan, ok := dateTimeFormatters.Get(key); ok {
+		return plan, nil
+	}
+
+	formatter, err := datetimeformat.New(intlbridge.ParseLocale(locale), formatOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDateTimeOptions, err)
+	}
+	resolved := formatter.ResolvedOptions()
+	plan := &dateTimeFormatter{
+		formatter: formatter,
+		locale:    resolved.Locale.String(),
 		calendar:  resolved.Calendar,
 		timeZone:  resolved.TimeZone,
-		formatter: formatter,
-	}, nil
+	}
+	dateTimeFormatters.Add(key, plan)
+	return plan, nil
 }
 
 func (dtv *DateTimeValue) Type() string {
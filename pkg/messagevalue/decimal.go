@@ -0,0 +1,151 @@
+package messagevalue
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/agentable/go-intl/numberformat"
+	"github.com/agentable/go-intl/pluralrules"
+	"github.com/cockroachdb/apd/v3"
+)
+
+// Exact decimal numbers are formatted from their decimal text rather than
+// through float64, so that large integers and decimal fractions keep every
+// digit. The fraction digits they show, such as the zero of 1.50, are kept
+// when formatting and selecting unless the options set the digits.
+
+// maxFractionDigits is the largest fraction digit count a formatter accepts.
+const maxFractionDigits = 100
+
+// fractionDigitOptions are the options that decide the fraction digits of a
+// formatted number.
+var fractionDigitOptions = []string{
+	"minimumFractionDigits",
+	"maximumFractionDigits",
+	"minimumSignificantDigits",
+	"maximumSignificantDigits",
+	"roundingIncrement",
+}
+
+// exactDecimal returns the value of an *apd.Decimal, json.Number, *big.Int,
+// or *big.Rat as a finite decimal. A rational without a finite decimal
+// expansion, such as 1/3, is not exact.
+func exactDecimal(v any) (*apd.Decimal, bool) {
+	switch x := v.(type) {
+	case *apd.Decimal:
+		return x, x != nil && x.Form == apd.Finite
+	case apd.Decimal:
+		return &x, x.Form == apd.Finite
+	case json.Number:
+		d, _, err := apd.NewFromString(string(x))
+		return d, err == nil && d.Form == apd.Finite
+	case *big.Int:
+		if x == nil {
+			return nil, false
+		}
+		return apd.NewWithBigInt(new(apd.BigInt).SetMathBigInt(x), 0), true
+	case big.Int:
+		return apd.NewWithBigInt(new(apd.BigInt).SetMathBigInt(&x), 0), true
+	case *big.Rat:
+		return ratDecimal(x)
+	case big.Rat:
+		return ratDecimal(&x)
+	}
+	return nil, false
+}
+
+// ratDecimal converts a rational with a finite decimal expansion.
+func ratDecimal(r *big.Rat) (*apd.Decimal, bool) {
+	if r == nil {
+		return nil, false
+	}
+	digits, exact := r.FloatPrec()
+	if !exact {
+		return nil, false
+	}
+	d, _, err := apd.NewFromString(r.FloatString(digits))
+	return d, err == nil
+}
+
+// decimalNumberFormatValue returns the formatter input of an exact decimal
+// type. Rationals without a finite expansion are cut after the most fraction
+// digits a formatter can show.
+func decimalNumberFormatValue(v any) (numberformat.Value, bool) {
+	text := ""
+	if d, ok := exactDecimal(v); ok {
+		text = d.Text('f')
+	} else if r, ok := v.(*big.Rat); ok && r != nil {
+		text = r.FloatString(maxFractionDigits)
+	} else if r, ok := v.(big.Rat); ok {
+		text = r.FloatString(maxFractionDigits)
+	} else {
+		return numberformat.Value{}, false
+	}
+	value, err := numberformat.Decimal(text)
+	if err != nil {
+		return numberformat.Value{}, false
+	}
+	return value, true
+}
+
+// visibleFractionDigits returns the number of fraction digits an exact
+// decimal shows, such as 2 for 1.50.
+func visibleFractionDigits(v any) int {
+	d, ok := exactDecimal(v)
+	if !ok || d.Exponent >= 0 {
+		return 0
+	}
+	return min(int(-d.Exponent), maxFractionDigits)
+}
+
+// withVisibleFractionDigits returns the formatting options of value: those
+// given, plus the visible fraction digits of an exact decimal formatted as a
+// plain number whose options leave the fraction digits open. The options of
+// the value itself are not changed, so later functions see them as given.
+func withVisibleFractionDigits(value any, options map[string]any) map[string]any {
+	digits := visibleFractionDigits(value)
+	if digits == 0 {
+		return options
+	}
+	if style, ok := options["style"]; ok && style != "decimal" {
+		return options
+	}
+	for _, name := range fractionDigitOptions {
+		if _, ok := options[name]; ok {
+			return options
+		}
+	}
+
+	formatOptions := make(map[string]any, len(options)+2)
+	for name, value := range options {
+		formatOptions[name] = value
+	}
+	formatOptions["minimumFractionDigits"] = digits
+	formatOptions["maximumFractionDigits"] = max(digits, 3)
+	return formatOptions
+}
+
+// pluralIntegerLimit bounds the integers passed to plural rules as floats.
+// Beyond it a float64 drops the low digits that plural rules read.
+var pluralIntegerLimit = apd.New(1<<53, 0)
+
+// pluralDecimalOperand returns the plural rule operand of an exact decimal.
+// Integers too large for a float64 are reduced to one with the same low
+// digits, which is all that plural rules look at in such numbers.
+func pluralDecimalOperand(d *apd.Decimal) pluralrules.Operand {
+	var integer, fraction, abs apd.Decimal
+	d.Modf(&integer, &fraction)
+	if fraction.IsZero() && abs.Abs(&integer).Cmp(pluralIntegerLimit) >= 0 {
+		n, ok := new(big.Int).SetString(integer.Text('f'), 10)
+		if ok {
+			const modulus = 1_000_000_000_000_000
+			low := new(big.Int).Rem(n, big.NewInt(modulus)).Int64()
+			if low < 0 {
+				return pluralrules.Int(low - modulus)
+			}
+			return pluralrules.Int(low + modulus)
+		}
+	}
+	f, _ := d.Float64()
+	return pluralrules.Float(f)
+}
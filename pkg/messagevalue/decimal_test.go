@@ -0,0 +1,101 @@
+package messagevalue
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecimal(t *testing.T, s string) *apd.Decimal {
+	t.Helper()
+
+	d, _, err := apd.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestNumberValueFormatsExactDecimals(t *testing.T) {
+	huge, ok := new(big.Int).SetString("12345678901234567890", 10)
+	require.True(t, ok)
+
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"apd integer", mustDecimal(t, "12345678901234567890"), "12345678901234567890"},
+		{"big.Int", huge, "12345678901234567890"},
+		{"visible fraction zeros", mustDecimal(t, "1.50"), "1.50"},
+		{"json.Number", json.Number("0.10"), "0.10"},
+		{"finite rational", big.NewRat(3, 2), "1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nv, err := NewNumberValue(tt.value, "en", "test", nil)
+			require.NoError(t, err)
+
+			str, err := nv.ToString()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.ReplaceAll(str, ",", ""))
+			assert.Empty(t, nv.Options(), "visible digits are not stored as options")
+		})
+	}
+}
+
+func TestNumberValueSelectsExactDecimals(t *testing.T) {
+	huge, ok := new(big.Int).SetString("100000000000000000001", 10)
+	require.True(t, ok)
+
+	tests := []struct {
+		name     string
+		value    any
+		options  map[string]any
+		keys     []string
+		expected []string
+	}{
+		{"numeric key", mustDecimal(t, "1.50"), nil, []string{"=1.5", "1.50"}, []string{"=1.5"}},
+		{"literal key keeps fraction zeros", mustDecimal(t, "1.50"), nil, []string{"1.5", "1.50"}, []string{"1.50"}},
+		{"integer does not match fraction zeros", mustDecimal(t, "1.0"), nil, []string{"1"}, []string{}},
+		{"large integer", huge, nil, []string{"=100000000000000000000", "=100000000000000000001"}, []string{"=100000000000000000001"}},
+		{"percent", mustDecimal(t, "0.25"), map[string]any{"style": "percent"}, []string{"=0.25", "=25"}, []string{"=25"}},
+		{"exact select", json.Number("2.50"), map[string]any{"select": "exact"}, []string{"other", "2.50"}, []string{"2.50"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nv, err := NewNumberValue(tt.value, "en", "test", tt.options)
+			require.NoError(t, err)
+
+			selected, err := nv.SelectKeys(tt.keys)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selected)
+		})
+	}
+}
+
+func TestWithVisibleFractionDigits(t *testing.T) {
+	options := map[string]any{"useGrouping": "always"}
+	formatOptions := withVisibleFractionDigits(mustDecimal(t, "2.500"), options)
+	assert.Equal(t, map[string]any{
+		"useGrouping":           "always",
+		"minimumFractionDigits": 3,
+		"maximumFractionDigits": 3,
+	}, formatOptions)
+	assert.Len(t, options, 1)
+
+	for _, options := range []map[string]any{
+		{"style": "percent"},
+		{"minimumSignificantDigits": 2},
+		{"roundingIncrement": 5},
+	} {
+		assert.Equal(t, options, withVisibleFractionDigits(mustDecimal(t, "2.5"), options))
+	}
+	assert.Nil(t, withVisibleFractionDigits(mustDecimal(t, "25"), nil))
+	assert.Nil(t, withVisibleFractionDigits(2.50, nil))
+}
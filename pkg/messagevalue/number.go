@@ -10,6 +10,7 @@ import (
 
 	"github.com/agentable/go-intl/numberformat"
 	"github.com/agentable/go-intl/pluralrules"
+	"github.com/cockroachdb/apd/v3"
 	"github.com/kaptinlin/messageformat-go/internal/intlbridge"
 	"github.com/kaptinlin/messageformat-go/pkg/bidi"
 )
@@ -66,47 +67,79 @@ func newNumberValue(value any, locale, source string, dir bidi.Direction, option
 	if !ok {
 		return nil, fmt.Errorf("%w: %T", ErrInvalidNumber, value)
 	}
-	formatter, err := numberformat.New(intlbridge.ParseLocale(locale), intlbridge.NumberOptions(options))
+	plan, err := numberFormatPlan(locale, withVisibleFractionDigits(value, options), selectable)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidNumberOptions, err)
-	}
-	resolved := formatter.ResolvedOptions()
-	resolvedLocale := resolved.Locale.String()
-	selectionRules, err := newPluralRules(resolvedLocale, resolved, options, selectable)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidNumberOptions, err)
+		return nil, err
 	}
 
 	return &NumberValue{
 		value:       value,
-		locale:      resolvedLocale,
+		locale:      plan.locale,
 		dir:         dir,
 		source:      source,
 		options:     cloneOptions(options),
 		selectable:  selectable,
-		formatter:   formatter,
+		formatter:   plan.formatter,
 		formatValue: formatValue,
-		pluralRules: selectionRules,
+		pluralRules: plan.pluralRules,
 	}, nil
 }
 
+// numberFormatPlan returns the formatter and plural rules for locale and
+// options, building and caching them on first use.
+func numberFormatPlan(locale string, options map[string]any, selectable bool) (*numberFormatter, error) {
+	formatOptions := intlbridge.NumberOptions(options)
+	ruleType := pluralRuleType(options, selectable)
+	key := intlbridge.CacheKey(locale+"|"+ruleType, formatOptions)
+	if plan, ok := numberFormatters.Get(key); ok {
+		return plan, nil
+	}
+
+	formatter, err := numberformat.New(intlbridge.ParseLocale(locale), formatOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNumberOptions, err)
+	}
+	resolved := formatter.ResolvedOptions()
+	resolvedLocale := resolved.Locale.String()
+	selectionRules, err := newPluralRules(resolvedLocale, resolved, ruleType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNumberOptions, err)
+	}
+
+	plan := &numberFormatter{
+		formatter:   formatter,
+		locale:      resolvedLocale,
+		pluralRules: selectionRules,
+	}
+	numberFormatters.Add(key, plan)
+	return plan, nil
+}
+
+// pluralRuleType returns the plural rule type used for selection, or "" when
+// the value does not select by plural category.
+func pluralRuleType(options map[string]any, selectable bool) string {
+	switch {
+	case !selectable || options["select"] == "exact":
+		return ""
+	case options["select"] == "ordinal":
+		return string(pluralrules.Ordinal)
+	default:
+		return string(pluralrules.Cardinal)
+	}
+}
+
 // newPluralRules compiles selection with the formatter's resolved locale and digit options.
 // TypeScript original code:
 // cat ??= new Intl.PluralRules(locales, pluralOpt).select(Number(numVal));
 func newPluralRules(
 	locale string,
 	resolved numberformat.ResolvedOptions,
-	options map[string]any,
-	selectable bool,
+	ruleType string,
 ) (*pluralrules.PluralRules, error) {
-	if !selectable || options["select"] == "exact" {
+	if ruleType == "" {
 		return nil, nil
 	}
 
-	ruleType := string(pluralrules.Cardinal)
-	if options["select"] == "ordinal" {
-		ruleType = string(pluralrules.Ordinal)
-	}
 	roundingMode := string(resolved.RoundingMode)
 	roundingPriority := string(resolved.RoundingPriority)
 	trailingZeroDisplay := string(resolved.TrailingZeroDisplay)
@@ -165,6 +198,27 @@ func (nv *NumberValue) CanSelect() bool {
 	return nv.selectable
 }
 
+// PluralCategories returns the CLDR plural categories that the plural rules
+// of this value's locale and options can select, in the order zero, one,
+// two, few, many, other. It is nil when the value does not select by plural
+// category.
+func (nv *NumberValue) PluralCategories() []string {
+	if !nv.selectable || nv.pluralRules == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, category := range nv.pluralRules.ResolvedOptions().PluralCategories {
+		seen[mapPluralForm(category)] = true
+	}
+	categories := make([]string, 0, len(seen))
+	for _, category := range [...]string{"zero", "one", "two", "few", "many", "other"} {
+		if seen[category] {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
 func (nv *NumberValue) ToString() (string, error) {
 	return nv.formatter.Format(nv.formatValue), nil
 }
@@ -204,7 +258,7 @@ func numberFormatValue(v any) (numberformat.Value, bool) {
 	case big.Float:
 		return bigFloatNumberFormatValue(&x)
 	}
-	return numberformat.Value{}, false
+	return decimalNumberFormatValue(v)
 }
 
 func bigFloatNumberFormatValue(v *big.Float) (numberformat.Value, bool) {
@@ -294,9 +348,19 @@ func (nv *NumberValue) SelectKeys(keys []string) ([]string, error) {
 		return nil, ErrNumberNotSelectable
 	}
 
+	// Exact decimals are compared and matched by their decimal text
 	num, ok := numberAsFloat(nv.value)
-	if !ok {
-		return []string{}, nil
+	exact, isExact := exactDecimal(nv.value)
+	switch {
+	case ok:
+	case isExact:
+		num, _ = exact.Float64()
+	default:
+		r, isRat := nv.value.(*big.Rat)
+		if !isRat || r == nil {
+			return []string{}, nil
+		}
+		num, _ = r.Float64()
 	}
 
 	// TypeScript: if (options.style === 'percent') { numVal *= 100; }
@@ -304,6 +368,10 @@ func (nv *NumberValue) SelectKeys(keys []string) ([]string, error) {
 	if style, hasStyle := nv.options["style"]; hasStyle {
 		if styleStr, ok := style.(string); ok && styleStr == "percent" {
 			numVal = num * 100
+			if isExact {
+				exact = new(apd.Decimal).Set(exact)
+				exact.Exponent += 2
+			}
 		}
 	}
 
@@ -311,7 +379,11 @@ func (nv *NumberValue) SelectKeys(keys []string) ([]string, error) {
 	// 1. Check for exact numeric match with =N syntax (e.g., =0, =1, =42)
 	for _, key := range keys {
 		if suffix, ok := strings.CutPrefix(key, "="); ok {
-			if keyNum, err := strconv.ParseFloat(suffix, 64); err == nil && keyNum == numVal {
+			if isExact {
+				if keyNum, _, err := apd.NewFromString(suffix); err == nil && keyNum.Cmp(exact) == 0 {
+					return []string{key}, nil
+				}
+			} else if keyNum, err := strconv.ParseFloat(suffix, 64); err == nil && keyNum == numVal {
 				return []string{key}, nil
 			}
 		}
@@ -319,6 +391,9 @@ func (nv *NumberValue) SelectKeys(keys []string) ([]string, error) {
 
 	// 2. Check for exact string match (TypeScript: if (keys.has(str)) return str)
 	valueStr := formatNumberForSelection(numVal)
+	if isExact {
+		valueStr = exact.Text('f')
+	}
 	for _, key := range keys {
 		if key == valueStr {
 			return []string{key}, nil
@@ -336,7 +411,11 @@ func (nv *NumberValue) SelectKeys(keys []string) ([]string, error) {
 	if nv.pluralRules == nil {
 		return []string{}, nil
 	}
-	category := nv.pluralRules.Select(pluralrules.Float(numVal))
+	operand := pluralrules.Float(numVal)
+	if isExact {
+		operand = pluralDecimalOperand(exact)
+	}
+	category := nv.pluralRules.Select(operand)
 	pluralCategory := mapPluralForm(category)
 	for _, key := range keys {
 		if key == pluralCategory {
@@ -0,0 +1,93 @@
+package messagevalue
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Static errors to avoid dynamic error creation
+var (
+	// ErrMismatchedMarkup indicates close markup that does not close the
+	// innermost open markup.
+	ErrMismatchedMarkup = errors.New("mismatched markup")
+
+	// ErrUnclosedMarkup indicates open markup without close markup.
+	ErrUnclosedMarkup = errors.New("unclosed markup")
+)
+
+// PartNode is a node of the tree that PartsTree builds from formatted parts.
+// An element node holds open or standalone markup and the nodes between the
+// open and its close as children; a leaf node holds any other part.
+type PartNode struct {
+	// Part is the part of a leaf, or the open or standalone markup of an
+	// element.
+	Part MessagePart
+
+	// Close is the close markup of a paired element. It is nil for leaves,
+	// standalone markup, and unclosed open markup.
+	Close *MarkupPart
+
+	// Children are the nodes between an open and its close.
+	Children []*PartNode
+}
+
+// Markup returns the markup of an element node, or nil for a leaf.
+func (n *PartNode) Markup() *MarkupPart {
+	markup, _ := n.Part.(*MarkupPart)
+	return markup
+}
+
+// IsElement reports whether n holds markup.
+func (n *PartNode) IsElement() bool {
+	return n.Markup() != nil
+}
+
+// PartsTree nests formatted parts, such as those of FormatToParts, into a
+// tree. Open and close markup pair by name into an element whose children
+// are the parts between them, and standalone markup becomes an element
+// without children.
+//
+// Markup that does not pair up is reported in the joined error, and the tree
+// is still built: close markup that matches an outer open closes the
+// elements inside it, which are reported as unclosed; close markup that
+// matches no open is reported as mismatched and dropped; open markup that is
+// never closed is reported as unclosed and keeps the rest of the parts as
+// children.
+func PartsTree(parts []MessagePart) ([]*PartNode, error) {
+	root := &PartNode{}
+	stack := []*PartNode{root}
+	var errs []error
+	for _, part := range parts {
+		node := &PartNode{Part: part}
+		parent := stack[len(stack)-1]
+		markup, ok := part.(*MarkupPart)
+		if !ok || markup.Kind() == "standalone" {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+
+		switch markup.Kind() {
+		case "open":
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case "close":
+			open := len(stack) - 1
+			for open > 0 && stack[open].Markup().Name() != markup.Name() {
+				open--
+			}
+			if open == 0 {
+				errs = append(errs, fmt.Errorf("%w: {/%s} closes no open markup", ErrMismatchedMarkup, markup.Name()))
+				continue
+			}
+			for _, unclosed := range stack[open+1:] {
+				errs = append(errs, fmt.Errorf("%w: {#%s} is closed by {/%s}", ErrUnclosedMarkup, unclosed.Markup().Name(), markup.Name()))
+			}
+			stack[open].Close = markup
+			stack = stack[:open]
+		}
+	}
+	for _, unclosed := range stack[1:] {
+		errs = append(errs, fmt.Errorf("%w: {#%s}", ErrUnclosedMarkup, unclosed.Markup().Name()))
+	}
+	return root.Children, errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package messagevalue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// treeString writes nodes as text with markup in brackets.
+func treeString(nodes []*PartNode) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		markup := node.Markup()
+		switch {
+		case markup == nil:
+			b.WriteString(node.Part.(interface{ Text() string }).Text())
+		case markup.Kind() == "standalone":
+			b.WriteString("[" + markup.Name() + "/]")
+		default:
+			b.WriteString("[" + markup.Name() + "]" + treeString(node.Children))
+			if node.Close != nil {
+				b.WriteString("[/" + node.Close.Name() + "]")
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestPartsTree(t *testing.T) {
+	open := func(name string) MessagePart { return NewMarkupPart("open", name, "", "", nil) }
+	closing := func(name string) MessagePart { return NewMarkupPart("close", name, "", "", nil) }
+	text := func(s string) MessagePart { return NewTextPart(s, s, "en") }
+
+	tests := []struct {
+		name     string
+		parts    []MessagePart
+		expected string
+		errs     []error
+	}{
+		{
+			name:     "nested",
+			parts:    []MessagePart{text("a"), open("b"), text("b"), open("i"), text("c"), closing("i"), closing("b"), text("d")},
+			expected: "a[b]b[i]c[/i][/b]d",
+		},
+		{
+			name:     "standalone",
+			parts:    []MessagePart{open("p"), NewMarkupPart("standalone", "br", "", "", nil), closing("p")},
+			expected: "[p][br/][/p]",
+		},
+		{
+			name:     "close without open",
+			parts:    []MessagePart{text("a"), closing("b"), text("c")},
+			expected: "ac",
+			errs:     []error{ErrMismatchedMarkup},
+		},
+		{
+			name:     "crossed markup",
+			parts:    []MessagePart{open("b"), open("i"), text("x"), closing("b"), closing("i")},
+			expected: "[b][i]x[/b]",
+			errs:     []error{ErrUnclosedMarkup, ErrMismatchedMarkup},
+		},
+		{
+			name:     "unclosed",
+			parts:    []MessagePart{open("b"), text("x")},
+			expected: "[b]x",
+			errs:     []error{ErrUnclosedMarkup},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := PartsTree(tt.parts)
+			assert.Equal(t, tt.expected, treeString(nodes))
+			if len(tt.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			joined, ok := err.(interface{ Unwrap() []error })
+			require.True(t, ok)
+			require.Len(t, joined.Unwrap(), len(tt.errs))
+			for i, want := range tt.errs {
+				assert.ErrorIs(t, joined.Unwrap()[i], want)
+			}
+		})
+	}
+}
+
+func TestPartNode(t *testing.T) {
+	markup := NewMarkupPart("open", "b", "", "", nil)
+	element := &PartNode{Part: markup}
+	leaf := &PartNode{Part: NewTextPart("x", "x", "en")}
+
+	assert.True(t, element.IsElement())
+	assert.Same(t, markup, element.Markup())
+	assert.False(t, leaf.IsElement())
+	assert.Nil(t, leaf.Markup())
+}
@@ -347,6 +347,18 @@ func TestNumberValueSelectKeys(t *testing.T) {
 	}
 }
 
+func TestNumberValuePluralCategories(t *testing.T) {
+	nv := mustNumberValue(t, 1, "en", "test", nil)
+	assert.Equal(t, []string{"one", "other"}, nv.PluralCategories())
+
+	nv = mustNumberValue(t, 1, "en", "test", map[string]any{"select": "exact"})
+	assert.Nil(t, nv.PluralCategories())
+
+	nv, err := NewNumberValueWithSelection(1, "en", "test", bidi.DirAuto, nil, false)
+	require.NoError(t, err)
+	assert.Nil(t, nv.PluralCategories())
+}
+
 func TestFallbackValue(t *testing.T) {
 	fv := NewFallbackValue("$name", "en")
 
@@ -0,0 +1,311 @@
+package syntax
+
+import (
+	"cmp"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/kaptinlin/messageformat-go/internal/cst"
+)
+
+// builder converts the internal concrete syntax tree into public nodes,
+// filling the source between child nodes with whitespace and junk tokens.
+type builder struct {
+	source string
+}
+
+// node returns a node of kind spanning at least start to end and its
+// children. Children are sorted, and the source between them is covered by
+// whitespace tokens or, when it is not whitespace, junk tokens.
+func (b *builder) node(kind Kind, start, end int, children ...*Node) *Node {
+	children = slices.DeleteFunc(children, func(child *Node) bool { return child == nil })
+	slices.SortStableFunc(children, func(x, y *Node) int { return cmp.Compare(x.Start, y.Start) })
+	if len(children) > 0 {
+		start = min(start, children[0].Start)
+		end = max(end, children[len(children)-1].End)
+	}
+
+	n := &Node{Kind: kind, Start: start, End: end}
+	pos := start
+	for _, child := range children {
+		if child.Start < pos {
+			// Overlapping recovery nodes: keep the source covered once
+			if child.End <= pos || !child.IsToken() {
+				continue
+			}
+			child = b.token(child.Kind, pos, child.End)
+		}
+		n.Children = append(n.Children, b.gap(pos, child.Start)...)
+		n.Children = append(n.Children, child)
+		pos = child.End
+	}
+	n.Children = append(n.Children, b.gap(pos, end)...)
+	return n
+}
+
+// token returns a token of kind spanning start to end, or nil when the span
+// is empty.
+func (b *builder) token(kind Kind, start, end int) *Node {
+	if start >= end {
+		return nil
+	}
+	return &Node{Kind: kind, Start: start, End: end, Text: b.source[start:end]}
+}
+
+// syntax returns a token for a syntax element of the internal tree.
+func (b *builder) syntax(kind Kind, s *cst.Syntax) *Node {
+	if s == nil {
+		return nil
+	}
+	return b.token(kind, s.Start(), s.End())
+}
+
+// gap returns whitespace and junk tokens covering start to end.
+func (b *builder) gap(start, end int) []*Node {
+	var tokens []*Node
+	for pos := start; pos < end; {
+		r, size := utf8.DecodeRuneInString(b.source[pos:])
+		ws := cst.IsWhitespaceChar(r) || cst.IsBidiChar(r)
+		next := pos + size
+		for next < end {
+			r, size := utf8.DecodeRuneInString(b.source[next:])
+			if (cst.IsWhitespaceChar(r) || cst.IsBidiChar(r)) != ws {
+				break
+			}
+			next += size
+		}
+		kind := KindJunk
+		if ws {
+			kind = KindWhitespace
+		}
+		tokens = append(tokens, b.token(kind, pos, min(next, end)))
+		pos = next
+	}
+	return tokens
+}
+
+// message converts a message, which spans the whole source.
+func (b *builder) message(msg cst.Message) *Node {
+	var children []*Node
+	var kind Kind
+	switch m := msg.(type) {
+	case *cst.SimpleMessage:
+		kind = KindSimpleMessage
+		pattern := m.Pattern()
+		children = append(children, b.pattern(&pattern))
+	case *cst.ComplexMessage:
+		kind = KindComplexMessage
+		children = append(children, b.declarations(m.Declarations())...)
+		pattern := m.Pattern()
+		children = append(children, b.pattern(&pattern))
+	case *cst.SelectMessage:
+		kind = KindSelectMessage
+		children = append(children, b.declarations(m.Declarations())...)
+		match := m.Match()
+		children = append(children, b.syntax(KindKeyword, &match))
+		for _, selector := range m.Selectors() {
+			children = append(children, b.variable(&selector))
+		}
+		for _, variant := range m.Variants() {
+			children = append(children, b.variant(&variant))
+		}
+	}
+	return b.node(kind, 0, len(b.source), children...)
+}
+
+func (b *builder) declarations(declarations []cst.Declaration) []*Node {
+	nodes := make([]*Node, 0, len(declarations))
+	for _, decl := range declarations {
+		switch d := decl.(type) {
+		case *cst.InputDeclaration:
+			keyword := d.Keyword()
+			value := b.value(d.Value())
+			n := b.node(KindInputDeclaration, d.Start(), d.End(), b.syntax(KindKeyword, &keyword), value)
+			if value != nil && value.Kind == KindExpression {
+				n.Name = expressionVariable(value)
+			}
+			nodes = append(nodes, n)
+		case *cst.LocalDeclaration:
+			keyword := d.Keyword()
+			target := b.value(d.Target())
+			n := b.node(KindLocalDeclaration, d.Start(), d.End(),
+				b.syntax(KindKeyword, &keyword),
+				target,
+				b.syntax(KindPunctuation, d.Equals()),
+				b.value(d.Value()),
+			)
+			if target != nil && target.Kind == KindVariable {
+				n.Name = target.Name
+			}
+			nodes = append(nodes, n)
+		case *cst.Junk:
+			nodes = append(nodes, b.token(KindJunk, d.Start(), d.End()))
+		}
+	}
+	return nodes
+}
+
+// expressionVariable returns the name of the variable operand of an
+// expression node, or "".
+func expressionVariable(expr *Node) string {
+	for _, child := range expr.Children {
+		if child.Kind == KindVariable {
+			return child.Name
+		}
+	}
+	return ""
+}
+
+// value converts an expression, operand, option value, or junk.
+func (b *builder) value(node cst.Node) *Node {
+	switch n := node.(type) {
+	case *cst.Expression:
+		return b.expression(n)
+	case *cst.Literal:
+		return b.literal(n)
+	case *cst.VariableRef:
+		return b.variable(n)
+	case *cst.FunctionRef:
+		return b.function(n)
+	case *cst.Junk:
+		return b.token(KindJunk, n.Start(), n.End())
+	}
+	return nil
+}
+
+func (b *builder) variant(v *cst.Variant) *Node {
+	children := make([]*Node, 0, len(v.Keys())+1)
+	for _, key := range v.Keys() {
+		switch k := key.(type) {
+		case *cst.Literal:
+			children = append(children, b.literal(k))
+		case *cst.CatchallKey:
+			children = append(children, b.node(KindCatchallKey, k.Start(), k.End(),
+				b.token(KindPunctuation, k.Start(), k.End())))
+		}
+	}
+	pattern := v.Value()
+	children = append(children, b.pattern(&pattern))
+	return b.node(KindVariant, v.Start(), v.End(), children...)
+}
+
+func (b *builder) pattern(p *cst.Pattern) *Node {
+	children := make([]*Node, 0, len(p.Body())+2)
+	for _, brace := range p.Braces() {
+		children = append(children, b.syntax(KindPunctuation, &brace))
+	}
+	for _, element := range p.Body() {
+		switch e := element.(type) {
+		case *cst.Text:
+			if text := b.token(KindText, e.Start(), e.End()); text != nil {
+				text.Value = e.Value()
+				children = append(children, text)
+			}
+		default:
+			children = append(children, b.value(element))
+		}
+	}
+	return b.node(KindPattern, p.Start(), p.End(), children...)
+}
+
+func (b *builder) expression(e *cst.Expression) *Node {
+	children := make([]*Node, 0, len(e.Braces())+len(e.Attributes())+3)
+	for _, brace := range e.Braces() {
+		children = append(children, b.syntax(KindPunctuation, &brace))
+	}
+	if e.Arg() != nil {
+		children = append(children, b.value(e.Arg()))
+	}
+	if e.FunctionRef() != nil {
+		children = append(children, b.value(e.FunctionRef()))
+	}
+	if e.Markup() != nil {
+		children = append(children, b.markup(e.Markup()))
+	}
+	for _, attr := range e.Attributes() {
+		children = append(children, b.attribute(&attr))
+	}
+	return b.node(KindExpression, e.Start(), e.End(), children...)
+}
+
+func (b *builder) literal(l *cst.Literal) *Node {
+	var children []*Node
+	start, end := l.Start(), l.End()
+	if open := l.Open(); open != nil {
+		children = append(children, b.syntax(KindPunctuation, open))
+		start = open.End()
+	}
+	if close := l.Close(); close != nil {
+		children = append(children, b.syntax(KindPunctuation, close))
+		end = close.Start()
+	}
+	children = append(children, b.token(KindContent, start, end))
+	n := b.node(KindLiteral, l.Start(), l.End(), children...)
+	n.Value = l.Value()
+	return n
+}
+
+func (b *builder) variable(v *cst.VariableRef) *Node {
+	open := v.Open()
+	n := b.node(KindVariable, v.Start(), v.End(),
+		b.syntax(KindPunctuation, &open),
+		b.token(KindName, open.End(), v.End()),
+	)
+	n.Name = v.Name()
+	return n
+}
+
+func (b *builder) function(f *cst.FunctionRef) *Node {
+	open := f.Open()
+	children := append([]*Node{b.syntax(KindPunctuation, &open)}, b.identifier(f.Name())...)
+	for _, option := range f.Options() {
+		children = append(children, b.option(&option))
+	}
+	n := b.node(KindFunction, f.Start(), f.End(), children...)
+	n.Name = f.Name().String()
+	return n
+}
+
+func (b *builder) markup(m *cst.Markup) *Node {
+	open := m.Open()
+	children := append([]*Node{b.syntax(KindPunctuation, &open)}, b.identifier(m.Name())...)
+	for _, option := range m.Options() {
+		children = append(children, b.option(&option))
+	}
+	children = append(children, b.syntax(KindPunctuation, m.Close()))
+	n := b.node(KindMarkup, m.Start(), m.End(), children...)
+	n.Name = m.Name().String()
+	return n
+}
+
+func (b *builder) option(o *cst.Option) *Node {
+	children := append(b.identifier(o.Name()), b.syntax(KindPunctuation, o.Equals()), b.value(o.Value()))
+	n := b.node(KindOption, o.Start(), o.End(), children...)
+	n.Name = o.Name().String()
+	return n
+}
+
+func (b *builder) attribute(a *cst.Attribute) *Node {
+	open := a.Open()
+	children := append([]*Node{b.syntax(KindPunctuation, &open)}, b.identifier(a.Name())...)
+	children = append(children, b.syntax(KindPunctuation, a.Equals()))
+	if a.Value() != nil {
+		children = append(children, b.literal(a.Value()))
+	}
+	n := b.node(KindAttribute, a.Start(), a.End(), children...)
+	n.Name = a.Name().String()
+	return n
+}
+
+// identifier returns the name and separator tokens of an identifier.
+func (b *builder) identifier(id cst.Identifier) []*Node {
+	tokens := make([]*Node, 0, len(id))
+	for i := range id {
+		kind := KindName
+		if id[i].Value() == ":" && i == 1 {
+			kind = KindPunctuation
+		}
+		tokens = append(tokens, b.syntax(kind, &id[i]))
+	}
+	return tokens
+}
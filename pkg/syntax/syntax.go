@@ -0,0 +1,185 @@
+// Package syntax provides a lossless syntax tree for MessageFormat 2.0
+// messages, for editors, highlighters, and other tooling that needs source
+// positions and layout.
+//
+// Unlike the data model, the tree keeps every byte of the source: its tokens,
+// concatenated in order, reproduce the source exactly. Whitespace, including
+// the bidi marks the syntax allows around names, is kept as whitespace tokens,
+// and source that cannot be parsed is kept as junk tokens. MessageFormat 2.0
+// messages have no comment syntax, so the tree has no comment tokens.
+//
+// Parse is error tolerant: it always returns a tree, together with every
+// syntax error found rather than only the first.
+package syntax
+
+import (
+	stderrors "errors"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/kaptinlin/messageformat-go/internal/cst"
+	"github.com/kaptinlin/messageformat-go/pkg/errors"
+)
+
+// Kind identifies the syntax of a node or token.
+type Kind string
+
+// Node kinds. Nodes have children and no text of their own.
+const (
+	KindSimpleMessage    Kind = "simple-message"
+	KindComplexMessage   Kind = "complex-message"
+	KindSelectMessage    Kind = "select-message"
+	KindInputDeclaration Kind = "input-declaration"
+	KindLocalDeclaration Kind = "local-declaration"
+	KindVariant          Kind = "variant"
+	KindCatchallKey      Kind = "catchall-key"
+	KindPattern          Kind = "pattern"
+	KindExpression       Kind = "expression"
+	KindLiteral          Kind = "literal"
+	KindVariable         Kind = "variable"
+	KindFunction         Kind = "function"
+	KindMarkup           Kind = "markup"
+	KindOption           Kind = "option"
+	KindAttribute        Kind = "attribute"
+)
+
+// Token kinds. Tokens are the leaves of the tree.
+const (
+	KindWhitespace  Kind = "whitespace"  // Whitespace and bidi marks between other tokens
+	KindKeyword     Kind = "keyword"     // .input, .local, or .match
+	KindPunctuation Kind = "punctuation" // { } {{ }} $ : = @ # / | *
+	KindName        Kind = "name"        // A name, or one part of a namespaced identifier
+	KindText        Kind = "text"        // Pattern text, with escapes as written
+	KindContent     Kind = "content"     // Literal content, without the | quotes
+	KindJunk        Kind = "junk"        // Source that could not be parsed
+)
+
+// IsToken reports whether k is a token kind.
+func (k Kind) IsToken() bool {
+	switch k {
+	case KindWhitespace, KindKeyword, KindPunctuation, KindName, KindText, KindContent, KindJunk:
+		return true
+	}
+	return false
+}
+
+// Node is a node or token of the syntax tree. Offsets are byte offsets into
+// the parsed source.
+type Node struct {
+	Kind  Kind
+	Start int
+	End   int
+
+	// Text is the source text of a token, and empty for nodes.
+	Text string
+
+	// Name is the variable name of variables and declarations, and the
+	// identifier of functions, markup, options, and attributes.
+	Name string
+
+	// Value is the text of text tokens and the value of literals, with
+	// escapes decoded.
+	Value string
+
+	// Children are the child nodes and tokens, in source order. Tokens have
+	// no children.
+	Children []*Node
+}
+
+// IsToken reports whether n is a token.
+func (n *Node) IsToken() bool {
+	return n.Kind.IsToken()
+}
+
+// String returns the source text the node spans.
+func (n *Node) String() string {
+	if n.IsToken() {
+		return n.Text
+	}
+	var sb strings.Builder
+	for token := range n.Tokens() {
+		sb.WriteString(token.Text)
+	}
+	return sb.String()
+}
+
+// Tokens returns the tokens of n in source order.
+func (n *Node) Tokens() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		n.tokens(yield)
+	}
+}
+
+func (n *Node) tokens(yield func(*Node) bool) bool {
+	if n.IsToken() {
+		return yield(n)
+	}
+	for _, child := range n.Children {
+		if !child.tokens(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Inspect calls f for n and, while f returns true, for each of its
+// descendants in source order. When f returns false for a node, its children
+// are skipped.
+func (n *Node) Inspect(f func(*Node) bool) {
+	if !f(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Inspect(f)
+	}
+}
+
+// Find returns the path from n to the innermost node or token that contains
+// offset, outermost first. A token contains the offsets from its start up to
+// and including its end, so an offset between two tokens finds the earlier
+// one. Find returns nil when offset is outside n.
+func (n *Node) Find(offset int) []*Node {
+	if offset < n.Start || offset > n.End {
+		return nil
+	}
+	path := []*Node{n}
+	for node := n; ; {
+		i := slices.IndexFunc(node.Children, func(child *Node) bool {
+			return child.Start <= offset && offset <= child.End
+		})
+		if i < 0 {
+			return path
+		}
+		node = node.Children[i]
+		path = append(path, node)
+	}
+}
+
+// Tree is a parsed message.
+type Tree struct {
+	Source string
+	Root   *Node // The message node, spanning the whole source
+	Errors []*errors.MessageSyntaxError
+}
+
+// Parse parses source into a lossless syntax tree. It always returns a tree;
+// the error joins every syntax error in the order it was found, and is nil
+// when source is a syntactically valid message. Data model errors, such as
+// duplicate declarations, are reported by datamodel.ValidateMessage.
+func Parse(source string) (*Tree, error) {
+	msg := cst.ParseCST(source, false)
+	tree := &Tree{
+		Source: source,
+		Root:   (&builder{source: source}).message(msg),
+		Errors: msg.Errors(),
+	}
+	if len(tree.Errors) == 0 {
+		return tree, nil
+	}
+	errs := make([]error, len(tree.Errors))
+	for i, err := range tree.Errors {
+		errs[i] = err
+	}
+	return tree, stderrors.Join(errs...)
+}
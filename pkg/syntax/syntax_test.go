@@ -0,0 +1,196 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLossless(t *testing.T) {
+	sources := []string{
+		"",
+		"Hello, world!",
+		"Hello {$name}!",
+		"{{quoted \\{ pattern}}",
+		".input {$n :number minimumFractionDigits=2} {{{$n}}}",
+		".local $x = {|a b| :string @attr} {{{$x}}}",
+		".input {$n :integer} .match $n one {{one}} * {{other}}",
+		".match $a $b\n1 * {{x}}\n* * {{y}}",
+		"{#link href=$url @track=|yes|}text{/link} {#br/}",
+		"{ ‎$x‏ :ns:fn }",
+		"{$x",
+		"{$x :} {$y",
+		"{|a| #b}",
+		".local x = {1} {{}}",
+		".match {$x} * {{}}",
+		"{{a}} junk",
+		".input {$x} .input",
+		"{|unterminated",
+		"}",
+	}
+
+	for _, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			tree, _ := Parse(source)
+			require.NotNil(t, tree.Root)
+			assert.Equal(t, source, tree.Root.String())
+			assert.Equal(t, 0, tree.Root.Start)
+			assert.Equal(t, len(source), tree.Root.End)
+
+			pos := 0
+			for token := range tree.Root.Tokens() {
+				assert.Equal(t, pos, token.Start, "token %q", token.Text)
+				assert.Equal(t, source[token.Start:token.End], token.Text)
+				assert.Empty(t, token.Children)
+				pos = token.End
+			}
+			assert.Equal(t, len(source), pos)
+
+			tree.Root.Inspect(func(n *Node) bool {
+				for _, child := range n.Children {
+					assert.True(t, n.Start <= child.Start && child.End <= n.End,
+						"%s %d-%d outside %s %d-%d", child.Kind, child.Start, child.End, n.Kind, n.Start, n.End)
+				}
+				return true
+			})
+		})
+	}
+}
+
+type summary struct {
+	kind  Kind
+	text  string
+	name  string
+	value string
+}
+
+func collect(root *Node, kinds ...Kind) []summary {
+	result := []summary{}
+	root.Inspect(func(n *Node) bool {
+		for _, kind := range kinds {
+			if n.Kind == kind {
+				result = append(result, summary{kind: n.Kind, text: n.String(), name: n.Name, value: n.Value})
+			}
+		}
+		return true
+	})
+	return result
+}
+
+func TestParseNodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		kinds  []Kind
+		want   []summary
+	}{
+		{
+			name:   "simple message",
+			source: "Hi \\{ {$user}",
+			kinds:  []Kind{KindSimpleMessage, KindText, KindVariable},
+			want: []summary{
+				{kind: KindSimpleMessage, text: "Hi \\{ {$user}"},
+				{kind: KindText, text: "Hi \\{ ", value: "Hi { "},
+				{kind: KindVariable, text: "$user", name: "user"},
+			},
+		},
+		{
+			name:   "declarations",
+			source: ".input {$n :number} .local $m = {$n} {{}}",
+			kinds:  []Kind{KindInputDeclaration, KindLocalDeclaration, KindKeyword, KindFunction},
+			want: []summary{
+				{kind: KindInputDeclaration, text: ".input {$n :number}", name: "n"},
+				{kind: KindKeyword, text: ".input"},
+				{kind: KindFunction, text: ":number", name: "number"},
+				{kind: KindLocalDeclaration, text: ".local $m = {$n}", name: "m"},
+				{kind: KindKeyword, text: ".local"},
+			},
+		},
+		{
+			name:   "variants",
+			source: ".input {$n :number} .match $n |one| {{a}} * {{b}}",
+			kinds:  []Kind{KindVariant, KindLiteral, KindCatchallKey},
+			want: []summary{
+				{kind: KindVariant, text: "|one| {{a}}"},
+				{kind: KindLiteral, text: "|one|", value: "one"},
+				{kind: KindVariant, text: "* {{b}}"},
+				{kind: KindCatchallKey, text: "*"},
+			},
+		},
+		{
+			name:   "options, markup, and attributes",
+			source: "{#a:link href=|x\\|y|/} {$v @id=1}",
+			kinds:  []Kind{KindMarkup, KindOption, KindAttribute, KindContent},
+			want: []summary{
+				{kind: KindMarkup, text: "#a:link href=|x\\|y|/", name: "a:link"},
+				{kind: KindOption, text: "href=|x\\|y|", name: "href"},
+				{kind: KindContent, text: "x\\|y"},
+				{kind: KindAttribute, text: "@id=1", name: "id"},
+				{kind: KindContent, text: "1"},
+			},
+		},
+		{
+			name:   "junk",
+			source: "{$x %} tail",
+			kinds:  []Kind{KindJunk},
+			want: []summary{
+				{kind: KindJunk, text: "%"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, _ := Parse(tt.source)
+			assert.Equal(t, tt.want, collect(tree.Root, tt.kinds...))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tree, err := Parse("{$x :} {$y")
+	require.Error(t, err)
+	require.Len(t, tree.Errors, 2)
+	assert.Equal(t, 5, tree.Errors[0].Start)
+	assert.Equal(t, 10, tree.Errors[1].Start)
+	for _, e := range tree.Errors {
+		assert.ErrorIs(t, err, e)
+	}
+
+	tree, err = Parse("{$x}")
+	require.NoError(t, err)
+	assert.Empty(t, tree.Errors)
+}
+
+func TestNodeFind(t *testing.T) {
+	tree, err := Parse("Hi {$user :string}")
+	require.NoError(t, err)
+
+	kinds := func(path []*Node) []Kind {
+		result := make([]Kind, len(path))
+		for i, n := range path {
+			result[i] = n.Kind
+		}
+		return result
+	}
+
+	assert.Equal(t, []Kind{KindSimpleMessage, KindPattern, KindExpression, KindVariable, KindName},
+		kinds(tree.Root.Find(6)))
+	assert.Equal(t, []Kind{KindSimpleMessage, KindPattern, KindExpression, KindFunction, KindName},
+		kinds(tree.Root.Find(12)))
+	assert.Equal(t, []Kind{KindSimpleMessage, KindPattern, KindText}, kinds(tree.Root.Find(3)))
+	assert.Nil(t, tree.Root.Find(100))
+}
+
+func TestNodeInspectSkipsChildren(t *testing.T) {
+	tree, err := Parse("{$a} {$b}")
+	require.NoError(t, err)
+
+	var visited []Kind
+	tree.Root.Inspect(func(n *Node) bool {
+		visited = append(visited, n.Kind)
+		return n.Kind != KindExpression
+	})
+	assert.Equal(t, []Kind{KindSimpleMessage, KindPattern, KindExpression, KindText, KindExpression}, visited)
+}
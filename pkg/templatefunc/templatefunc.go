@@ -0,0 +1,153 @@
+// Package templatefunc exposes catalog messages to Go templates.
+//
+// A Funcs value builds, per request locale, the FuncMap of a "msg" function
+// that formats a catalog message by ID with named values:
+//
+//	{{ msg "cart.items" "count" .Count }}
+//	{{ msg "greeting" .Values }}
+//
+// HTML returns a FuncMap for html/template whose messages render through an
+// htmlrender.Renderer, so markup becomes allowlisted tags and the result is
+// escaped by context like any template.HTML. Text returns a FuncMap for
+// text/template whose messages format as plain strings.
+//
+// html/template needs every function at parse time, so parse with the
+// FuncMap of any locale and set the request locale on a clone:
+//
+//	page := template.Must(template.New("page").Funcs(funcs.HTML("en")).Parse(src))
+//	t := template.Must(page.Clone()).Funcs(funcs.HTML(locale))
+package templatefunc
+
+import (
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"github.com/kaptinlin/messageformat-go/pkg/catalog"
+	"github.com/kaptinlin/messageformat-go/pkg/htmlrender"
+)
+
+// Static errors to avoid dynamic error creation
+var (
+	// ErrInvalidArguments indicates msg values that are neither one map nor
+	// name and value pairs.
+	ErrInvalidArguments = errors.New("invalid message arguments")
+)
+
+// FuncName is the name of the message function in the FuncMap.
+const FuncName = "msg"
+
+// ErrorHandler receives a message that the catalog does not have, with an
+// error matching catalog.ErrMessageNotFound, or the diagnostics of
+// formatting and rendering a message.
+type ErrorHandler func(locale, id string, err error)
+
+// Funcs builds template functions backed by a catalog.
+type Funcs struct {
+	catalog  *catalog.Catalog
+	renderer *htmlrender.Renderer
+	onError  ErrorHandler
+}
+
+// Option configures Funcs.
+type Option func(*Funcs)
+
+// WithRenderer sets the renderer of HTML messages. The default renders with
+// htmlrender.DefaultElements.
+func WithRenderer(renderer *htmlrender.Renderer) Option {
+	return func(f *Funcs) {
+		f.renderer = renderer
+	}
+}
+
+// WithErrorHandler reports missing messages and formatting diagnostics to
+// onError. A missing message then renders as its ID, and a message with
+// diagnostics as its output with fallbacks. Without a handler, msg returns
+// these errors, which stops template execution.
+func WithErrorHandler(onError ErrorHandler) Option {
+	return func(f *Funcs) {
+		f.onError = onError
+	}
+}
+
+// New creates template functions that format messages from c.
+func New(c *catalog.Catalog, options ...Option) *Funcs {
+	f := &Funcs{catalog: c}
+	for _, option := range options {
+		option(f)
+	}
+	if f.renderer == nil {
+		f.renderer, _ = htmlrender.New()
+	}
+	return f
+}
+
+// HTML returns the html/template FuncMap that formats messages for locale.
+func (f *Funcs) HTML(locale string) htmltemplate.FuncMap {
+	return htmltemplate.FuncMap{
+		FuncName: func(id string, args ...any) (htmltemplate.HTML, error) {
+			values, err := messageValues(args)
+			if err != nil {
+				return "", err
+			}
+			parts, err := f.catalog.FormatToParts(locale, id, values)
+			if errors.Is(err, catalog.ErrMessageNotFound) {
+				return htmltemplate.HTML(htmltemplate.HTMLEscapeString(id)), f.report(locale, id, err) //nolint:gosec // escaped message ID
+			}
+			html, renderErr := f.renderer.Render(parts)
+			return html, f.report(locale, id, errors.Join(err, renderErr))
+		},
+	}
+}
+
+// Text returns the text/template FuncMap that formats messages for locale.
+func (f *Funcs) Text(locale string) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		FuncName: func(id string, args ...any) (string, error) {
+			values, err := messageValues(args)
+			if err != nil {
+				return "", err
+			}
+			out, err := f.catalog.Format(locale, id, values)
+			if errors.Is(err, catalog.ErrMessageNotFound) {
+				return id, f.report(locale, id, err)
+			}
+			return out, f.report(locale, id, err)
+		},
+	}
+}
+
+// report passes err to the error handler, or returns it without one.
+func (f *Funcs) report(locale, id string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if f.onError == nil {
+		return fmt.Errorf("message %s: %w", id, err)
+	}
+	f.onError(locale, id, err)
+	return nil
+}
+
+// messageValues returns the values of msg arguments: one map, or name and
+// value pairs.
+func messageValues(args []any) (map[string]any, error) {
+	if len(args) == 1 {
+		if values, ok := args[0].(map[string]any); ok {
+			return values, nil
+		}
+	}
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("%w: %d arguments", ErrInvalidArguments, len(args))
+	}
+	values := make(map[string]any, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		name, ok := args[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: name %v is not a string", ErrInvalidArguments, args[i])
+		}
+		values[name] = args[i+1]
+	}
+	return values, nil
+}
@@ -0,0 +1,118 @@
+package templatefunc
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	messageformat "github.com/kaptinlin/messageformat-go"
+	"github.com/kaptinlin/messageformat-go/pkg/catalog"
+	"github.com/kaptinlin/messageformat-go/pkg/htmlrender"
+)
+
+type reportedError struct {
+	locale, id string
+	err        error
+}
+
+func newTestCatalog(t *testing.T) *catalog.Catalog {
+	t.Helper()
+
+	c := catalog.New("en", messageformat.WithBidiIsolation(messageformat.BidiNone))
+	require.NoError(t, c.AddMessages("en", map[string]string{
+		"greeting": "Hello {$name}!",
+		"docs":     "Read {#link href=$url}the {#b}docs{/b}{/link}.",
+		"custom":   "{#term}word{/term}",
+	}))
+	require.NoError(t, c.AddMessages("de", map[string]string{
+		"greeting": "Hallo {$name}!",
+	}))
+	return c
+}
+
+func executeHTML(t *testing.T, funcs *Funcs, locale, src string, data any) (string, error) {
+	t.Helper()
+
+	page := htmltemplate.Must(htmltemplate.New("page").Funcs(funcs.HTML("en")).Parse(src))
+	tmpl := htmltemplate.Must(page.Clone()).Funcs(funcs.HTML(locale))
+	var b strings.Builder
+	err := tmpl.Execute(&b, data)
+	return b.String(), err
+}
+
+func TestHTML(t *testing.T) {
+	var reported []reportedError
+	funcs := New(newTestCatalog(t), WithErrorHandler(func(locale, id string, err error) {
+		reported = append(reported, reportedError{locale, id, err})
+	}))
+
+	tests := []struct {
+		name     string
+		locale   string
+		src      string
+		data     any
+		expected string
+	}{
+		{"pairs", "de-CH", `<p>{{ msg "greeting" "name" .Name }}</p>`, map[string]any{"Name": "<Anna>"}, "<p>Hallo &lt;Anna&gt;!</p>"},
+		{"map", "en", `{{ msg "greeting" .Values }}`, map[string]any{"Values": map[string]any{"name": "Bo"}}, "Hello Bo!"},
+		{"markup", "en", `{{ msg "docs" "url" .URL }}`, map[string]any{"URL": "/docs?a=1&b=2"}, `Read <a href="/docs?a=1&amp;b=2">the <b>docs</b></a>.`},
+		{"attribute context", "en", `<img alt="{{ msg "docs" "url" "/x" }}">`, nil, `<img alt="Read the docs.">`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeHTML(t, funcs, tt.locale, tt.src, tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+	assert.Empty(t, reported)
+
+	out, err := executeHTML(t, funcs, "fr", `{{ msg "missing<" }} {{ msg "greeting" }}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "missing&lt; Hello {$name}!", out)
+	require.Len(t, reported, 2)
+	assert.Equal(t, "fr", reported[0].locale)
+	assert.Equal(t, "missing<", reported[0].id)
+	require.ErrorIs(t, reported[0].err, catalog.ErrMessageNotFound)
+	assert.Equal(t, "greeting", reported[1].id)
+	assert.Error(t, reported[1].err)
+}
+
+func TestHTMLWithoutErrorHandler(t *testing.T) {
+	renderer, err := htmlrender.New(htmlrender.WithUnknownMarkup(htmlrender.RejectUnknown))
+	require.NoError(t, err)
+	funcs := New(newTestCatalog(t), WithRenderer(renderer))
+
+	_, err = executeHTML(t, funcs, "en", `{{ msg "missing" }}`, nil)
+	require.ErrorIs(t, err, catalog.ErrMessageNotFound)
+
+	_, err = executeHTML(t, funcs, "en", `{{ msg "custom" }}`, nil)
+	require.ErrorIs(t, err, htmlrender.ErrUnknownMarkup)
+
+	_, err = executeHTML(t, funcs, "en", `{{ msg "greeting" "name" }}`, nil)
+	require.ErrorIs(t, err, ErrInvalidArguments)
+
+	_, err = executeHTML(t, funcs, "en", `{{ msg "greeting" 1 2 }}`, nil)
+	require.ErrorIs(t, err, ErrInvalidArguments)
+}
+
+func TestText(t *testing.T) {
+	var reported []reportedError
+	funcs := New(newTestCatalog(t), WithErrorHandler(func(locale, id string, err error) {
+		reported = append(reported, reportedError{locale, id, err})
+	}))
+
+	tmpl := texttemplate.Must(texttemplate.New("mail").Funcs(funcs.Text("de")).Parse(
+		`{{ msg "greeting" "name" .Name }} {{ msg "docs" "url" "/docs" }} {{ msg "missing" }}`,
+	))
+	var b strings.Builder
+	require.NoError(t, tmpl.Execute(&b, map[string]any{"Name": "<Anna>"}))
+	assert.Equal(t, "Hallo <Anna>! Read the docs. missing", b.String())
+	require.Len(t, reported, 1)
+	require.ErrorIs(t, reported[0].err, catalog.ErrMessageNotFound)
+}
@@ -0,0 +1,142 @@
+package messageformat
+
+import (
+	"maps"
+
+	"github.com/kaptinlin/messageformat-go/internal/resolve"
+	"github.com/kaptinlin/messageformat-go/internal/selector"
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+)
+
+// executionPlan is the formatting program of a message, built once by Compile
+// so that formatting does not walk or copy the data model.
+// An executionPlan is immutable and safe for concurrent use.
+type executionPlan struct {
+	message      datamodel.Message
+	declarations []planDeclaration
+	pattern      planPattern     // pattern messages
+	table        *selector.Table // select messages
+	variants     []planPattern   // select messages, in variant order
+}
+
+// planDeclaration is a compiled .input or .local declaration.
+type planDeclaration struct {
+	name     string
+	input    bool
+	compiled *resolve.CompiledExpression
+	local    *resolve.UnresolvedExpression // shared by all calls; nil for inputs
+}
+
+// planPattern is a pattern with its expressions compiled.
+type planPattern []planElement
+
+// planElement is one pattern element: text, an expression, or markup.
+type planElement struct {
+	text   string
+	expr   *resolve.CompiledExpression
+	markup *datamodel.Markup
+}
+
+// newExecutionPlan compiles message for mf's locales and functions.
+func newExecutionPlan(mf *MessageFormat, message datamodel.Message) *executionPlan {
+	plan := &executionPlan{message: message}
+	compile := func(expr *datamodel.Expression) *resolve.CompiledExpression {
+		return resolve.CompileExpression(expr, mf.locales, mf.functions, mf.builtins, mf.localeMatcher)
+	}
+
+	for _, decl := range message.Declarations() {
+		switch d := decl.(type) {
+		case *datamodel.InputDeclaration:
+			if expression := d.Value(); expression != nil {
+				plan.declarations = append(plan.declarations, planDeclaration{
+					name:     d.Name(),
+					input:    true,
+					compiled: compile(expression),
+				})
+			}
+		case *datamodel.LocalDeclaration:
+			if expression := d.Value(); expression != nil {
+				unresolved := resolve.NewUnresolvedExpression(expression, nil)
+				unresolved.Compiled = compile(expression)
+				plan.declarations = append(plan.declarations, planDeclaration{
+					name:     d.Name(),
+					compiled: unresolved.Compiled,
+					local:    unresolved,
+				})
+			}
+		}
+	}
+
+	compilePattern := func(pattern datamodel.Pattern) planPattern {
+		elements := pattern.Elements()
+		compiled := make(planPattern, len(elements))
+		for i, element := range elements {
+			switch elem := element.(type) {
+			case *datamodel.TextElement:
+				compiled[i] = planElement{text: elem.Value()}
+			case *datamodel.Expression:
+				compiled[i] = planElement{expr: compile(elem)}
+			case *datamodel.Markup:
+				compiled[i] = planElement{markup: elem}
+			}
+		}
+		return compiled
+	}
+
+	switch msg := message.(type) {
+	case *datamodel.PatternMessage:
+		plan.pattern = compilePattern(msg.Pattern())
+	case *datamodel.SelectMessage:
+		plan.table = selector.NewTable(msg)
+		variants := msg.Variants()
+		plan.variants = make([]planPattern, len(variants))
+		for i, variant := range variants {
+			plan.variants[i] = compilePattern(variant.Value())
+		}
+	}
+
+	return plan
+}
+
+// selectPattern returns the compiled pattern selected in ctx, reporting
+// selection errors as selector.SelectPattern does.
+func (p *executionPlan) selectPattern(ctx *resolve.Context) planPattern {
+	switch {
+	case p.table != nil:
+		index, ok := p.table.Select(ctx)
+		if !ok {
+			return nil
+		}
+		return p.variants[index]
+	case p.pattern != nil:
+		return p.pattern
+	default:
+		// Unsupported message types report a bad-selector error
+		selector.SelectPattern(ctx, p.message)
+		return nil
+	}
+}
+
+// scope builds the variable scope of a formatting call: the values with the
+// declarations layered on top.
+func (p *executionPlan) scope(values map[string]any) map[string]any {
+	if len(p.declarations) == 0 && values != nil {
+		// Nothing is written to a scope without declarations
+		return values
+	}
+
+	scope := make(map[string]any, len(values)+len(p.declarations))
+	maps.Copy(scope, values)
+	for _, decl := range p.declarations {
+		if decl.input {
+			scope[decl.name] = &resolve.UnresolvedExpression{
+				Expression: decl.compiled.Expression(),
+				Scope:      values,
+				Compiled:   decl.compiled,
+			}
+		} else {
+			scope[decl.name] = decl.local
+		}
+	}
+	return scope
+}
@@ -0,0 +1,168 @@
+package messageformat
+
+import (
+	"context"
+	"slices"
+
+	"github.com/kaptinlin/messageformat-go/internal/resolve"
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// PluralCoverage describes how the variant keys of one plural selector cover
+// the plural categories of a locale.
+type PluralCoverage struct {
+	Selector   string   // Selector variable name, without "$"
+	Locale     string   // Locale the plural rules were resolved for
+	Type       string   // "cardinal" or "ordinal"
+	Categories []string // Categories of the locale's plural rules, in CLDR order
+	Missing    []string // Categories, other than "other", without a variant key
+	Impossible []string // Category keys that are not categories of the locale
+	Start      int      // Start byte offset of the selector in the source, or -1
+	End        int      // End byte offset of the selector in the source, or -1
+}
+
+// Complete reports whether every category has a variant and every category
+// key can be selected.
+func (c PluralCoverage) Complete() bool {
+	return len(c.Missing) == 0 && len(c.Impossible) == 0
+}
+
+// pluralCategories are the CLDR plural categories, in CLDR order.
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// pluralFunctions are the names of the built-in functions that select by
+// plural category.
+var pluralFunctions = map[string]bool{"integer": true, "number": true}
+
+// CheckPluralCategories reports, for each .match selector annotated with the
+// built-in :number or :integer that selects by plural category, which
+// categories of locale have no variant and which category keys locale never
+// selects.
+//
+// The categories are those of the plural rules that NumberValue.SelectKeys
+// selects with when the message is compiled for locale, with the selector's
+// options, as listed by NumberValue.PluralCategories. A category other than
+// "other" is missing when no variant has its key at the selector's position.
+// Exact keys such as 1 do not count toward a category. Selectors with
+// select=exact, selectors whose value cannot be resolved, and pattern messages
+// have no coverage.
+func (mf *MessageFormat) CheckPluralCategories(locale string) []PluralCoverage {
+	msg, ok := mf.message.(*datamodel.SelectMessage)
+	if !ok {
+		return nil
+	}
+
+	target := *mf
+	target.locales = []string{locale}
+	target.locale = functions.NegotiateLocale(target.locales, target.localeMatcher)
+	target.plan = newExecutionPlan(&target, msg)
+
+	var coverage []PluralCoverage
+	for i, ref := range msg.Selectors() {
+		input, annotation := selectorInput(msg, ref.Name())
+		if annotation == nil || !mf.isPluralFunction(annotation.Name()) {
+			continue
+		}
+		if c, ok := target.pluralCoverage(&ref, input, variantKeys(msg, i)); ok {
+			coverage = append(coverage, c)
+		}
+	}
+	return coverage
+}
+
+// pluralCoverage resolves the selector ref, whose value is computed from the
+// variable input, and checks the categories of its plural rules against the
+// literal keys at its position.
+func (mf *MessageFormat) pluralCoverage(ref *datamodel.VariableRef, input string, keys []string) (PluralCoverage, bool) {
+	coverage := PluralCoverage{Selector: ref.Name(), Type: "cardinal"}
+	coverage.Start, coverage.End = ref.GetPosition()
+
+	ctx := mf.createContext(context.Background(), map[string]any{input: 0}, nil)
+	mv, ok := resolve.ResolveVariableRef(ctx, ref).(*messagevalue.NumberValue)
+	if !ok {
+		return coverage, false
+	}
+	coverage.Categories = mv.PluralCategories()
+	if coverage.Categories == nil {
+		return coverage, false
+	}
+	coverage.Locale = mv.Locale()
+	if mv.Options()["select"] == "ordinal" {
+		coverage.Type = "ordinal"
+	}
+
+	for _, category := range pluralCategories {
+		available := slices.Contains(coverage.Categories, category)
+		if available && category != "other" && !slices.Contains(keys, category) {
+			coverage.Missing = append(coverage.Missing, category)
+		}
+		if !available && slices.Contains(keys, category) {
+			coverage.Impossible = append(coverage.Impossible, category)
+		}
+	}
+	return coverage, true
+}
+
+// isPluralFunction reports whether name resolves to a built-in function that
+// selects by plural category. Custom selectors are never probed.
+func (mf *MessageFormat) isPluralFunction(name string) bool {
+	return pluralFunctions[name] && mf.builtins[name] && mf.functions[name] != nil
+}
+
+// selectorInput follows the declarations of the selector name back to the
+// variable its value is computed from, and returns that variable with the
+// nearest function annotation on the way. The annotation is nil when there is
+// none or when the value is computed from a literal.
+func selectorInput(msg datamodel.Message, name string) (string, *datamodel.FunctionRef) {
+	var annotation *datamodel.FunctionRef
+	declarations := msg.Declarations()
+	for {
+		i := slices.IndexFunc(declarations, func(decl datamodel.Declaration) bool {
+			return decl.Name() == name
+		})
+		if i < 0 {
+			return name, annotation
+		}
+		var value *datamodel.Expression
+		switch decl := declarations[i].(type) {
+		case *datamodel.InputDeclaration:
+			value = decl.Value()
+		case *datamodel.LocalDeclaration:
+			value = decl.Value()
+		}
+		if value == nil {
+			return name, annotation
+		}
+		if annotation == nil {
+			annotation = value.FunctionRef()
+		}
+		if _, ok := declarations[i].(*datamodel.InputDeclaration); ok {
+			return name, annotation
+		}
+		ref, ok := value.Arg().(*datamodel.VariableRef)
+		if !ok {
+			return name, nil
+		}
+		name = ref.Name()
+		// Declarations may only refer to earlier ones, so this terminates
+		declarations = declarations[:i]
+	}
+}
+
+// variantKeys returns the distinct literal keys at position i of the
+// variants of msg.
+func variantKeys(msg *datamodel.SelectMessage, i int) []string {
+	var keys []string
+	for _, variant := range msg.Variants() {
+		variantKeys := variant.Keys()
+		if i >= len(variantKeys) {
+			continue
+		}
+		if literal, ok := variantKeys[i].(*datamodel.Literal); ok && !slices.Contains(keys, literal.Value()) {
+			keys = append(keys, literal.Value())
+		}
+	}
+	return keys
+}
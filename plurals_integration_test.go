@@ -0,0 +1,62 @@
+//go:build integration
+
+package messageformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckPluralCategoriesCLDR checks coverage against the CLDR plural data
+// of go-intl, so it runs only with the integration build tag.
+func TestCheckPluralCategoriesCLDR(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		source string
+		want   []PluralCoverage
+	}{
+		{
+			name:   "missing polish categories",
+			locale: "pl",
+			source: ".input {$n :integer} .match $n one {{plik}} * {{pliki}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "pl", Type: "cardinal", Categories: []string{"one", "few", "many", "other"}, Missing: []string{"few", "many"}, Start: 28, End: 30},
+			},
+		},
+		{
+			name:   "missing french many",
+			locale: "fr",
+			source: ".input {$n :number} .match $n one {{fichier}} * {{fichiers}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "fr", Type: "cardinal", Categories: []string{"one", "many", "other"}, Missing: []string{"many"}, Start: 27, End: 29},
+			},
+		},
+		{
+			name:   "complete spanish with many",
+			locale: "es",
+			source: ".input {$n :number} .match $n one {{archivo}} many {{de archivos}} * {{archivos}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "es", Type: "cardinal", Categories: []string{"one", "many", "other"}, Start: 27, End: 29},
+			},
+		},
+		{
+			name:   "ordinal selector through a local",
+			locale: "en",
+			source: ".input {$n :number} .input {$s :string} .local $place = {$n :integer select=ordinal} .match $s $place a one {{st}} * two {{nd}} * few {{rd}} * * {{th}}",
+			want: []PluralCoverage{
+				{Selector: "place", Locale: "en", Type: "ordinal", Categories: []string{"one", "two", "few", "other"}, Start: 95, End: 101},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, err := Parse([]string{"en"}, tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mf.CheckPluralCategories(tt.locale))
+		})
+	}
+}
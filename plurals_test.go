@@ -0,0 +1,111 @@
+package messageformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// TestCheckPluralCategories uses only English cardinal rules, whose
+// categories are one for exactly 1 and other for everything else. Other
+// locales and ordinal rules are covered by the integration tests.
+func TestCheckPluralCategories(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		source string
+		want   []PluralCoverage
+	}{
+		{
+			name:   "complete english cardinal",
+			locale: "en",
+			source: ".input {$n :number} .match $n one {{item}} * {{items}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "en", Type: "cardinal", Categories: []string{"one", "other"}, Start: 27, End: 29},
+			},
+		},
+		{
+			name:   "impossible category",
+			locale: "en",
+			source: ".input {$n :number} .match $n one {{a}} two {{b}} * {{c}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "en", Type: "cardinal", Categories: []string{"one", "other"}, Impossible: []string{"two"}, Start: 27, End: 29},
+			},
+		},
+		{
+			name:   "missing category",
+			locale: "en",
+			source: ".input {$n :integer} .match $n 0 {{none}} * {{some}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "en", Type: "cardinal", Categories: []string{"one", "other"}, Missing: []string{"one"}, Start: 28, End: 30},
+			},
+		},
+		{
+			name:   "exact keys do not cover a category",
+			locale: "en",
+			source: ".input {$n :number} .match $n 1 {{one}} * {{other}}",
+			want: []PluralCoverage{
+				{Selector: "n", Locale: "en", Type: "cardinal", Categories: []string{"one", "other"}, Missing: []string{"one"}, Start: 27, End: 29},
+			},
+		},
+		{
+			name:   "exact selection",
+			locale: "en",
+			source: ".input {$n :number select=exact} .match $n 1 {{one}} * {{other}}",
+		},
+		{
+			name:   "literal selector",
+			locale: "en",
+			source: ".local $n = {1 :number} .match $n one {{one}} * {{other}}",
+		},
+		{
+			name:   "pattern message",
+			locale: "en",
+			source: "{$n :number}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, err := Parse([]string{"en"}, tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mf.CheckPluralCategories(tt.locale))
+		})
+	}
+}
+
+func TestPluralCoverageComplete(t *testing.T) {
+	assert.True(t, PluralCoverage{Categories: []string{"one", "other"}}.Complete())
+	assert.False(t, PluralCoverage{Missing: []string{"few"}}.Complete())
+	assert.False(t, PluralCoverage{Impossible: []string{"two"}}.Complete())
+}
+
+func TestCheckPluralCategoriesKeepsFormatting(t *testing.T) {
+	mf, err := Parse([]string{"en"}, ".input {$n :number} .match $n one {{one}} * {{other}}")
+	require.NoError(t, err)
+
+	mf.CheckPluralCategories("pl")
+	got, err := mf.Format(map[string]any{"n": 1})
+	require.NoError(t, err)
+	assert.Equal(t, "one", got)
+	assert.Equal(t, []string{"en"}, mf.locales)
+}
+
+func TestCheckPluralCategoriesSkipsCustomSelectors(t *testing.T) {
+	custom := func(ctx functions.MessageFunctionContext, options functions.Options, input any) messagevalue.MessageValue {
+		return functions.NumberFunction(ctx, options, input)
+	}
+	mf, err := Parse([]string{"en"}, ".input {$n :number} .match $n one {{one}} * {{other}}",
+		WithFunction("number", custom))
+	require.NoError(t, err)
+	assert.Empty(t, mf.CheckPluralCategories("en"))
+
+	mf, err = Parse([]string{"en"}, ".input {$n :number} .match $n one {{one}} * {{other}}",
+		WithFunction("number", functions.IntegerFunction))
+	require.NoError(t, err)
+	assert.Empty(t, mf.CheckPluralCategories("en"), "a built-in implementation under another name is custom")
+}
@@ -0,0 +1,98 @@
+package messageformat
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kaptinlin/messageformat-go/internal/resolve"
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	pkgerrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+)
+
+// compileSchemas returns the schemas that Compile checks literal values
+// against: those registered with custom functions and, with StrictOptions,
+// those of the built-in functions that opts does not replace.
+func compileSchemas(opts *MessageFormatOptions) map[string]functions.Schema {
+	schemas := make(map[string]functions.Schema)
+	if opts.StrictOptions {
+		maps.Copy(schemas, functions.DefaultSchemaMap())
+		maps.Copy(schemas, functions.DraftSchemaMap())
+		for name := range opts.Functions {
+			delete(schemas, name)
+		}
+	}
+	maps.Copy(schemas, opts.Schemas)
+	return schemas
+}
+
+// checkLiterals checks the literal operands and options of the function
+// calls in msg that have a schema. Errors are bad-operand and bad-option
+// resolution errors located at their expression.
+func checkLiterals(msg datamodel.Message, schemas map[string]functions.Schema) error {
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	var errs []error
+	datamodel.Visit(msg, &datamodel.Visitor{
+		Expression: func(expr *datamodel.Expression, _ datamodel.VisitContext) func() {
+			functionRef := expr.FunctionRef()
+			if functionRef == nil {
+				return nil
+			}
+			schema, ok := schemas[functionRef.Name()]
+			if !ok {
+				return nil
+			}
+			report := func(kind pkgerrors.ErrorKind, cause error) {
+				err := pkgerrors.NewMessageResolutionError(
+					kind,
+					fmt.Sprintf("%v of :%s", cause, functionRef.Name()),
+					resolve.ExpressionSource(expr),
+					cause,
+				)
+				err.SetSpan(expr.GetPosition())
+				errs = append(errs, err)
+			}
+
+			switch arg := expr.Arg().(type) {
+			case nil:
+				if schema.Operand.Required {
+					report(pkgerrors.ErrorTypeBadOperand, functions.ErrMissingOperand)
+				}
+			case *datamodel.Literal:
+				if err := schema.Operand.CheckLiteral(arg.Value()); err != nil {
+					report(pkgerrors.ErrorTypeBadOperand, err)
+				}
+			}
+
+			options := functionRef.Options()
+			for _, name := range slices.Sorted(maps.Keys(options)) {
+				if strings.HasPrefix(name, "u:") {
+					continue
+				}
+				option, ok := schema.Option(name)
+				if !ok {
+					report(pkgerrors.ErrorTypeBadOption, fmt.Errorf("%w %s", functions.ErrUnknownOption, name))
+					continue
+				}
+				switch value := options[name].(type) {
+				case *datamodel.Literal:
+					if err := option.CheckLiteral(value.Value()); err != nil {
+						report(pkgerrors.ErrorTypeBadOption, err)
+					}
+				case *datamodel.VariableRef:
+					if option.LiteralOnly {
+						report(pkgerrors.ErrorTypeBadOption, fmt.Errorf("%w %s", functions.ErrNotLiteral, name))
+					}
+				}
+			}
+			return nil
+		},
+	})
+	return errors.Join(errs...)
+}
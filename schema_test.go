@@ -0,0 +1,122 @@
+package messageformat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgerrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+func TestFunctionSchemaChecksLiterals(t *testing.T) {
+	t.Parallel()
+
+	badge := func(ctx functions.MessageFunctionContext, options functions.Options, operand any) messagevalue.MessageValue {
+		return messagevalue.NewStringValue("badge", ctx.Locales()[0], ctx.Source())
+	}
+	schema := functions.Schema{
+		Operand: functions.OperandSchema{Required: true},
+		Options: []functions.OptionSchema{
+			{Name: "size", Type: functions.ValueString, Values: []string{"small", "large"}},
+			{Name: "count", Type: functions.ValueInteger, Max: 99},
+			{Name: "kind", Type: functions.ValueString, LiteralOnly: true},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		kinds  []pkgerrors.ErrorKind
+		texts  []string
+		causes []error
+	}{
+		{
+			name:   "valid literals and variables",
+			source: "{$x :badge size=small count=$n kind=info u:id=b}",
+		},
+		{
+			name:   "misspelled option",
+			source: "{$x :badge siz=small}",
+			kinds:  []pkgerrors.ErrorKind{pkgerrors.ErrorTypeBadOption},
+			texts:  []string{"{$x :badge siz=small}"},
+			causes: []error{functions.ErrUnknownOption},
+		},
+		{
+			name:   "invalid literal values",
+			source: "Hi {$x :badge size=huge count=100}",
+			kinds:  []pkgerrors.ErrorKind{pkgerrors.ErrorTypeBadOption, pkgerrors.ErrorTypeBadOption},
+			texts:  []string{"{$x :badge size=huge count=100}", "{$x :badge size=huge count=100}"},
+			causes: []error{functions.ErrInvalidLiteral, functions.ErrInvalidLiteral},
+		},
+		{
+			name:   "variable for a literal-only option",
+			source: "{$x :badge kind=$k}",
+			kinds:  []pkgerrors.ErrorKind{pkgerrors.ErrorTypeBadOption},
+			texts:  []string{"{$x :badge kind=$k}"},
+			causes: []error{functions.ErrNotLiteral},
+		},
+		{
+			name:   "missing operand in a declaration",
+			source: ".local $b = {:badge}\n{{{$b}}}",
+			kinds:  []pkgerrors.ErrorKind{pkgerrors.ErrorTypeBadOperand},
+			texts:  []string{"{:badge}"},
+			causes: []error{functions.ErrMissingOperand},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse([]string{"en"}, tt.source, WithFunctionSchema("badge", badge, schema))
+			if len(tt.kinds) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+
+			diagnostics := pkgerrors.Diagnose(tt.source, err)
+			require.Len(t, diagnostics, len(tt.kinds))
+			for i, d := range diagnostics {
+				assert.Equal(t, tt.kinds[i], d.Kind)
+				assert.Equal(t, tt.texts[i], tt.source[d.Start:d.End])
+			}
+			for _, cause := range tt.causes {
+				assert.True(t, errors.Is(err, cause), "want %v", cause)
+			}
+		})
+	}
+}
+
+func TestStrictOptionsChecksBuiltinFunctions(t *testing.T) {
+	t.Parallel()
+
+	source := "{1 :number minimumFractionDigit=2}"
+	_, err := Parse([]string{"en"}, source)
+	require.NoError(t, err, "built-in options are checked only when strict")
+
+	_, err = Parse([]string{"en"}, source, WithStrictOptions())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, functions.ErrUnknownOption)
+	assert.Equal(t, "1:1: bad-option: unknown option minimumFractionDigit of :number", pkgerrors.Diagnose(source, err)[0].String())
+
+	_, err = Parse([]string{"en"}, "{$n :number select=$s}", WithStrictOptions())
+	assert.ErrorIs(t, err, functions.ErrNotLiteral)
+
+	_, err = Parse([]string{"en"}, "{|x| :integer}", WithStrictOptions())
+	assert.ErrorIs(t, err, functions.ErrInvalidLiteral)
+
+	_, err = Parse([]string{"en"}, "{$d :datetime dateLength=short timePrecision=minute hour12=true u:dir=rtl}", WithStrictOptions())
+	require.NoError(t, err)
+
+	// A replaced built-in has no schema unless one is registered with it
+	number := func(ctx functions.MessageFunctionContext, options functions.Options, operand any) messagevalue.MessageValue {
+		return messagevalue.NewStringValue("n", ctx.Locales()[0], ctx.Source())
+	}
+	_, err = Parse([]string{"en"}, source, WithStrictOptions(), WithFunction("number", number))
+	require.NoError(t, err)
+}
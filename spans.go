@@ -0,0 +1,113 @@
+package messageformat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// Span locates the output of an expression, or the output enclosed by a
+// markup element, in the text of FormatWithSpans.
+type Span struct {
+	Type      string // "expression" or "markup"
+	Source    string // Fallback source of an expression, such as "$count"; empty for markup
+	Name      string // Markup name; empty for expressions
+	ID        string // Value of the u:id option, if any
+	Start     int    // Start byte offset in the text
+	End       int    // End byte offset in the text
+	RuneStart int    // Start rune offset in the text
+	RuneEnd   int    // End rune offset in the text
+}
+
+// FormatWithSpans is like Format but also returns a span for each expression
+// and markup element of the selected pattern, in order of their start.
+//
+// An expression span covers the formatted value, or its fallback, without
+// the bidi isolation marks around it. A markup span covers the text between
+// an open and its close. Standalone markup has an empty span at its
+// position, close markup without a matching open has no span, and open
+// markup that is never closed, or closed only by the close of an outer
+// element, ends where the outer element or the text ends.
+func (mf *MessageFormat) FormatWithSpans(values map[string]any) (string, []Span, error) {
+	var result strings.Builder
+	spans := &spanRecorder{}
+	_, diagnostics, err := mf.formatTo(context.Background(), &result, values, spans)
+	if err != nil {
+		return "", nil, errors.Join(append(diagnostics, err)...)
+	}
+	text := result.String()
+	return text, spans.finish(text), errors.Join(diagnostics...)
+}
+
+// spanRecorder collects the spans of FormatWithSpans as the pattern is
+// written. A nil recorder records nothing.
+type spanRecorder struct {
+	spans []Span
+	open  []int // indexes of the spans of open markup
+}
+
+// expression records the span of an expression resolved to mv, which is nil
+// when resolution produced no value.
+func (r *spanRecorder) expression(mv messagevalue.MessageValue, start, end int) {
+	if r == nil {
+		return
+	}
+	span := Span{Type: "expression", Start: start, End: end}
+	if mv != nil {
+		span.Source = mv.Source()
+		if id, ok := mv.(interface{ ID() string }); ok {
+			span.ID = id.ID()
+		}
+	}
+	r.spans = append(r.spans, span)
+}
+
+// markup records markup part at byte offset.
+func (r *spanRecorder) markup(part messagevalue.MessagePart, offset int) {
+	if r == nil {
+		return
+	}
+	markup, ok := part.(*messagevalue.MarkupPart)
+	if !ok {
+		return
+	}
+	span := Span{Type: "markup", Name: markup.Name(), ID: markup.ID(), Start: offset, End: offset}
+	switch markup.Kind() {
+	case "open":
+		r.open = append(r.open, len(r.spans))
+		r.spans = append(r.spans, span)
+	case "standalone":
+		r.spans = append(r.spans, span)
+	case "close":
+		open := len(r.open) - 1
+		for open >= 0 && r.spans[r.open[open]].Name != markup.Name() {
+			open--
+		}
+		if open < 0 {
+			return
+		}
+		r.closeFrom(open, offset)
+	}
+}
+
+// closeFrom ends the spans of the open markup from index open on at offset.
+func (r *spanRecorder) closeFrom(open, offset int) {
+	for _, i := range r.open[open:] {
+		r.spans[i].End = offset
+	}
+	r.open = r.open[:open]
+}
+
+// finish ends the spans of markup left open at the end of text and fills in
+// the rune offsets.
+func (r *spanRecorder) finish(text string) []Span {
+	r.closeFrom(0, len(text))
+	for i := range r.spans {
+		r.spans[i].RuneStart = utf8.RuneCountInString(text[:r.spans[i].Start])
+		r.spans[i].RuneEnd = r.spans[i].RuneStart + utf8.RuneCountInString(text[r.spans[i].Start:r.spans[i].End])
+	}
+	return r.spans
+}
@@ -0,0 +1,111 @@
+package messageformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgerrors "github.com/kaptinlin/messageformat-go/pkg/errors"
+)
+
+func TestFormatWithSpans(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		source   string
+		bidi     BidiIsolation
+		expected string
+		spans    []Span
+	}{
+		{
+			name:     "expressions",
+			source:   "Hi {$name}, {|x| :string u:id=mark}!",
+			bidi:     BidiNone,
+			expected: "Hi Ada, x!",
+			spans: []Span{
+				{Type: "expression", Source: "$name", Start: 3, End: 6, RuneStart: 3, RuneEnd: 6},
+				{Type: "expression", Source: "|x|", ID: "mark", Start: 8, End: 9, RuneStart: 8, RuneEnd: 9},
+			},
+		},
+		{
+			name:     "bidi isolation marks",
+			source:   "Hi {$name}, {$name}",
+			bidi:     BidiDefault,
+			expected: "Hi \u2068Ada\u2069, \u2068Ada\u2069",
+			spans: []Span{
+				{Type: "expression", Source: "$name", Start: 6, End: 9, RuneStart: 4, RuneEnd: 7},
+				{Type: "expression", Source: "$name", Start: 17, End: 20, RuneStart: 11, RuneEnd: 14},
+			},
+		},
+		{
+			name:     "multibyte text",
+			source:   "café {$name}",
+			bidi:     BidiNone,
+			expected: "café Ada",
+			spans: []Span{
+				{Type: "expression", Source: "$name", Start: 6, End: 9, RuneStart: 5, RuneEnd: 8},
+			},
+		},
+		{
+			name:     "markup",
+			source:   "{#b u:id=title}Hi {$name}{/b}{#br/}{#i}x",
+			bidi:     BidiNone,
+			expected: "Hi Adax",
+			spans: []Span{
+				{Type: "markup", Name: "b", ID: "title", Start: 0, End: 6, RuneStart: 0, RuneEnd: 6},
+				{Type: "expression", Source: "$name", Start: 3, End: 6, RuneStart: 3, RuneEnd: 6},
+				{Type: "markup", Name: "br", Start: 6, End: 6, RuneStart: 6, RuneEnd: 6},
+				{Type: "markup", Name: "i", Start: 6, End: 7, RuneStart: 6, RuneEnd: 7},
+			},
+		},
+		{
+			name:     "mismatched markup",
+			source:   "{/b}{#i}{#b}x{/i}y",
+			bidi:     BidiNone,
+			expected: "xy",
+			spans: []Span{
+				{Type: "markup", Name: "i", Start: 0, End: 1, RuneStart: 0, RuneEnd: 1},
+				{Type: "markup", Name: "b", Start: 0, End: 1, RuneStart: 0, RuneEnd: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mf, err := Parse([]string{"en"}, tt.source, WithBidiIsolation(tt.bidi))
+			require.NoError(t, err)
+
+			values := map[string]any{"name": "Ada"}
+			text, spans, err := mf.FormatWithSpans(values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+			assert.Equal(t, tt.spans, spans)
+
+			formatted, err := mf.Format(values)
+			require.NoError(t, err)
+			assert.Equal(t, formatted, text)
+			for _, span := range spans {
+				assert.Equal(t, text[span.Start:span.End], string([]rune(text)[span.RuneStart:span.RuneEnd]))
+			}
+		})
+	}
+}
+
+func TestFormatWithSpansFallback(t *testing.T) {
+	t.Parallel()
+
+	mf, err := Parse([]string{"en"}, "a {$missing} b")
+	require.NoError(t, err)
+
+	text, spans, err := mf.FormatWithSpans(nil)
+	var resolutionErr *pkgerrors.MessageResolutionError
+	require.ErrorAs(t, err, &resolutionErr)
+	assert.Equal(t, "a \u2068{$missing}\u2069 b", text)
+	require.Len(t, spans, 1)
+	assert.Equal(t, "{$missing}", text[spans[0].Start:spans[0].End])
+	assert.Equal(t, "$missing", spans[0].Source)
+}
@@ -1,9 +1,11 @@
 package tests
 
 import (
+	"bytes"
 	"testing"
 
 	messageformat "github.com/kaptinlin/messageformat-go"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
 	"github.com/stretchr/testify/require"
 )
 
@@ -37,6 +39,26 @@ func BenchmarkNumberFormatting(b *testing.B) {
 	}
 }
 
+func BenchmarkNumberFormattingUncached(b *testing.B) {
+	messageformat.SetFormatterCacheSize(0)
+	b.Cleanup(func() {
+		messageformat.SetFormatterCacheSize(messagevalue.DefaultFormatterCacheSize)
+	})
+
+	mf, err := messageformat.Parse([]string{"en"}, "You have {$count :number} messages")
+	require.NoError(b, err)
+
+	data := map[string]any{
+		"count": 42,
+	}
+
+	b.ResetTimer()
+	for b.Loop() {
+		_, err := mf.Format(data)
+		require.NoError(b, err)
+	}
+}
+
 func BenchmarkSelectMessage(b *testing.B) {
 	mf, err := messageformat.Parse([]string{"en"}, `
 .input {$count :number}
@@ -89,6 +111,42 @@ one *      {{{$userName} added one photo to their album.}}
 	}
 }
 
+func BenchmarkFormatTo(b *testing.B) {
+	mf, err := messageformat.Parse([]string{"en"}, "Hello, {$name}! You have {$count :number} messages.")
+	require.NoError(b, err)
+
+	data := map[string]any{
+		"name":  "World",
+		"count": 42,
+	}
+
+	var buf bytes.Buffer
+	b.ResetTimer()
+	for b.Loop() {
+		buf.Reset()
+		_, err := mf.FormatTo(&buf, data)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkAppendFormat(b *testing.B) {
+	mf, err := messageformat.Parse([]string{"en"}, "Hello, {$name}! You have {$count :number} messages.")
+	require.NoError(b, err)
+
+	data := map[string]any{
+		"name":  "World",
+		"count": 42,
+	}
+
+	buf := make([]byte, 0, 128)
+	b.ResetTimer()
+	for b.Loop() {
+		var err error
+		buf, err = mf.AppendFormat(buf[:0], data)
+		require.NoError(b, err)
+	}
+}
+
 func BenchmarkFormatToParts(b *testing.B) {
 	mf, err := messageformat.Parse([]string{"en"}, "Hello, {$name}! You have {$count :number} messages.")
 	require.NoError(b, err)
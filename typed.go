@@ -0,0 +1,194 @@
+package messageformat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/kaptinlin/messageformat-go/internal/resolve"
+	"github.com/kaptinlin/messageformat-go/pkg/datamodel"
+	"github.com/kaptinlin/messageformat-go/pkg/functions"
+	"github.com/kaptinlin/messageformat-go/pkg/messagevalue"
+)
+
+// Static errors to avoid dynamic error creation
+var (
+	// ErrInvalidParamsType indicates a params type that cannot hold message variables.
+	ErrInvalidParamsType = errors.New("invalid params type")
+
+	// ErrMissingParam indicates a message variable without a matching params member.
+	ErrMissingParam = errors.New("message variable has no params member")
+
+	// ErrIncompatibleParam indicates a params member whose Go type the variable's function rejects.
+	ErrIncompatibleParam = errors.New("params member type incompatible with function")
+)
+
+// TypedMessageFormat is a MessageFormat whose external variables were checked
+// against the params type P when it was compiled.
+// It is safe for concurrent use.
+type TypedMessageFormat[P any] struct {
+	mf        *MessageFormat
+	variables []string
+}
+
+// CompileTyped parses source and verifies that every external variable of the
+// message resolves to a member of P, using the lookup rules of Format: struct
+// fields by `mf` tag or name, Get-prefixed getter methods, map keys, and
+// dotted paths. Variables annotated with :number, :integer, :percent,
+// :currency, :datetime, :date, or :time must also have a Go type the
+// annotation accepts, or one that a value adapter or functions.MessageValuer
+// converts.
+// Interface-typed members are checked at format time only.
+//
+// Parse errors are returned unchanged; params errors are joined and match
+// ErrMissingParam or ErrIncompatibleParam.
+func CompileTyped[P any](locales []string, source string, options ...Option) (*TypedMessageFormat[P], error) {
+	mf, err := Parse(locales, source, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := checkParams(mf.message, reflect.TypeFor[P](), mf.valueAdapters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedMessageFormat[P]{mf: mf, variables: variables}, nil
+}
+
+// Format formats the message with the variables read from params.
+// Runtime diagnostics follow the semantics of (*MessageFormat).Format.
+func (t *TypedMessageFormat[P]) Format(params P) (string, error) {
+	return t.mf.Format(t.values(params))
+}
+
+// FormatToParts formats the message to parts with the variables read from params.
+// Runtime diagnostics follow the semantics of (*MessageFormat).FormatToParts.
+func (t *TypedMessageFormat[P]) FormatToParts(params P) ([]messagevalue.MessagePart, error) {
+	return t.mf.FormatToParts(t.values(params))
+}
+
+// MessageFormat returns the underlying dynamic formatter.
+func (t *TypedMessageFormat[P]) MessageFormat() *MessageFormat {
+	return t.mf
+}
+
+// values reads each external variable from params into a formatting scope.
+// Variables are stored under their full name, so dotted paths resolve with a
+// direct lookup; members of nil pointers leave the variable unset.
+func (t *TypedMessageFormat[P]) values(params P) map[string]any {
+	values := make(map[string]any, len(t.variables))
+	for _, name := range t.variables {
+		if value, ok := resolve.GetValue(params, name); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// checkParams validates the external variables of msg against paramsType and
+// returns their names. Members of a type in adapters are accepted by every
+// function.
+func checkParams(msg datamodel.Message, paramsType reflect.Type, adapters map[reflect.Type]functions.ValueAdapter) ([]string, error) {
+	root := paramsType
+	for root != nil && root.Kind() == reflect.Pointer {
+		root = root.Elem()
+	}
+	if root == nil || (root.Kind() != reflect.Struct && root.Kind() != reflect.Map && root.Kind() != reflect.Interface) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParamsType, paramsType)
+	}
+
+	result, err := datamodel.ValidateMessage(msg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	external := make(map[string]bool, len(result.Variables))
+	for _, name := range result.Variables {
+		external[name] = true
+	}
+	annotations := make(map[string][]string)
+	datamodel.Visit(msg, &datamodel.Visitor{
+		FunctionRef: func(functionRef *datamodel.FunctionRef, _ datamodel.VisitContext, argument datamodel.ExpressionArg) func() {
+			if ref, ok := argument.(*datamodel.VariableRef); ok && external[ref.Name()] {
+				annotations[ref.Name()] = append(annotations[ref.Name()], functionRef.Name())
+			}
+			return nil
+		},
+	})
+
+	var errs []error
+	for _, name := range result.Variables {
+		memberType, ok := resolve.LookupType(paramsType, name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: $%s in %v", ErrMissingParam, name, paramsType))
+			continue
+		}
+		for _, function := range annotations[name] {
+			if adapters[memberType] == nil && !acceptsOperandType(function, memberType) {
+				errs = append(errs, fmt.Errorf("%w: $%s has type %v, :%s", ErrIncompatibleParam, name, memberType, function))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result.Variables, nil
+}
+
+var (
+	messageValueType  = reflect.TypeFor[messagevalue.MessageValue]()
+	messageValuerType = reflect.TypeFor[functions.MessageValuer]()
+
+	// numericOperandTypes mirrors the operands accepted by the numeric functions.
+	numericOperandTypes = map[reflect.Type]bool{
+		reflect.TypeFor[int]():          true,
+		reflect.TypeFor[int8]():         true,
+		reflect.TypeFor[int16]():        true,
+		reflect.TypeFor[int32]():        true,
+		reflect.TypeFor[int64]():        true,
+		reflect.TypeFor[uint]():         true,
+		reflect.TypeFor[uint8]():        true,
+		reflect.TypeFor[uint16]():       true,
+		reflect.TypeFor[uint32]():       true,
+		reflect.TypeFor[uint64]():       true,
+		reflect.TypeFor[float32]():      true,
+		reflect.TypeFor[float64]():      true,
+		reflect.TypeFor[*big.Int]():     true,
+		reflect.TypeFor[*big.Float]():   true,
+		reflect.TypeFor[*big.Rat]():     true,
+		reflect.TypeFor[*apd.Decimal](): true,
+		reflect.TypeFor[json.Number]():  true,
+		reflect.TypeFor[string]():       true,
+	}
+
+	// dateTimeOperandTypes mirrors the operands accepted by the date/time functions.
+	dateTimeOperandTypes = map[reflect.Type]bool{
+		reflect.TypeFor[time.Time](): true,
+		reflect.TypeFor[int]():       true,
+		reflect.TypeFor[int64]():     true,
+		reflect.TypeFor[float64]():   true,
+		reflect.TypeFor[string]():    true,
+	}
+)
+
+// acceptsOperandType reports whether values of type t are valid operands for
+// the named built-in function. Other functions accept any type.
+func acceptsOperandType(function string, t reflect.Type) bool {
+	if t.Kind() == reflect.Interface || t.Implements(messageValueType) || t.Implements(messageValuerType) {
+		return true
+	}
+	switch function {
+	case "number", "integer", "percent", "currency":
+		return numericOperandTypes[t]
+	case "datetime", "date", "time":
+		return dateTimeOperandTypes[t]
+	default:
+		return true
+	}
+}
@@ -0,0 +1,109 @@
+package messageformat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestAccount struct {
+	Name string `mf:"name"`
+}
+
+type typedTestParams struct {
+	User    typedTestAccount `mf:"user"`
+	Count   int              `mf:"count"`
+	Label   string           `mf:"label"`
+	When    time.Time        `mf:"when"`
+	Manager *typedTestAccount
+	Extra   map[string]any `mf:"extra"`
+	Dynamic any            `mf:"dynamic"`
+}
+
+func TestCompileTyped(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr error
+	}{
+		{name: "plain variables", source: "Hello {$user.name}, {$label}"},
+		{name: "number annotation", source: "{$count :number}"},
+		{name: "input declaration", source: ".input {$count :integer}\n.match $count\none {{one}}\n* {{other}}"},
+		{name: "datetime annotation", source: "{$when :datetime}"},
+		{name: "string is a numeric operand", source: "{$label :number}"},
+		{name: "pointer member", source: "{$manager.name}"},
+		{name: "map member", source: "{$extra.anything.deep}"},
+		{name: "interface member", source: "{$dynamic :datetime}"},
+		{name: "local declaration is not a param", source: ".local $x = {|1| :number}\n{{{$x} {$count}}}"},
+		{name: "unknown variable", source: "Hello {$naem}", wantErr: ErrMissingParam},
+		{name: "unknown nested variable", source: "Hello {$user.email}", wantErr: ErrMissingParam},
+		{name: "struct is not numeric", source: "{$user :number}", wantErr: ErrIncompatibleParam},
+		{name: "time is not numeric", source: "{$when :integer}", wantErr: ErrIncompatibleParam},
+		{name: "pointer struct is not a date", source: "{$user.name :number} {$count :number} {$manager :date}", wantErr: ErrIncompatibleParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typed, err := CompileTyped[typedTestParams]([]string{"en"}, tt.source)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, typed)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, typed.MessageFormat())
+		})
+	}
+}
+
+func TestCompileTypedReportsAllParams(t *testing.T) {
+	_, err := CompileTyped[typedTestParams]([]string{"en"}, "{$naem} {$cuont}")
+	require.ErrorIs(t, err, ErrMissingParam)
+	assert.Contains(t, err.Error(), "$naem")
+	assert.Contains(t, err.Error(), "$cuont")
+}
+
+func TestCompileTypedParamsTypes(t *testing.T) {
+	_, err := CompileTyped[int]([]string{"en"}, "{$x}")
+	require.ErrorIs(t, err, ErrInvalidParamsType)
+
+	_, err = CompileTyped[map[string]any]([]string{"en"}, "{$x :number}")
+	require.NoError(t, err)
+
+	_, err = CompileTyped[*typedTestParams]([]string{"en"}, "{$label}")
+	require.NoError(t, err)
+
+	_, err = CompileTyped[typedTestParams]([]string{"en"}, "{$x")
+	require.Error(t, err)
+}
+
+func TestTypedMessageFormatFormat(t *testing.T) {
+	typed, err := CompileTyped[*typedTestParams](
+		[]string{"en"},
+		"Hello {$user.name}, {$label} ({$manager.name})",
+		WithBidiIsolation(BidiNone),
+	)
+	require.NoError(t, err)
+
+	result, err := typed.Format(&typedTestParams{
+		User:    typedTestAccount{Name: "Alice"},
+		Label:   "welcome",
+		Manager: &typedTestAccount{Name: "Bob"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Alice, welcome (Bob)", result)
+
+	result, err = typed.Format(&typedTestParams{User: typedTestAccount{Name: "Alice"}, Label: "welcome"})
+	require.Error(t, err)
+	assert.Equal(t, "Hello Alice, welcome ({$manager.name})", result)
+
+	parts, err := typed.FormatToParts(&typedTestParams{
+		User:    typedTestAccount{Name: "Alice"},
+		Manager: &typedTestAccount{Name: "Bob"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, parts)
+	assert.Equal(t, "text", parts[0].Type())
+}